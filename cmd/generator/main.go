@@ -47,6 +47,10 @@ type CacheAnnotation struct {
 	Unless    string
 	Before    bool
 	Sync      bool
+
+	Stale        string
+	StaleIfError string
+	RefreshAfter string
 }
 
 var annotationRegex = regexp.MustCompile(`//\s*@(\w+)\s*\(([^)]+)\)`)
@@ -329,6 +333,12 @@ func parseAnnotation(comment string) *CacheAnnotation {
 			annotation.Before = value == "true"
 		case "sync":
 			annotation.Sync = value == "true"
+		case "stale":
+			annotation.Stale = value
+		case "stale_if_error":
+			annotation.StaleIfError = value
+		case "refresh_after":
+			annotation.RefreshAfter = value
 		}
 	}
 
@@ -432,6 +442,15 @@ func init() {
 			if annotation.Sync {
 				code += fmt.Sprintf("\t\tSync:      true,\n")
 			}
+			if annotation.Stale != "" {
+				code += fmt.Sprintf("\t\tStale:     \"%s\",\n", annotation.Stale)
+			}
+			if annotation.StaleIfError != "" {
+				code += fmt.Sprintf("\t\tStaleIfError: \"%s\",\n", annotation.StaleIfError)
+			}
+			if annotation.RefreshAfter != "" {
+				code += fmt.Sprintf("\t\tRefreshAfter: \"%s\",\n", annotation.RefreshAfter)
+			}
 			code += "\t})\n"
 		}
 	}
@@ -716,8 +735,7 @@ package %s
 
 import (
 	"context"
-	"fmt"
-	"time"
+	"reflect"
 
 	"github.com/coderiser/go-cache/pkg/core"
 	gocache "github.com/coderiser/go-cache/pkg/cache"
@@ -873,173 +891,117 @@ func generateMethodImplementation(method *MethodInfo, decoratedTypeName, service
 // generateCachedMethodWithAnnotation 生成带缓存注解的方法实现
 func generateCachedMethodWithAnnotation(method *MethodInfo, ann *CacheAnnotation, cachedTypeName string) string {
 	switch ann.Type {
-	case "cacheable":
-		return generateCacheableMethod(method, ann, cachedTypeName)
-	case "cacheput":
-		return generateCachePutMethod(method, ann, cachedTypeName)
-	case "cacheevict":
-		return generateCacheEvictMethod(method, ann, cachedTypeName)
+	case "cacheable", "cacheput", "cacheevict":
+		return generateExecuteMethod(method, ann, cachedTypeName)
 	default:
 		return generateSimplePassthroughMethod(method, cachedTypeName)
 	}
 }
 
-// generateCacheableMethod 生成 @cacheable 方法实现
-func generateCacheableMethod(method *MethodInfo, ann *CacheAnnotation, cachedTypeName string) string {
+// generateExecuteMethod 生成委托给 CacheManager.Execute 的方法实现
+// 支持 (T, error)、(T)、(error) 和无返回值的方法，其他签名透传
+func generateExecuteMethod(method *MethodInfo, ann *CacheAnnotation, cachedTypeName string) string {
 	params := buildParamList(method.Params)
 	argNames := buildArgNames(method.Params)
 	resultTypes := buildResultTypes(method.Results)
-	keyExpr := buildKeyExpression(method.Params, method.Results, ann.Key)
-	
-	code := fmt.Sprintf(`
-// %s 带缓存的实现（@cacheable）
-func (c *%s) %s(%s) %s {
-	// 1. 获取缓存
-	cache, err := c.manager.GetCache("%s")
-	if err != nil {
-		return c.decorated.%s(%s)
-	}
-	
-	// 2. 生成缓存 Key
-	key := fmt.Sprintf("%s:%%v", %s)
-	
-	// 3. 查询缓存
-	if val, found, _ := cache.Get(context.Background(), key); found {
-		return val.(%s), nil
-	}
-	
-	// 4. 执行原始方法
-	result, err := c.decorated.%s(%s)
-	if err != nil {
-		return nil, err
+
+	// 第一个 context.Context 参数作为 Execute 的 ctx，回源时所有 context.Context 参数都传入闭包的 ctx
+	ctxExpr := "context.Background()"
+	invokeCtx := invokeContextName(method.Params)
+	var args, names, invokeArgs []string
+	for i, param := range method.Params {
+		name := param.Name
+		if name == "_" || name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		args = append(args, fmt.Sprintf("reflect.ValueOf(%s)", name))
+		names = append(names, fmt.Sprintf("%q", name))
+		if param.Type == "context.Context" {
+			if ctxExpr == "context.Background()" {
+				ctxExpr = name
+			}
+			invokeArgs = append(invokeArgs, invokeCtx)
+			continue
+		}
+		invokeArgs = append(invokeArgs, name)
+	}
+
+	var lhs, invokeBody, returnCode string
+	call := fmt.Sprintf("c.decorated.%s(%s)", method.Name, argNames)
+	invokeCall := fmt.Sprintf("c.decorated.%s(%s)", method.Name, strings.Join(invokeArgs, ", "))
+	switch {
+	case len(method.Results) == 2 && method.HasError && method.ResultType != "":
+		// 后端解码出的值（如 map）不是返回类型时由 ConvertValue 重新解码，失败时返回错误
+		lhs, invokeBody = "result, err := ", "return "+invokeCall
+		returnCode = fmt.Sprintf("\tif err != nil {\n\t\tvar zero %s\n\t\treturn zero, err\n\t}\n\treturn core.ConvertValue[%s](result)\n", method.ResultType, method.ResultType)
+	case len(method.Results) == 1 && method.HasError:
+		lhs, invokeBody = "_, err := ", "return nil, "+invokeCall
+		returnCode = "\treturn err\n"
+	case len(method.Results) == 1:
+		// 无法返回错误，转换失败时直接调用原始方法
+		lhs, invokeBody = "result, _ := ", "return "+invokeCall+", nil"
+		returnCode = fmt.Sprintf("\tvalue, err := core.ConvertValue[%s](result)\n\tif err != nil {\n\t\treturn %s\n\t}\n\treturn value\n", method.ResultType, call)
+	case len(method.Results) == 0:
+		invokeBody = invokeCall + "\n\t\treturn nil, nil"
+	default:
+		return generateSimplePassthroughMethod(method, cachedTypeName)
 	}
-	
-	// 5. 写入缓存
-	ttl, _ := time.ParseDuration("%s")
-	_ = cache.Set(context.Background(), key, result, ttl)
-	
-	return result, nil
-}
-`, method.Name, cachedTypeName, method.Name, params, resultTypes,
-		ann.CacheName, method.Name, argNames,
-		ann.CacheName, keyExpr,
-		method.ResultType,
-		method.Name, argNames,
-		ann.TTL)
-	
-	return code
-}
 
-// generateCachePutMethod 生成 @cacheput 方法实现
-func generateCachePutMethod(method *MethodInfo, ann *CacheAnnotation, cachedTypeName string) string {
-	params := buildParamList(method.Params)
-	argNames := buildArgNames(method.Params)
-	resultTypes := buildResultTypes(method.Results)
-	keyExpr := buildKeyExpression(method.Params, method.Results, ann.Key)
-	
-	code := fmt.Sprintf(`
-// %s 带缓存更新的实现（@cacheput）
+	return fmt.Sprintf(`
+// %s 带缓存的实现（@%s），由 CacheManager.Execute 执行缓存逻辑
 func (c *%s) %s(%s) %s {
-	// 1. 执行原始方法
-	result, err := c.decorated.%s(%s)
-	if err != nil {
-		return nil, err
-	}
-	
-	// 2. 更新缓存
-	cache, err := c.manager.GetCache("%s")
-	if err != nil {
-		return result, nil
-	}
-	
-	// 3. 生成缓存 Key
-	key := fmt.Sprintf("%s:%%v", %s)
-	
-	// 4. 写入缓存
-	ttl, _ := time.ParseDuration("%s")
-	_ = cache.Set(context.Background(), key, result, ttl)
-	
-	return result, nil
-}
-`, method.Name, cachedTypeName, method.Name, params, resultTypes,
-		method.Name, argNames,
-		ann.CacheName,
-		ann.CacheName, keyExpr,
-		ann.TTL)
-	
-	return code
+	meta := %s
+	args := []reflect.Value{%s}
+	%sc.manager.Execute(%s, meta, args, func(%s context.Context) (interface{}, error) {
+		%s
+	})
+%s}
+`, method.Name, ann.Type, cachedTypeName, method.Name, params, resultTypes,
+		buildMethodMeta(method.Name, ann, names),
+		strings.Join(args, ", "),
+		lhs, ctxExpr, invokeCtx, invokeBody, returnCode)
 }
 
-// generateCacheEvictMethod 生成 @cacheevict 方法实现
-func generateCacheEvictMethod(method *MethodInfo, ann *CacheAnnotation, cachedTypeName string) string {
-	params := buildParamList(method.Params)
-	argNames := buildArgNames(method.Params)
-	resultTypes := buildResultTypes(method.Results)
-	keyExpr := buildKeyExpression(method.Params, method.Results, ann.Key)
-	
-	// 检查是否只有 error 返回值
-	hasOnlyErrorReturn := method.HasError && method.ResultType == ""
-	
-	if hasOnlyErrorReturn {
-		code := fmt.Sprintf(`
-// %s 带缓存清除的实现（@cacheevict）
-func (c *%s) %s(%s) %s {
-	// 1. 执行原始方法
-	err := c.decorated.%s(%s)
-	if err != nil {
-		return err
-	}
-	
-	// 2. 清除缓存
-	cache, _ := c.manager.GetCache("%s")
-	if cache == nil {
-		return nil
+// invokeContextName 回源闭包的 ctx 参数名，避免遮蔽名为 ctx 的非 context 参数
+func invokeContextName(params []ParamInfo) string {
+	for _, param := range params {
+		if param.Name == "ctx" && param.Type != "context.Context" {
+			return "invokeCtx"
+		}
 	}
-	
-	// 3. 生成缓存 Key
-	key := fmt.Sprintf("%s:%%v", %s)
-	
-	// 4. 删除缓存
-	_ = cache.Delete(context.Background(), key)
-	
-	return nil
+	return "ctx"
 }
-`, method.Name, cachedTypeName, method.Name, params, resultTypes,
-			method.Name, argNames,
-			ann.CacheName,
-			ann.CacheName, keyExpr)
-		return code
-	}
-	
-	code := fmt.Sprintf(`
-// %s 带缓存清除的实现（@cacheevict）
-func (c *%s) %s(%s) %s {
-	// 1. 执行原始方法
-	result, err := c.decorated.%s(%s)
-	if err != nil {
-		return result, err
-	}
-	
-	// 2. 清除缓存
-	cache, err := c.manager.GetCache("%s")
-	if err != nil {
-		return result, nil
+
+// buildMethodMeta 构建 core.MethodMeta 字面量
+func buildMethodMeta(methodName string, ann *CacheAnnotation, argNames []string) string {
+	var b strings.Builder
+	b.WriteString("&core.MethodMeta{\n")
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "\t\t%s: %q,\n", name, value)
+		}
 	}
-	
-	// 3. 生成缓存 Key
-	key := fmt.Sprintf("%s:%%v", %s)
-	
-	// 4. 删除缓存
-	_ = cache.Delete(context.Background(), key)
-	
-	return result, nil
-}
-`, method.Name, cachedTypeName, method.Name, params, resultTypes,
-		method.Name, argNames,
-		ann.CacheName,
-		ann.CacheName, keyExpr)
-	
-	return code
+	field("CacheType", ann.Type)
+	field("CacheName", ann.CacheName)
+	field("KeyExpr", ann.Key)
+	field("TTLExpr", ann.TTL)
+	field("Condition", ann.Condition)
+	field("Unless", ann.Unless)
+	if ann.Sync {
+		b.WriteString("\t\tSync: true,\n")
+	}
+	if ann.Before {
+		b.WriteString("\t\tBefore: true,\n")
+	}
+	field("RefreshAfterExpr", ann.RefreshAfter)
+	field("StaleExpr", ann.Stale)
+	field("StaleIfErrorExpr", ann.StaleIfError)
+	field("MethodName", methodName)
+	if len(argNames) > 0 {
+		fmt.Fprintf(&b, "\t\tArgNames: []string{%s},\n", strings.Join(argNames, ", "))
+	}
+	b.WriteString("\t}")
+	return b.String()
 }
 
 // generateSimplePassthroughMethod 生成简单透传方法（无缓存注解）
@@ -1100,68 +1062,6 @@ func buildResultTypes(results []ParamInfo) string {
 	return "(" + strings.Join(resultTypes, ", ") + ")"
 }
 
-// buildKeyExpression 根据 SpEL 表达式构建 Key 生成代码
-// 支持：#paramName, #result.fieldName, 字符串拼接
-// 返回 key 的值部分（不包含 cache name 前缀），如：id 或 result.ID
-func buildKeyExpression(params []ParamInfo, results []ParamInfo, keyExpr string) string {
-	// 处理 #result.XXX 表达式（如：#result.Id, #result.UserID）
-	if strings.HasPrefix(keyExpr, "#result.") {
-		fieldName := strings.TrimPrefix(keyExpr, "#result.")
-		// 检查是否有返回值
-		if len(results) > 0 && results[0].Type != "error" {
-			return fmt.Sprintf(`result.%s`, fieldName)
-		}
-		// 无返回值时使用默认值
-		return `"default"`
-	}
-	
-	// 处理简单参数 #paramName
-	if strings.HasPrefix(keyExpr, "#") && !strings.Contains(keyExpr, "+") {
-		paramName := strings.TrimPrefix(keyExpr, "#")
-		return paramName
-	}
-	
-	// 处理复杂表达式：#user.Id + ':' + #status
-	if strings.Contains(keyExpr, "+") {
-		return buildComplexKeyExpression(params, keyExpr)
-	}
-	
-	// 默认：使用第一个参数
-	if len(params) == 0 {
-		return `"default"`
-	}
-	firstParam := params[0].Name
-	if firstParam == "_" || firstParam == "" {
-		return "arg0"
-	}
-	return firstParam
-}
-
-// buildComplexKeyExpression 构建复杂 Key 表达式
-// 返回拼接后的表达式，如：user.Id + ":" + status
-func buildComplexKeyExpression(params []ParamInfo, expr string) string {
-	// 简单实现：分割 + 号，处理每个部分
-	parts := strings.Split(expr, "+")
-	var valueParts []string
-	
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		// 去掉所有的 # 符号（SpEL 参数前缀）
-		part = strings.ReplaceAll(part, "#", "")
-		
-		if strings.HasPrefix(part, "'") && strings.HasSuffix(part, "'") {
-			// 字符串字面量：去掉单引号，加上双引号
-			str := strings.Trim(part, "'")
-			valueParts = append(valueParts, fmt.Sprintf(`"%s"`, str))
-		} else {
-			// 参数引用或字段访问
-			valueParts = append(valueParts, part)
-		}
-	}
-	
-	return strings.Join(valueParts, " + ")
-}
-
 func countAnnotations(annotations map[string]map[string]*CacheAnnotation) int {
 	total := 0
 	for _, methods := range annotations {
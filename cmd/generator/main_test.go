@@ -798,3 +798,42 @@ func TestGenerateMultipleServices(t *testing.T) {
 		}
 	}
 }
+
+// TestGenerateExecuteMethod 测试生成的方法把闭包的 ctx 传给原始方法，并转换缓存值类型
+func TestGenerateExecuteMethod(t *testing.T) {
+	ann := &CacheAnnotation{Type: "cacheable", CacheName: "users", Key: "#id"}
+
+	code := generateExecuteMethod(&MethodInfo{
+		Name:       "GetUser",
+		Params:     []ParamInfo{{Name: "reqCtx", Type: "context.Context"}, {Name: "id", Type: "int64"}},
+		Results:    []ParamInfo{{Type: "*User"}, {Type: "error"}},
+		HasError:   true,
+		ResultType: "*User",
+	}, ann, "CachedUserService")
+	for _, s := range []string{
+		"c.manager.Execute(reqCtx, meta, args, func(ctx context.Context)",
+		"return c.decorated.GetUser(ctx, id)",
+		"return core.ConvertValue[*User](result)",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("Generated method missing %q:\n%s", s, code)
+		}
+	}
+
+	code = generateExecuteMethod(&MethodInfo{
+		Name:       "GetName",
+		Params:     []ParamInfo{{Name: "c2", Type: "context.Context"}, {Name: "ctx", Type: "string"}},
+		Results:    []ParamInfo{{Type: "string"}},
+		ResultType: "string",
+	}, ann, "CachedUserService")
+	for _, s := range []string{
+		"func(invokeCtx context.Context)",
+		"return c.decorated.GetName(invokeCtx, ctx), nil",
+		"value, err := core.ConvertValue[string](result)",
+		"return c.decorated.GetName(c2, ctx)",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("Generated method missing %q:\n%s", s, code)
+		}
+	}
+}
@@ -106,7 +106,7 @@ func createCacheInvalidator(instanceID string) (*backend.CacheInvalidator, error
 	config := backend.DefaultCacheInvalidatorConfig()
 	config.Channel = "cache-invalidation"
 	config.Addr = "localhost:6379"
-	_ = instanceID // 实例 ID（当前版本未使用）
+	config.InstanceID = instanceID // 写入消息 origin，SubscribeMessages 会忽略自己发出的消息
 
 	return backend.NewCacheInvalidator(config)
 }
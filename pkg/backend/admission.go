@@ -0,0 +1,210 @@
+package backend
+
+import (
+	"hash/maphash"
+	"sort"
+	"sync"
+	"time"
+)
+
+// L1AdmissionConfig L1 准入配置（基于访问频率）
+// 只有在一个窗口内访问次数达到 Threshold 的 key 才会被放入 L1，
+// 避免长尾 key 把真正的热点挤出容量很小的 L1。
+type L1AdmissionConfig struct {
+	Threshold       uint32        // 准入阈值 K（窗口内访问次数）
+	Window          time.Duration // 衰减窗口：每个窗口结束时所有计数减半
+	Width           int           // Count-Min Sketch 每行的计数器数量（向上取 2 的幂）
+	Depth           int           // Count-Min Sketch 行数（哈希函数个数）
+	HotKeysCapacity int           // 热点 key 集合的最大容量（用于调试）
+}
+
+// DefaultL1AdmissionConfig 默认 L1 准入配置
+func DefaultL1AdmissionConfig() *L1AdmissionConfig {
+	return &L1AdmissionConfig{
+		Threshold:       3,
+		Window:          time.Minute,
+		Width:           16384,
+		Depth:           4,
+		HotKeysCapacity: 100,
+	}
+}
+
+// HotKey 热点 key 及其估算访问次数
+type HotKey struct {
+	Key   string
+	Count uint32
+}
+
+// frequencySketch 带衰减的 Count-Min Sketch
+// 计数只会高估不会低估；每个窗口结束时所有计数减半，旧的热度逐渐淡出。
+type frequencySketch struct {
+	mu        sync.Mutex
+	seed      maphash.Seed
+	rows      [][]uint32
+	mask      uint64
+	window    time.Duration
+	lastDecay time.Time
+	threshold uint32
+
+	hotCap int
+	hot    map[string]uint32 // 达到阈值的 key（容量受限）
+}
+
+// newFrequencySketch 创建频率统计器
+func newFrequencySketch(config *L1AdmissionConfig) *frequencySketch {
+	defaults := DefaultL1AdmissionConfig()
+	c := *config
+	if c.Threshold == 0 {
+		c.Threshold = defaults.Threshold
+	}
+	if c.Window <= 0 {
+		c.Window = defaults.Window
+	}
+	if c.Width <= 0 {
+		c.Width = defaults.Width
+	}
+	if c.Depth <= 0 {
+		c.Depth = defaults.Depth
+	}
+	if c.HotKeysCapacity <= 0 {
+		c.HotKeysCapacity = defaults.HotKeysCapacity
+	}
+
+	width := 1
+	for width < c.Width {
+		width <<= 1
+	}
+	rows := make([][]uint32, c.Depth)
+	for i := range rows {
+		rows[i] = make([]uint32, width)
+	}
+
+	return &frequencySketch{
+		seed:      maphash.MakeSeed(),
+		rows:      rows,
+		mask:      uint64(width - 1),
+		window:    c.Window,
+		lastDecay: time.Now(),
+		threshold: c.Threshold,
+		hotCap:    c.HotKeysCapacity,
+		hot:       make(map[string]uint32),
+	}
+}
+
+// indexes 双重哈希得到每一行的下标
+func (s *frequencySketch) indexes(key string) (uint64, uint64) {
+	h := maphash.String(s.seed, key)
+	return h, (h >> 32) | 1
+}
+
+// increment 记录一次访问，返回记录后的估算次数
+func (s *frequencySketch) increment(key string) uint32 {
+	h1, h2 := s.indexes(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maybeDecay(time.Now())
+
+	estimate := ^uint32(0)
+	for i, row := range s.rows {
+		idx := (h1 + uint64(i)*h2) & s.mask
+		if row[idx] < ^uint32(0) {
+			row[idx]++
+		}
+		if row[idx] < estimate {
+			estimate = row[idx]
+		}
+	}
+
+	if estimate >= s.threshold {
+		s.trackHot(key, estimate)
+	}
+	return estimate
+}
+
+// estimate 估算访问次数（不记录访问）
+func (s *frequencySketch) estimate(key string) uint32 {
+	h1, h2 := s.indexes(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.estimateLocked(h1, h2)
+}
+
+func (s *frequencySketch) estimateLocked(h1, h2 uint64) uint32 {
+	estimate := ^uint32(0)
+	for i, row := range s.rows {
+		if v := row[(h1+uint64(i)*h2)&s.mask]; v < estimate {
+			estimate = v
+		}
+	}
+	return estimate
+}
+
+// admit 判断 key 是否达到准入阈值
+func (s *frequencySketch) admit(key string) bool {
+	return s.estimate(key) >= s.threshold
+}
+
+// trackHot 记录热点 key；集合已满时替换计数最小且不高于新 key 的条目
+func (s *frequencySketch) trackHot(key string, count uint32) {
+	if _, ok := s.hot[key]; ok || len(s.hot) < s.hotCap {
+		s.hot[key] = count
+		return
+	}
+
+	var minKey string
+	minCount := ^uint32(0)
+	for k, c := range s.hot {
+		if c < minCount {
+			minKey, minCount = k, c
+		}
+	}
+	if count >= minCount {
+		delete(s.hot, minKey)
+		s.hot[key] = count
+	}
+}
+
+// maybeDecay 窗口结束时所有计数减半，并移除已不再热的 key（调用方持有锁）
+func (s *frequencySketch) maybeDecay(now time.Time) {
+	if now.Sub(s.lastDecay) < s.window {
+		return
+	}
+	s.lastDecay = now
+
+	for _, row := range s.rows {
+		for i := range row {
+			row[i] >>= 1
+		}
+	}
+	for key := range s.hot {
+		h1, h2 := s.indexes(key)
+		count := s.estimateLocked(h1, h2)
+		if count < s.threshold {
+			delete(s.hot, key)
+			continue
+		}
+		s.hot[key] = count
+	}
+}
+
+// hotKeys 当前热点 key，按估算次数从高到低排序
+func (s *frequencySketch) hotKeys() []HotKey {
+	s.mu.Lock()
+	s.maybeDecay(time.Now())
+	result := make([]HotKey, 0, len(s.hot))
+	for key, count := range s.hot {
+		result = append(result, HotKey{Key: key, Count: count})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
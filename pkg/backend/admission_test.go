@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFrequencySketch_Admission(t *testing.T) {
+	s := newFrequencySketch(&L1AdmissionConfig{Threshold: 3, Window: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		s.increment("user:1")
+	}
+	if s.admit("user:1") {
+		t.Error("Expected key below threshold not to be admitted")
+	}
+	s.increment("user:1")
+	if !s.admit("user:1") {
+		t.Error("Expected key to be admitted after 3 hits")
+	}
+	if s.admit("user:2") {
+		t.Error("Expected unseen key not to be admitted")
+	}
+}
+
+func TestFrequencySketch_Decay(t *testing.T) {
+	s := newFrequencySketch(&L1AdmissionConfig{Threshold: 2, Window: time.Hour})
+	for i := 0; i < 3; i++ {
+		s.increment("user:1")
+	}
+	if got := len(s.hotKeys()); got != 1 {
+		t.Fatalf("Expected 1 hot key, got %d", got)
+	}
+
+	// 窗口结束：3 → 1，低于阈值
+	s.mu.Lock()
+	s.maybeDecay(s.lastDecay.Add(time.Hour))
+	s.mu.Unlock()
+
+	if s.admit("user:1") {
+		t.Error("Expected key to fall below threshold after decay")
+	}
+	if got := len(s.hotKeys()); got != 0 {
+		t.Errorf("Expected hot key set to be pruned after decay, got %d", got)
+	}
+}
+
+func TestFrequencySketch_HotKeys(t *testing.T) {
+	s := newFrequencySketch(&L1AdmissionConfig{Threshold: 1, Window: time.Hour, HotKeysCapacity: 3})
+
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("key:%d", i)
+		for j := 0; j <= i; j++ {
+			s.increment(key)
+		}
+	}
+
+	hot := s.hotKeys()
+	if len(hot) != 3 {
+		t.Fatalf("Expected hot key set capped at 3, got %d", len(hot))
+	}
+	if hot[0].Key != "key:4" || hot[0].Count != 5 {
+		t.Errorf("Expected hottest key:4 with 5 hits, got %+v", hot[0])
+	}
+	for i := 1; i < len(hot); i++ {
+		if hot[i].Count > hot[i-1].Count {
+			t.Errorf("Expected hot keys sorted by count, got %+v", hot)
+		}
+	}
+}
+
+func TestHybridBackend_AdmitL1(t *testing.T) {
+	h := &HybridBackend{
+		stats:     &HybridStats{},
+		admission: newFrequencySketch(&L1AdmissionConfig{Threshold: 2, Window: time.Hour}),
+	}
+
+	h.admission.increment("user:1")
+	if h.admitL1("user:1") {
+		t.Error("Expected cold key to be rejected")
+	}
+	h.admission.increment("user:1")
+	if !h.admitL1("user:1") {
+		t.Error("Expected hot key to be admitted")
+	}
+	if got := h.stats.AdmissionRejected(); got != 1 {
+		t.Errorf("Expected 1 rejected admission, got %d", got)
+	}
+	if hot := h.HotKeys(); len(hot) != 1 || hot[0].Key != "user:1" {
+		t.Errorf("Unexpected hot keys: %+v", hot)
+	}
+
+	// 未启用准入时总是允许
+	if !(&HybridBackend{}).admitL1("any") {
+		t.Error("Expected admission disabled to admit all keys")
+	}
+}
@@ -0,0 +1,255 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"hash/maphash"
+	"math"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ExistenceFilter 存在性过滤器
+// MightContain 可能误判存在，但不会误判不存在：返回 false 的 key 一定没有被 Add 过。
+type ExistenceFilter interface {
+	Add(ctx context.Context, key string) error
+	MightContain(ctx context.Context, key string) (bool, error)
+}
+
+// BloomFilterConfig 布隆过滤器配置
+type BloomFilterConfig struct {
+	Capacity          uint64  // 预期元素数量（可扩展过滤器为第一层容量）
+	FalsePositiveRate float64 // 目标误判率
+	GrowthFactor      float64 // 可扩展过滤器每层容量的增长倍数
+	TighteningRatio   float64 // 可扩展过滤器每层误判率的收紧比例，保证总误判率收敛
+}
+
+// DefaultBloomFilterConfig 默认布隆过滤器配置
+func DefaultBloomFilterConfig() *BloomFilterConfig {
+	return &BloomFilterConfig{
+		Capacity:          100000,
+		FalsePositiveRate: 0.01,
+		GrowthFactor:      2,
+		TighteningRatio:   0.85,
+	}
+}
+
+// withDefaults 补全未设置的字段
+func (c *BloomFilterConfig) withDefaults() *BloomFilterConfig {
+	defaults := DefaultBloomFilterConfig()
+	if c == nil {
+		return defaults
+	}
+	out := *c
+	if out.Capacity == 0 {
+		out.Capacity = defaults.Capacity
+	}
+	if out.FalsePositiveRate <= 0 || out.FalsePositiveRate >= 1 {
+		out.FalsePositiveRate = defaults.FalsePositiveRate
+	}
+	if out.GrowthFactor < 1 {
+		out.GrowthFactor = defaults.GrowthFactor
+	}
+	if out.TighteningRatio <= 0 || out.TighteningRatio >= 1 {
+		out.TighteningRatio = defaults.TighteningRatio
+	}
+	return &out
+}
+
+// bloomParams 根据容量和误判率计算位数 m 和哈希函数个数 k
+func bloomParams(capacity uint64, fpRate float64) (uint64, int) {
+	n := float64(capacity)
+	m := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return uint64(m), k
+}
+
+// bloomLayer 固定大小的布隆过滤器
+type bloomLayer struct {
+	bits     []uint64
+	m        uint64
+	k        int
+	capacity uint64
+	count    uint64
+}
+
+func newBloomLayer(capacity uint64, fpRate float64) *bloomLayer {
+	m, k := bloomParams(capacity, fpRate)
+	return &bloomLayer{bits: make([]uint64, (m+63)/64), m: m, k: k, capacity: capacity}
+}
+
+func (l *bloomLayer) add(h1, h2 uint64) {
+	for i := 0; i < l.k; i++ {
+		pos := (h1 + uint64(i)*h2) % l.m
+		l.bits[pos/64] |= 1 << (pos % 64)
+	}
+	l.count++
+}
+
+func (l *bloomLayer) contains(h1, h2 uint64) bool {
+	for i := 0; i < l.k; i++ {
+		pos := (h1 + uint64(i)*h2) % l.m
+		if l.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ScalableBloomFilter 可扩展布隆过滤器（单进程）
+// 当前层写满后追加一层更大、误判率更低的过滤器，元素数量超出预期时误判率仍然有界。
+type ScalableBloomFilter struct {
+	mu     sync.RWMutex
+	config *BloomFilterConfig
+	seed   maphash.Seed
+	layers []*bloomLayer
+}
+
+// NewScalableBloomFilter 创建可扩展布隆过滤器
+func NewScalableBloomFilter(config *BloomFilterConfig) *ScalableBloomFilter {
+	config = config.withDefaults()
+	return &ScalableBloomFilter{
+		config: config,
+		seed:   maphash.MakeSeed(),
+		layers: []*bloomLayer{newBloomLayer(config.Capacity, config.FalsePositiveRate*(1-config.TighteningRatio))},
+	}
+}
+
+// hashes 双重哈希
+func (f *ScalableBloomFilter) hashes(key string) (uint64, uint64) {
+	h := maphash.String(f.seed, key)
+	return h, (h >> 32) | 1
+}
+
+// Add 添加 key
+func (f *ScalableBloomFilter) Add(ctx context.Context, key string) error {
+	h1, h2 := f.hashes(key)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, layer := range f.layers {
+		if layer.contains(h1, h2) {
+			return nil
+		}
+	}
+	current := f.layers[len(f.layers)-1]
+	if current.count >= current.capacity {
+		capacity := uint64(float64(current.capacity) * f.config.GrowthFactor)
+		fpRate := f.config.FalsePositiveRate * (1 - f.config.TighteningRatio) *
+			math.Pow(f.config.TighteningRatio, float64(len(f.layers)))
+		current = newBloomLayer(capacity, fpRate)
+		f.layers = append(f.layers, current)
+	}
+	current.add(h1, h2)
+	return nil
+}
+
+// MightContain key 是否可能存在
+func (f *ScalableBloomFilter) MightContain(ctx context.Context, key string) (bool, error) {
+	h1, h2 := f.hashes(key)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, layer := range f.layers {
+		if layer.contains(h1, h2) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Count 已添加的元素数量（重复添加和误判为已存在的 key 不计）
+func (f *ScalableBloomFilter) Count() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var total uint64
+	for _, layer := range f.layers {
+		total += layer.count
+	}
+	return total
+}
+
+// Layers 当前层数
+func (f *ScalableBloomFilter) Layers() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.layers)
+}
+
+// RedisBloomFilter 基于 Redis bitmap 的布隆过滤器（多实例共享）
+// 使用 SETBIT/GETBIT，大小按 Capacity 和 FalsePositiveRate 固定，不会自动扩展；
+// 元素数量明显超过 Capacity 时误判率上升，需要换一个更大的 key 重新构建。
+type RedisBloomFilter struct {
+	client redis.Cmdable
+	key    string
+	m      uint64
+	k      int
+}
+
+// NewRedisBloomFilter 创建 Redis 布隆过滤器，key 为保存 bitmap 的 Redis key
+func NewRedisBloomFilter(client redis.Cmdable, key string, config *BloomFilterConfig) (*RedisBloomFilter, error) {
+	if client == nil || key == "" {
+		return nil, fmt.Errorf("redis bloom filter: client and key are required")
+	}
+	config = config.withDefaults()
+	m, k := bloomParams(config.Capacity, config.FalsePositiveRate)
+	if m > 1<<32 {
+		return nil, fmt.Errorf("redis bloom filter: %d bits exceeds the 2^32 bitmap limit", m)
+	}
+	return &RedisBloomFilter{client: client, key: key, m: m, k: k}, nil
+}
+
+// positions 计算 key 对应的位（FNV 哈希，各实例结果一致）
+func (f *RedisBloomFilter) positions(key string) []int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	h1 := h.Sum64()
+	h2 := (h1 >> 32) | 1
+
+	positions := make([]int64, f.k)
+	for i := range positions {
+		positions[i] = int64((h1 + uint64(i)*h2) % f.m)
+	}
+	return positions
+}
+
+// Add 添加 key
+func (f *RedisBloomFilter) Add(ctx context.Context, key string) error {
+	pipe := f.client.Pipeline()
+	for _, pos := range f.positions(key) {
+		pipe.SetBit(ctx, f.key, pos, 1)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// MightContain key 是否可能存在
+func (f *RedisBloomFilter) MightContain(ctx context.Context, key string) (bool, error) {
+	pipe := f.client.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, f.k)
+	for _, pos := range f.positions(key) {
+		cmds = append(cmds, pipe.GetBit(ctx, f.key, pos))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	for _, cmd := range cmds {
+		if cmd.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Reset 删除 bitmap（重新构建前调用）
+func (f *RedisBloomFilter) Reset(ctx context.Context) error {
+	return f.client.Del(ctx, f.key).Err()
+}
+
+var _ ExistenceFilter = (*ScalableBloomFilter)(nil)
+var _ ExistenceFilter = (*RedisBloomFilter)(nil)
@@ -0,0 +1,73 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestScalableBloomFilter(t *testing.T) {
+	ctx := context.Background()
+	filter := NewScalableBloomFilter(&BloomFilterConfig{Capacity: 1000, FalsePositiveRate: 0.01})
+
+	// 超出第一层容量，触发扩展
+	for i := 0; i < 5000; i++ {
+		filter.Add(ctx, fmt.Sprintf("user:%d", i))
+	}
+	if filter.Layers() < 2 {
+		t.Errorf("Expected filter to grow beyond one layer, got %d", filter.Layers())
+	}
+
+	// 不会误判不存在
+	for i := 0; i < 5000; i++ {
+		if ok, _ := filter.MightContain(ctx, fmt.Sprintf("user:%d", i)); !ok {
+			t.Fatalf("False negative for user:%d", i)
+		}
+	}
+
+	// 误判率接近目标值
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if ok, _ := filter.MightContain(ctx, fmt.Sprintf("bogus:%d", i)); ok {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.03 {
+		t.Errorf("False positive rate too high: %.4f", rate)
+	}
+}
+
+func TestRedisBloomFilter(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available, skipping: %v", err)
+	}
+
+	key := "test:bloom:" + time.Now().Format("150405.000")
+	filter, err := NewRedisBloomFilter(client, key, &BloomFilterConfig{Capacity: 1000, FalsePositiveRate: 0.01})
+	if err != nil {
+		t.Fatalf("NewRedisBloomFilter failed: %v", err)
+	}
+	defer filter.Reset(ctx)
+
+	if err := filter.Add(ctx, "user:1"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if ok, err := filter.MightContain(ctx, "user:1"); err != nil || !ok {
+		t.Errorf("Expected user:1 to be present, got %v (%v)", ok, err)
+	}
+
+	// 另一个实例使用同一个 key，看到相同的数据
+	other, _ := NewRedisBloomFilter(client, key, &BloomFilterConfig{Capacity: 1000, FalsePositiveRate: 0.01})
+	if ok, _ := other.MightContain(ctx, "user:1"); !ok {
+		t.Error("Expected shared bitmap to be visible from another filter instance")
+	}
+	if ok, _ := other.MightContain(ctx, "user:2"); ok {
+		t.Error("Expected user:2 to be absent")
+	}
+}
@@ -0,0 +1,553 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/logger"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int32
+
+const (
+	// CircuitClosed 关闭：请求正常通过，统计错误率和慢调用率
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 打开：请求直接被拒绝，调用方降级到本地缓存或数据源
+	CircuitOpen
+	// CircuitHalfOpen 半开：放行少量探测请求，全部成功则关闭，任一失败则重新打开
+	CircuitHalfOpen
+)
+
+// String 状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrCircuitOpen 熔断器打开，请求被拒绝
+var ErrCircuitOpen = &BackendError{Code: "CIRCUIT_OPEN", Message: "circuit breaker is open"}
+
+// CircuitBreakerConfig 熔断器配置
+type CircuitBreakerConfig struct {
+	Name                  string        // 名称（用于日志和事件）
+	Window                time.Duration // 统计窗口（滑动窗口，按 10 个桶滚动）
+	MinRequests           int64         // 窗口内最少请求数，不足时不触发熔断
+	ErrorRateThreshold    float64       // 错误率阈值（0~1，0 表示不按错误率熔断）
+	SlowCallThreshold     time.Duration // 慢调用阈值（0 表示不统计慢调用）
+	SlowCallRateThreshold float64       // 慢调用率阈值（0~1，0 表示不按慢调用熔断）
+	OpenTimeout           time.Duration // 打开后多久进入半开
+	HalfOpenMaxCalls      int64         // 半开状态下的探测请求数
+	CallTimeout           time.Duration // 单次调用超时（0 表示不限制，由后端自身超时控制）
+
+	// IsFailure 判断错误是否计入失败（为空时除调用方取消外的所有错误都计入）
+	IsFailure func(err error) bool
+	// OnStateChange 状态变更回调
+	OnStateChange func(event CircuitStateChange)
+}
+
+// DefaultCircuitBreakerConfig 默认熔断器配置
+func DefaultCircuitBreakerConfig(name string) *CircuitBreakerConfig {
+	return &CircuitBreakerConfig{
+		Name:                  name,
+		Window:                10 * time.Second,
+		MinRequests:           20,
+		ErrorRateThreshold:    0.5,
+		SlowCallThreshold:     200 * time.Millisecond,
+		SlowCallRateThreshold: 0.8,
+		OpenTimeout:           5 * time.Second,
+		HalfOpenMaxCalls:      3,
+	}
+}
+
+// circuitBreakerFromOptions 从 CacheConfig.Options 读取熔断器配置
+// Options["circuit_breaker"] 为 "true" 时启用（否则返回 nil），未设置的阈值使用默认值：
+// circuit_breaker.window、min_requests、error_rate_threshold、slow_call_threshold、
+// slow_call_rate_threshold、open_timeout、half_open_max_calls、call_timeout（均带 "circuit_breaker." 前缀）。
+func circuitBreakerFromOptions(config *CacheConfig) (*CircuitBreakerConfig, error) {
+	enabled, err := strconv.ParseBool(config.Option("circuit_breaker", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid option circuit_breaker=%q: %w", config.Option("circuit_breaker", ""), err)
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	cb := DefaultCircuitBreakerConfig(config.Name)
+	durations := map[string]*time.Duration{
+		"window":              &cb.Window,
+		"slow_call_threshold": &cb.SlowCallThreshold,
+		"open_timeout":        &cb.OpenTimeout,
+		"call_timeout":        &cb.CallTimeout,
+	}
+	for name, field := range durations {
+		if v := config.Option("circuit_breaker."+name, ""); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid option circuit_breaker.%s=%q: %w", name, v, err)
+			}
+			*field = d
+		}
+	}
+	rates := map[string]*float64{
+		"error_rate_threshold":     &cb.ErrorRateThreshold,
+		"slow_call_rate_threshold": &cb.SlowCallRateThreshold,
+	}
+	for name, field := range rates {
+		if v := config.Option("circuit_breaker."+name, ""); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < 0 || f > 1 {
+				return nil, fmt.Errorf("invalid option circuit_breaker.%s=%q: must be a rate between 0 and 1", name, v)
+			}
+			*field = f
+		}
+	}
+	counts := map[string]*int64{
+		"min_requests":        &cb.MinRequests,
+		"half_open_max_calls": &cb.HalfOpenMaxCalls,
+	}
+	for name, field := range counts {
+		if v := config.Option("circuit_breaker."+name, ""); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid option circuit_breaker.%s=%q: %w", name, v, err)
+			}
+			*field = n
+		}
+	}
+	return cb, nil
+}
+
+// CircuitStateChange 状态变更事件
+type CircuitStateChange struct {
+	Name   string
+	From   CircuitState
+	To     CircuitState
+	Reason string
+	Time   time.Time
+}
+
+// CircuitBreakerStats 熔断器统计
+type CircuitBreakerStats struct {
+	State        CircuitState
+	Successes    int64 // 成功调用数
+	Failures     int64 // 失败调用数
+	SlowCalls    int64 // 慢调用数
+	Rejected     int64 // 被拒绝的调用数
+	StateChanges int64 // 状态变更次数
+}
+
+// circuitBucket 滑动窗口中的一个桶
+type circuitBucket struct {
+	epoch    int64 // 桶对应的时间片序号
+	total    int64
+	failures int64
+	slow     int64
+}
+
+// circuitBucketCount 滑动窗口的桶数
+const circuitBucketCount = 10
+
+// CircuitBreaker 熔断器（基于滑动窗口的错误率和慢调用率）
+type CircuitBreaker struct {
+	config    *CircuitBreakerConfig
+	bucketDur time.Duration
+
+	mu            sync.Mutex
+	state         CircuitState
+	buckets       [circuitBucketCount]circuitBucket
+	openedAt      time.Time
+	halfOpenCalls int64 // 半开状态下已放行的探测数
+	halfOpenOK    int64 // 半开状态下已成功的探测数
+	listeners     []func(event CircuitStateChange)
+	events        []CircuitStateChange // 待通知的状态变更
+	notifyMu      sync.Mutex
+
+	successes, failures, slowCalls, rejected, stateChanges int64
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(config *CircuitBreakerConfig) *CircuitBreaker {
+	defaults := DefaultCircuitBreakerConfig("")
+	c := *config
+	if c.Window <= 0 {
+		c.Window = defaults.Window
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = defaults.MinRequests
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = defaults.OpenTimeout
+	}
+	if c.HalfOpenMaxCalls <= 0 {
+		c.HalfOpenMaxCalls = defaults.HalfOpenMaxCalls
+	}
+
+	cb := &CircuitBreaker{
+		config:    &c,
+		bucketDur: c.Window / circuitBucketCount,
+	}
+	if cb.bucketDur <= 0 {
+		cb.bucketDur = time.Millisecond
+	}
+	if c.OnStateChange != nil {
+		cb.listeners = append(cb.listeners, c.OnStateChange)
+	}
+	return cb
+}
+
+// Execute 通过熔断器执行调用；熔断器打开时直接返回 ErrCircuitOpen
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !cb.allow() {
+		atomic.AddInt64(&cb.rejected, 1)
+		return ErrCircuitOpen
+	}
+
+	if cb.config.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cb.config.CallTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := fn(ctx)
+	cb.record(err, time.Since(start))
+	return err
+}
+
+// allow 判断是否放行请求（打开超时后转为半开）
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.unlockAndNotify()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.config.OpenTimeout {
+			return false
+		}
+		cb.transition(CircuitHalfOpen, "open timeout elapsed")
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.halfOpenCalls >= cb.config.HalfOpenMaxCalls {
+			return false
+		}
+		cb.halfOpenCalls++
+		return true
+	default:
+		return true
+	}
+}
+
+// isFailure 判断错误是否计入失败
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if cb.config.IsFailure != nil {
+		return cb.config.IsFailure(err)
+	}
+	// 调用方主动取消不代表后端故障
+	return !errors.Is(err, context.Canceled)
+}
+
+// record 记录一次调用结果并判断是否需要切换状态
+func (cb *CircuitBreaker) record(err error, latency time.Duration) {
+	failed := cb.isFailure(err)
+	slow := cb.config.SlowCallThreshold > 0 && latency >= cb.config.SlowCallThreshold
+
+	if failed {
+		atomic.AddInt64(&cb.failures, 1)
+	} else {
+		atomic.AddInt64(&cb.successes, 1)
+	}
+	if slow {
+		atomic.AddInt64(&cb.slowCalls, 1)
+	}
+
+	cb.mu.Lock()
+	defer cb.unlockAndNotify()
+
+	switch cb.state {
+	case CircuitHalfOpen:
+		if failed || slow {
+			cb.trip("probe failed")
+			return
+		}
+		cb.halfOpenOK++
+		if cb.halfOpenOK >= cb.config.HalfOpenMaxCalls {
+			cb.transition(CircuitClosed, "probes succeeded")
+		}
+	case CircuitClosed:
+		b := cb.currentBucket(time.Now())
+		b.total++
+		if failed {
+			b.failures++
+		}
+		if slow {
+			b.slow++
+		}
+		if reason := cb.shouldTrip(); reason != "" {
+			cb.trip(reason)
+		}
+	}
+}
+
+// currentBucket 返回当前时间片的桶（过期的桶会被重置）
+func (cb *CircuitBreaker) currentBucket(now time.Time) *circuitBucket {
+	epoch := now.UnixNano() / int64(cb.bucketDur)
+	b := &cb.buckets[epoch%circuitBucketCount]
+	if b.epoch != epoch {
+		*b = circuitBucket{epoch: epoch}
+	}
+	return b
+}
+
+// shouldTrip 根据窗口统计判断是否打开，返回原因（空字符串表示不打开）
+func (cb *CircuitBreaker) shouldTrip() string {
+	current := time.Now().UnixNano() / int64(cb.bucketDur)
+	var total, failures, slow int64
+	for _, b := range cb.buckets {
+		if current-b.epoch >= circuitBucketCount {
+			continue
+		}
+		total += b.total
+		failures += b.failures
+		slow += b.slow
+	}
+	if total < cb.config.MinRequests {
+		return ""
+	}
+	if t := cb.config.ErrorRateThreshold; t > 0 && float64(failures)/float64(total) >= t {
+		return "error rate threshold exceeded"
+	}
+	if t := cb.config.SlowCallRateThreshold; t > 0 && float64(slow)/float64(total) >= t {
+		return "slow call rate threshold exceeded"
+	}
+	return ""
+}
+
+// trip 打开熔断器
+func (cb *CircuitBreaker) trip(reason string) {
+	cb.openedAt = time.Now()
+	cb.transition(CircuitOpen, reason)
+}
+
+// transition 切换状态并通知监听者（调用方持有锁）
+func (cb *CircuitBreaker) transition(to CircuitState, reason string) {
+	from := cb.state
+	if from == to {
+		return
+	}
+	cb.state = to
+	cb.halfOpenCalls = 0
+	cb.halfOpenOK = 0
+	if to == CircuitClosed {
+		cb.buckets = [circuitBucketCount]circuitBucket{}
+	}
+	atomic.AddInt64(&cb.stateChanges, 1)
+
+	if to == CircuitOpen {
+		logger.Warn("Circuit breaker %s: %s -> %s (%s)", cb.config.Name, from, to, reason)
+	} else {
+		logger.Info("Circuit breaker %s: %s -> %s (%s)", cb.config.Name, from, to, reason)
+	}
+
+	cb.events = append(cb.events, CircuitStateChange{Name: cb.config.Name, From: from, To: to, Reason: reason, Time: time.Now()})
+}
+
+// unlockAndNotify 释放锁后按顺序通知本次产生的状态变更
+// 回调中不能调用 Reset，否则会死锁。
+func (cb *CircuitBreaker) unlockAndNotify() {
+	events := cb.events
+	cb.events = nil
+	if len(events) == 0 {
+		cb.mu.Unlock()
+		return
+	}
+	listeners := make([]func(CircuitStateChange), len(cb.listeners))
+	copy(listeners, cb.listeners)
+
+	// 先取得通知锁再释放状态锁，保证事件按发生顺序送达
+	cb.notifyMu.Lock()
+	defer cb.notifyMu.Unlock()
+	cb.mu.Unlock()
+
+	for _, event := range events {
+		for _, fn := range listeners {
+			fn(event)
+		}
+	}
+}
+
+// OnStateChange 注册状态变更回调
+func (cb *CircuitBreaker) OnStateChange(fn func(event CircuitStateChange)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.listeners = append(cb.listeners, fn)
+}
+
+// State 当前状态（打开超时后读取仍为 open，直到下一次请求触发半开）
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Name 熔断器名称
+func (cb *CircuitBreaker) Name() string {
+	return cb.config.Name
+}
+
+// Reset 强制关闭熔断器并清空窗口统计
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.unlockAndNotify()
+	cb.transition(CircuitClosed, "reset")
+	cb.buckets = [circuitBucketCount]circuitBucket{}
+}
+
+// Stats 统计快照
+func (cb *CircuitBreaker) Stats() *CircuitBreakerStats {
+	return &CircuitBreakerStats{
+		State:        cb.State(),
+		Successes:    atomic.LoadInt64(&cb.successes),
+		Failures:     atomic.LoadInt64(&cb.failures),
+		SlowCalls:    atomic.LoadInt64(&cb.slowCalls),
+		Rejected:     atomic.LoadInt64(&cb.rejected),
+		StateChanges: atomic.LoadInt64(&cb.stateChanges),
+	}
+}
+
+// CircuitBreakerBackend 带熔断器的缓存后端包装器
+// 熔断器打开时：Get 返回未命中（调用方回源或使用上层缓存），Set 被跳过，
+// Delete 返回 ErrCircuitOpen（远端可能仍保留旧值，调用方需要感知）。
+type CircuitBreakerBackend struct {
+	backend CacheBackend
+	breaker *CircuitBreaker
+}
+
+// NewCircuitBreakerBackend 用熔断器包装任意后端
+func NewCircuitBreakerBackend(backend CacheBackend, config *CircuitBreakerConfig) *CircuitBreakerBackend {
+	return &CircuitBreakerBackend{
+		backend: backend,
+		breaker: NewCircuitBreaker(config),
+	}
+}
+
+// Get 获取缓存值（熔断时返回未命中）
+func (c *CircuitBreakerBackend) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	var val interface{}
+	var found bool
+	err := c.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		val, found, err = c.backend.Get(ctx, key)
+		return err
+	})
+	if errors.Is(err, ErrCircuitOpen) {
+		return nil, false, nil
+	}
+	return val, found, err
+}
+
+// GetWithTTL 获取缓存值及剩余 TTL（被包装后端不支持时剩余 TTL 为 0）
+func (c *CircuitBreakerBackend) GetWithTTL(ctx context.Context, key string) (interface{}, bool, time.Duration, error) {
+	var val interface{}
+	var found bool
+	var remaining time.Duration
+	err := c.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		if tg, ok := c.backend.(TTLGetter); ok {
+			val, found, remaining, err = tg.GetWithTTL(ctx, key)
+		} else {
+			val, found, err = c.backend.Get(ctx, key)
+		}
+		return err
+	})
+	if errors.Is(err, ErrCircuitOpen) {
+		return nil, false, 0, nil
+	}
+	return val, found, remaining, err
+}
+
+// Set 设置缓存值（熔断时跳过）
+func (c *CircuitBreakerBackend) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	err := c.breaker.Execute(ctx, func(ctx context.Context) error {
+		return c.backend.Set(ctx, key, value, ttl)
+	})
+	if errors.Is(err, ErrCircuitOpen) {
+		return nil
+	}
+	return err
+}
+
+// Delete 删除缓存值（熔断时返回 ErrCircuitOpen）
+func (c *CircuitBreakerBackend) Delete(ctx context.Context, key string) error {
+	return c.breaker.Execute(ctx, func(ctx context.Context) error {
+		return c.backend.Delete(ctx, key)
+	})
+}
+
+// DeletePrefix 按前缀删除（熔断时返回 ErrCircuitOpen）
+func (c *CircuitBreakerBackend) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	pd, ok := c.backend.(PrefixDeleter)
+	if !ok {
+		return 0, fmt.Errorf("wrapped backend does not support prefix deletion")
+	}
+	deleted := 0
+	err := c.breaker.Execute(ctx, func(ctx context.Context) error {
+		n, err := pd.DeletePrefix(ctx, prefix)
+		deleted = n
+		return err
+	})
+	return deleted, err
+}
+
+// Ping 探测被包装的后端（不计入熔断统计）；熔断器打开时返回 ErrDegraded，被包装后端不支持探测时视为正常
+func (c *CircuitBreakerBackend) Ping(ctx context.Context) error {
+	if c.breaker.State() == CircuitOpen {
+		return fmt.Errorf("%w: circuit breaker is open", ErrDegraded)
+	}
+	if checker, ok := c.backend.(HealthChecker); ok {
+		return checker.Ping(ctx)
+	}
+	return nil
+}
+
+// Close 关闭被包装的后端
+func (c *CircuitBreakerBackend) Close() error {
+	return c.backend.Close()
+}
+
+// Stats 被包装后端的统计信息
+func (c *CircuitBreakerBackend) Stats() *CacheStats {
+	return c.backend.Stats()
+}
+
+// Breaker 获取熔断器
+func (c *CircuitBreakerBackend) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// Unwrap 获取被包装的后端
+func (c *CircuitBreakerBackend) Unwrap() CacheBackend {
+	return c.backend
+}
+
+// 确保实现 CacheBackend 和 TTLGetter 接口
+var _ CacheBackend = (*CircuitBreakerBackend)(nil)
+var _ TTLGetter = (*CircuitBreakerBackend)(nil)
+var _ HealthChecker = (*CircuitBreakerBackend)(nil)
+var _ PrefixDeleter = (*CircuitBreakerBackend)(nil)
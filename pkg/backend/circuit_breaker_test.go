@@ -0,0 +1,318 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyBackend 可注入错误和延迟的测试后端
+type flakyBackend struct {
+	*MemoryBackend
+	mu    sync.Mutex
+	err   error
+	delay time.Duration
+}
+
+func newFlakyBackend(t *testing.T) *flakyBackend {
+	t.Helper()
+	mem, err := NewMemoryBackend(DefaultCacheConfig("flaky"))
+	if err != nil {
+		t.Fatalf("Failed to create memory backend: %v", err)
+	}
+	return &flakyBackend{MemoryBackend: mem}
+}
+
+func (f *flakyBackend) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *flakyBackend) call() error {
+	f.mu.Lock()
+	err, delay := f.err, f.delay
+	f.mu.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return err
+}
+
+func (f *flakyBackend) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	if err := f.call(); err != nil {
+		return nil, false, err
+	}
+	return f.MemoryBackend.Get(ctx, key)
+}
+
+func (f *flakyBackend) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := f.call(); err != nil {
+		return err
+	}
+	return f.MemoryBackend.Set(ctx, key, value, ttl)
+}
+
+func (f *flakyBackend) Delete(ctx context.Context, key string) error {
+	if err := f.call(); err != nil {
+		return err
+	}
+	return f.MemoryBackend.Delete(ctx, key)
+}
+
+func testBreakerConfig() *CircuitBreakerConfig {
+	config := DefaultCircuitBreakerConfig("test")
+	config.MinRequests = 4
+	config.ErrorRateThreshold = 0.5
+	config.SlowCallThreshold = 0
+	config.OpenTimeout = 30 * time.Millisecond
+	config.HalfOpenMaxCalls = 2
+	return config
+}
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	var mu sync.Mutex
+	var events []CircuitStateChange
+	config := testBreakerConfig()
+	config.OnStateChange = func(event CircuitStateChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	cb := NewCircuitBreaker(config)
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	ok := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return boom }
+
+	// 窗口内请求数不足时不熔断
+	for i := 0; i < 3; i++ {
+		cb.Execute(ctx, fail)
+	}
+	if cb.State() != CircuitClosed {
+		t.Fatalf("Expected closed below MinRequests, got %s", cb.State())
+	}
+	cb.Execute(ctx, fail)
+	if cb.State() != CircuitOpen {
+		t.Fatalf("Expected open after error rate exceeded, got %s", cb.State())
+	}
+
+	// 打开期间直接拒绝
+	called := false
+	if err := cb.Execute(ctx, func(ctx context.Context) error { called = true; return nil }); err != ErrCircuitOpen || called {
+		t.Errorf("Expected ErrCircuitOpen without calling fn, got %v (called=%v)", err, called)
+	}
+
+	// 超时后半开，探测失败重新打开
+	time.Sleep(40 * time.Millisecond)
+	cb.Execute(ctx, fail)
+	if cb.State() != CircuitOpen {
+		t.Fatalf("Expected reopen after failed probe, got %s", cb.State())
+	}
+
+	// 探测全部成功后关闭
+	time.Sleep(40 * time.Millisecond)
+	cb.Execute(ctx, ok)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("Expected half-open during probes, got %s", cb.State())
+	}
+	cb.Execute(ctx, ok)
+	if cb.State() != CircuitClosed {
+		t.Fatalf("Expected closed after probes succeeded, got %s", cb.State())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, state := range want {
+		if events[i].To != state {
+			t.Errorf("Event %d: expected %s, got %s", i, state, events[i].To)
+		}
+	}
+
+	stats := cb.Stats()
+	if stats.Rejected != 1 || stats.StateChanges != 5 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCircuitBreaker_SlowCalls(t *testing.T) {
+	config := testBreakerConfig()
+	config.ErrorRateThreshold = 0
+	config.SlowCallThreshold = 5 * time.Millisecond
+	config.SlowCallRateThreshold = 0.5
+	cb := NewCircuitBreaker(config)
+
+	slow := func(ctx context.Context) error { time.Sleep(10 * time.Millisecond); return nil }
+	for i := 0; i < 4; i++ {
+		cb.Execute(context.Background(), slow)
+	}
+	if cb.State() != CircuitOpen {
+		t.Errorf("Expected open after slow call rate exceeded, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_CallerCancelNotCounted(t *testing.T) {
+	cb := NewCircuitBreaker(testBreakerConfig())
+	for i := 0; i < 10; i++ {
+		cb.Execute(context.Background(), func(ctx context.Context) error { return context.Canceled })
+	}
+	if cb.State() != CircuitClosed {
+		t.Errorf("Expected caller cancellation not to trip the breaker, got %s", cb.State())
+	}
+
+	cb.Reset()
+	if cb.State() != CircuitClosed {
+		t.Errorf("Expected closed after reset, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerBackend_Degraded(t *testing.T) {
+	flaky := newFlakyBackend(t)
+	b := NewCircuitBreakerBackend(flaky, testBreakerConfig())
+	defer b.Close()
+
+	ctx := context.Background()
+	b.Set(ctx, "key1", "value1", time.Minute)
+
+	flaky.fail(errors.New("connection refused"))
+	for i := 0; i < 4; i++ {
+		b.Get(ctx, "key1")
+	}
+	if b.Breaker().State() != CircuitOpen {
+		t.Fatalf("Expected open, got %s", b.Breaker().State())
+	}
+
+	// 打开期间：Get 未命中且无错误，Set 被跳过，Delete 返回 ErrCircuitOpen
+	if val, found, err := b.Get(ctx, "key1"); found || err != nil || val != nil {
+		t.Errorf("Expected miss without error, got %v, %v, %v", val, found, err)
+	}
+	if err := b.Set(ctx, "key2", "value2", time.Minute); err != nil {
+		t.Errorf("Expected Set to be skipped without error, got %v", err)
+	}
+	if err := b.Delete(ctx, "key1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen from Delete, got %v", err)
+	}
+	if _, found, _ := flaky.MemoryBackend.Get(ctx, "key2"); found {
+		t.Error("Expected Set not to reach the wrapped backend")
+	}
+
+	// 恢复后探测成功，重新关闭
+	flaky.fail(nil)
+	time.Sleep(40 * time.Millisecond)
+	b.Get(ctx, "key1")
+	b.Get(ctx, "key1")
+	if b.Breaker().State() != CircuitClosed {
+		t.Errorf("Expected closed after recovery, got %s", b.Breaker().State())
+	}
+}
+
+func TestCircuitBreakerBackend_Forwarding(t *testing.T) {
+	ctx := context.Background()
+	mem := newFlakyBackend(t).MemoryBackend
+	checked := &pingBackend{MemoryBackend: mem}
+	b := NewCircuitBreakerBackend(checked, testBreakerConfig())
+	defer b.Close()
+
+	// DeletePrefix 转发给被包装的后端
+	b.Set(ctx, "acme:1", "v", time.Minute)
+	b.Set(ctx, "globex:1", "v", time.Minute)
+	if n, err := b.DeletePrefix(ctx, "acme:"); err != nil || n != 1 {
+		t.Errorf("Expected 1 deleted, got %d (%v)", n, err)
+	}
+	if _, found, _ := b.Get(ctx, "globex:1"); !found {
+		t.Error("Expected other prefix to be kept")
+	}
+
+	// Ping 转发给被包装的后端
+	checked.err = errors.New("connection refused")
+	if err := b.Ping(ctx); err == nil || errors.Is(err, ErrDegraded) {
+		t.Errorf("Expected wrapped ping error, got %v", err)
+	}
+	checked.err = nil
+	if err := b.Ping(ctx); err != nil {
+		t.Errorf("Expected healthy ping, got %v", err)
+	}
+
+	// 熔断器打开：Ping 为降级，DeletePrefix 返回 ErrCircuitOpen
+	for i := 0; i < 4; i++ {
+		b.Breaker().Execute(ctx, func(ctx context.Context) error { return errors.New("timeout") })
+	}
+	if err := b.Ping(ctx); !errors.Is(err, ErrDegraded) {
+		t.Errorf("Expected ErrDegraded while open, got %v", err)
+	}
+	if _, err := b.DeletePrefix(ctx, "globex:"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen from DeletePrefix, got %v", err)
+	}
+
+	// 被包装后端不支持时：DeletePrefix 返回错误，Ping 视为正常
+	plain := NewCircuitBreakerBackend(struct{ CacheBackend }{mem}, testBreakerConfig())
+	if _, err := plain.DeletePrefix(ctx, "acme:"); err == nil {
+		t.Error("Expected error for backend without prefix deletion")
+	}
+	if err := plain.Ping(ctx); err != nil {
+		t.Errorf("Expected nil ping for backend without health check, got %v", err)
+	}
+}
+
+func TestCircuitBreakerFromOptions(t *testing.T) {
+	config := DefaultCacheConfig("users")
+	if cb, err := circuitBreakerFromOptions(config); cb != nil || err != nil {
+		t.Errorf("Expected no breaker without option, got %+v (%v)", cb, err)
+	}
+
+	config.Options = map[string]string{
+		"circuit_breaker":                      "true",
+		"circuit_breaker.error_rate_threshold": "0.25",
+		"circuit_breaker.min_requests":         "10",
+		"circuit_breaker.open_timeout":         "2s",
+	}
+	cb, err := circuitBreakerFromOptions(config)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	defaults := DefaultCircuitBreakerConfig("users")
+	if cb.Name != "users" || cb.ErrorRateThreshold != 0.25 || cb.MinRequests != 10 || cb.OpenTimeout != 2*time.Second {
+		t.Errorf("Unexpected breaker config: %+v", cb)
+	}
+	if cb.Window != defaults.Window || cb.HalfOpenMaxCalls != defaults.HalfOpenMaxCalls {
+		t.Errorf("Expected unset fields to use defaults, got %+v", cb)
+	}
+
+	for k, v := range map[string]string{
+		"circuit_breaker":                      "yes please",
+		"circuit_breaker.window":               "ten",
+		"circuit_breaker.error_rate_threshold": "1.5",
+		"circuit_breaker.min_requests":         "many",
+	} {
+		config.Options = map[string]string{"circuit_breaker": "true", k: v}
+		if _, err := circuitBreakerFromOptions(config); err == nil {
+			t.Errorf("Expected error for %s=%s", k, v)
+		}
+	}
+}
+
+func TestHybridBackend_L2CircuitOpen(t *testing.T) {
+	cb := NewCircuitBreaker(testBreakerConfig())
+	h := &HybridBackend{stats: &HybridStats{}, l2Breaker: cb}
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		h.callL2(ctx, func(ctx context.Context) error { return errors.New("timeout") })
+	}
+
+	called := false
+	err := h.callL2(ctx, func(ctx context.Context) error { called = true; return nil })
+	if !errors.Is(err, ErrCircuitOpen) || called {
+		t.Errorf("Expected L2 call to be skipped, got %v (called=%v)", err, called)
+	}
+	if got := h.stats.L2Rejected(); got != 1 {
+		t.Errorf("Expected 1 rejected L2 call, got %d", got)
+	}
+}
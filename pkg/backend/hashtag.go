@@ -0,0 +1,164 @@
+package backend
+
+import (
+	"strings"
+
+	"github.com/coderiser/go-cache/pkg/spel"
+)
+
+// HashTagStrategy Redis Cluster hash tag 策略
+// Redis Cluster 只对 key 中第一个 {...} 的内容计算 slot，
+// 让相关 key 共享同一个 hash tag 即可在 MGET、Pipeline、Lua 脚本中避免 CROSSSLOT 错误。
+type HashTagStrategy string
+
+const (
+	// HashTagNone 不添加 hash tag（默认，key 均匀分布到所有 slot）
+	HashTagNone HashTagStrategy = ""
+	// HashTagCacheName 以缓存前缀作为 tag：{prefix}:key，整个缓存落在同一个 slot
+	HashTagCacheName HashTagStrategy = "cache"
+	// HashTagEntityID 以 key 的前 N 段作为 tag：prefix:{user:42}:orders
+	HashTagEntityID HashTagStrategy = "entity"
+	// HashTagExpr 由 SpEL 表达式从 key 中选取 tag（可用变量：key, parts, prefix）
+	HashTagExpr HashTagStrategy = "expr"
+)
+
+// DefaultEntityDepth entity 策略默认取 key 的前 2 段（如 "user:42"）
+const DefaultEntityDepth = 2
+
+// HashTagKeyBuilder 支持 hash tag 的键构建器
+type HashTagKeyBuilder struct {
+	Separator   string
+	Prefix      string
+	Strategy    HashTagStrategy
+	Expr        string // HashTagExpr 策略使用的表达式，如 "parts[1]"
+	EntityDepth int    // HashTagEntityID 策略使用的段数
+
+	evaluator *spel.SpELEvaluator
+}
+
+// NewHashTagKeyBuilder 创建支持 hash tag 的键构建器
+func NewHashTagKeyBuilder(separator, prefix string, strategy HashTagStrategy, expr string) *HashTagKeyBuilder {
+	if separator == "" {
+		separator = ":"
+	}
+	kb := &HashTagKeyBuilder{
+		Separator:   separator,
+		Prefix:      prefix,
+		Strategy:    strategy,
+		Expr:        expr,
+		EntityDepth: DefaultEntityDepth,
+	}
+	if strategy == HashTagExpr {
+		kb.evaluator = spel.NewSpELEvaluator()
+	}
+	return kb
+}
+
+// Build 拼接各段并应用 hash tag
+func (kb *HashTagKeyBuilder) Build(parts ...string) string {
+	return kb.BuildKey(strings.Join(parts, kb.Separator))
+}
+
+// BuildKey 为业务 key 添加前缀和 hash tag
+func (kb *HashTagKeyBuilder) BuildKey(key string) string {
+	if kb.Strategy == HashTagCacheName {
+		if kb.Prefix == "" {
+			return key
+		}
+		return "{" + kb.Prefix + "}" + kb.Separator + key
+	}
+
+	tagged := key
+	// 已包含 hash tag 的 key 保持不变，尊重调用方的显式选择
+	if _, ok := extractHashTag(key); !ok {
+		if tag := kb.Tag(key); tag != "" {
+			if i := strings.Index(key, tag); i >= 0 {
+				tagged = key[:i] + "{" + tag + "}" + key[i+len(tag):]
+			} else {
+				tagged = "{" + tag + "}" + kb.Separator + key
+			}
+		}
+	}
+
+	if kb.Prefix != "" {
+		return kb.Prefix + kb.Separator + tagged
+	}
+	return tagged
+}
+
+// Tag 返回业务 key 对应的 hash tag 内容（不含花括号），无 tag 时返回空串
+func (kb *HashTagKeyBuilder) Tag(key string) string {
+	switch kb.Strategy {
+	case HashTagCacheName:
+		return kb.Prefix
+	case HashTagEntityID:
+		depth := kb.EntityDepth
+		if depth <= 0 {
+			depth = DefaultEntityDepth
+		}
+		parts := strings.SplitN(key, kb.Separator, depth+1)
+		if len(parts) > depth {
+			parts = parts[:depth]
+		}
+		return strings.Join(parts, kb.Separator)
+	case HashTagExpr:
+		if kb.Expr == "" || kb.evaluator == nil {
+			return ""
+		}
+		ctx := spel.NewEvaluationContext()
+		ctx.Key = key
+		ctx.SetArg("key", key)
+		ctx.SetArg("parts", strings.Split(key, kb.Separator))
+		ctx.SetArg("prefix", kb.Prefix)
+		tag, err := kb.evaluator.EvaluateToString(kb.Expr, ctx)
+		if err != nil {
+			return ""
+		}
+		return tag
+	default:
+		return ""
+	}
+}
+
+// extractHashTag 按 Redis 规则提取 key 中的 hash tag：第一个 '{' 与其后第一个 '}' 之间的非空内容
+func extractHashTag(key string) (string, bool) {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return "", false
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return "", false
+	}
+	return key[start+1 : start+1+end], true
+}
+
+// HashSlotCount Redis Cluster 槽位总数
+const HashSlotCount = 16384
+
+// HashSlot 计算完整 key 在 Redis Cluster 中的槽位（CRC16/XMODEM mod 16384）
+func HashSlot(key string) int {
+	if tag, ok := extractHashTag(key); ok {
+		key = tag
+	}
+	return int(crc16(key) % HashSlotCount)
+}
+
+// crc16 CRC16/XMODEM（Redis Cluster 使用的算法）
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// 确保实现 KeyBuilder 接口
+var _ KeyBuilder = (*HashTagKeyBuilder)(nil)
@@ -0,0 +1,110 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestHashSlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		slot int
+	}{
+		{"123456789", 12739}, // CRC16/XMODEM 校验值 0x31C3
+		{"foo", 12182},
+		{"bar", 5061},
+	}
+	for _, tt := range tests {
+		if got := HashSlot(tt.key); got != tt.slot {
+			t.Errorf("HashSlot(%q) = %d, want %d", tt.key, got, tt.slot)
+		}
+	}
+
+	// 相同 hash tag 的 key 落在同一槽位
+	if HashSlot("{user1000}.following") != HashSlot("{user1000}.followers") {
+		t.Error("Keys with the same hash tag should share a slot")
+	}
+	// 空 tag 按整个 key 计算
+	if HashSlot("foo{}{bar}") != int(crc16("foo{}{bar}")%HashSlotCount) {
+		t.Error("Empty hash tag should hash the whole key")
+	}
+}
+
+func TestHashTagKeyBuilder_CacheName(t *testing.T) {
+	kb := NewHashTagKeyBuilder(":", "users", HashTagCacheName, "")
+
+	if got := kb.BuildKey("42"); got != "{users}:42" {
+		t.Errorf("Expected {users}:42, got %s", got)
+	}
+	if got := kb.Build("42", "profile"); got != "{users}:42:profile" {
+		t.Errorf("Expected {users}:42:profile, got %s", got)
+	}
+	if HashSlot(kb.BuildKey("1")) != HashSlot(kb.BuildKey("999")) {
+		t.Error("All keys of a cache should share a slot")
+	}
+
+	// 无前缀时无法构造 tag，保持原样
+	kb = NewHashTagKeyBuilder(":", "", HashTagCacheName, "")
+	if got := kb.BuildKey("42"); got != "42" {
+		t.Errorf("Expected 42, got %s", got)
+	}
+}
+
+func TestHashTagKeyBuilder_EntityID(t *testing.T) {
+	kb := NewHashTagKeyBuilder(":", "app", HashTagEntityID, "")
+
+	if got := kb.BuildKey("user:42"); got != "app:{user:42}" {
+		t.Errorf("Expected app:{user:42}, got %s", got)
+	}
+	if got := kb.BuildKey("user:42:orders"); got != "app:{user:42}:orders" {
+		t.Errorf("Expected app:{user:42}:orders, got %s", got)
+	}
+	if HashSlot(kb.BuildKey("user:42")) != HashSlot(kb.BuildKey("user:42:orders")) {
+		t.Error("Keys of the same entity should share a slot")
+	}
+
+	kb.EntityDepth = 1
+	if got := kb.BuildKey("42:orders"); got != "app:{42}:orders" {
+		t.Errorf("Expected app:{42}:orders, got %s", got)
+	}
+}
+
+func TestHashTagKeyBuilder_Expr(t *testing.T) {
+	kb := NewHashTagKeyBuilder(":", "", HashTagExpr, "parts[1]")
+
+	if got := kb.BuildKey("order:42:items"); got != "order:{42}:items" {
+		t.Errorf("Expected order:{42}:items, got %s", got)
+	}
+	if HashSlot(kb.BuildKey("order:42:items")) != HashSlot(kb.BuildKey("invoice:42")) {
+		t.Error("Keys selecting the same tag should share a slot")
+	}
+
+	// 表达式求值失败时不添加 tag
+	kb = NewHashTagKeyBuilder(":", "", HashTagExpr, "parts[")
+	if got := kb.BuildKey("order:42"); got != "order:42" {
+		t.Errorf("Expected order:42, got %s", got)
+	}
+}
+
+func TestHashTagKeyBuilder_ExplicitTag(t *testing.T) {
+	kb := NewHashTagKeyBuilder(":", "app", HashTagEntityID, "")
+	if got := kb.BuildKey("{tenant1}:user:42"); got != "app:{tenant1}:user:42" {
+		t.Errorf("Expected explicit tag to be kept, got %s", got)
+	}
+}
+
+func TestRedisClusterConfig_RouteByPrefix(t *testing.T) {
+	config := DefaultRedisClusterConfig()
+	if config.hashTagStrategy() != HashTagNone {
+		t.Errorf("Expected no hash tag by default, got %q", config.hashTagStrategy())
+	}
+
+	config.RouteByPrefix = true
+	if config.hashTagStrategy() != HashTagCacheName {
+		t.Errorf("Expected RouteByPrefix to imply cache strategy, got %q", config.hashTagStrategy())
+	}
+
+	config.HashTagStrategy = HashTagEntityID
+	if config.hashTagStrategy() != HashTagEntityID {
+		t.Errorf("Expected explicit strategy to win, got %q", config.hashTagStrategy())
+	}
+}
@@ -2,6 +2,8 @@ package backend
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -18,6 +20,11 @@ type HybridBackend struct {
 	ttlMgr      *TTLManager
 	keyBuilder  *DefaultKeyBuilder
 	l1WriteBack time.Duration // L1 回写 TTL
+	l1TTLRatio  float64       // L1 TTL 相对 L2 TTL 的比例（0 表示不启用）
+	invalidator *l1Invalidator // 跨实例 L1 失效（未配置频道时为 nil）
+	publishOnFlush bool       // L2 异步写回时在写入 Redis 后才广播失效
+	l2Breaker   *CircuitBreaker // L2 熔断器（未配置时为 nil）
+	admission   *frequencySketch // L1 准入统计（未配置时所有 key 都进入 L1）
 	closed      bool
 }
 
@@ -28,13 +35,28 @@ type HybridStats struct {
 	l2Fallbacks                int64 // L1 miss 后 L2 命中的次数
 	l1Backfills                int64 // 从 L2 回写 L1 的次数
 	sets, deletes, errors      int64
+	invalidationsSent          int64 // 广播的 L1 失效消息数
+	invalidationsReceived      int64 // 收到并生效的 L1 失效消息数
+	l2Rejected                 int64 // L2 熔断期间被跳过的操作数
+	admissionRejected          int64 // 未达到准入阈值、没有进入 L1 的次数
 }
 
 // HybridConfig 混合缓存配置
 type HybridConfig struct {
 	L1Config      *CacheConfig  // L1 配置
 	L2Config      *RedisConfig  // L2 配置
-	L1WriteBackTTL time.Duration // L1 回写 TTL（默认 5 分钟）
+	L1WriteBackTTL time.Duration // L1 回写 TTL 上限（默认 5 分钟），实际不超过 L2 剩余 TTL
+	L1TTLRatio     float64       // 可选：L1 TTL = L2 TTL × 比例（0 < ratio <= 1，0 表示不启用）
+
+	InvalidationBus     InvalidationBus // 跨实例 L1 失效总线（优先于 InvalidationChannel，由调用方负责关闭）
+	InvalidationChannel string          // 跨实例 L1 失效频道：未指定总线时基于 L2 的 Redis 客户端创建 Pub/Sub 总线（为空表示不启用）
+	InstanceID          string          // 本实例 ID（为空时自动生成），用于忽略自己发出的失效消息
+
+	InvalidationRecovery         RecoveryAction // 失效订阅断开重连后对 L1 的恢复动作（默认清空 L1）
+	InvalidationRecoveryPrefixes []string       // InvalidationRecovery 为 RecoveryFlushPrefix 时清理的前缀
+
+	L2CircuitBreaker *CircuitBreakerConfig // 可选：L2 熔断器，打开时只使用 L1（nil 表示不启用）
+	L1Admission      *L1AdmissionConfig    // 可选：L1 准入，窗口内访问 K 次后才进入 L1（nil 表示全部进入）
 }
 
 // DefaultHybridConfig 默认混合缓存配置
@@ -71,7 +93,12 @@ func NewHybridBackend(config *HybridConfig) (*HybridBackend, error) {
 		return nil, err
 	}
 
-	return &HybridBackend{
+	// L2 命名空间代数变更（本实例或其他实例清空）时，L1 中的旧数据随之失效
+	l2.OnGenerationChange(func(gen int64) {
+		_ = l1.Clear(context.Background())
+	})
+
+	h := &HybridBackend{
 		l1:          l1,
 		l2:          l2,
 		config:      config.L1Config,
@@ -79,7 +106,54 @@ func NewHybridBackend(config *HybridConfig) (*HybridBackend, error) {
 		ttlMgr:      NewTTLManager(config.L1Config.DefaultTTL, config.L1Config.MaxTTL),
 		keyBuilder:  NewDefaultKeyBuilder(":", config.L1Config.Name),
 		l1WriteBack: config.L1WriteBackTTL,
-	}, nil
+		l1TTLRatio:  config.L1TTLRatio,
+	}
+
+	if config.L1Admission != nil {
+		h.admission = newFrequencySketch(config.L1Admission)
+	}
+
+	if config.L2CircuitBreaker != nil {
+		h.l2Breaker = NewCircuitBreaker(config.L2CircuitBreaker)
+	}
+
+	if config.InvalidationBus != nil || config.InvalidationChannel != "" {
+		instanceID := config.InstanceID
+		if instanceID == "" {
+			instanceID = NewInstanceID()
+		}
+		bus, ownsBus := config.InvalidationBus, false
+		if bus == nil {
+			ci, err := NewCacheInvalidatorFromClient(l2.Client(), config.InvalidationChannel, instanceID)
+			if err != nil {
+				l2.Close()
+				l1.Close()
+				return nil, err
+			}
+			bus, ownsBus = NewRedisInvalidationBus(ci), true
+		}
+		h.invalidator, err = newL1Invalidator(bus, ownsBus, config.L1Config.Name, instanceID, l1, h.stats,
+			config.InvalidationRecovery, config.InvalidationRecoveryPrefixes)
+		if err != nil {
+			if ownsBus {
+				bus.Close()
+			}
+			l2.Close()
+			l1.Close()
+			return nil, err
+		}
+	}
+
+	// L2 启用异步写回时，等数据真正写入 Redis 后再广播失效，
+	// 避免其他实例在写入前从 L2 读到旧值并回填 L1
+	if h.invalidator != nil {
+		h.publishOnFlush = l2.OnWriteBehindFlush(func(keys []string) {
+			// 一个批次合并为一条消息
+			h.invalidator.publish(context.Background(), keys...)
+		})
+	}
+
+	return h, nil
 }
 
 // Get 获取缓存值（L1 → L2 级联查询）
@@ -91,6 +165,11 @@ func (h *HybridBackend) Get(ctx context.Context, key string) (interface{}, bool,
 	}
 	h.mu.RUnlock()
 
+	// 记录访问频率（用于 L1 准入）
+	if h.admission != nil {
+		h.admission.increment(key)
+	}
+
 	// 1. 先查 L1（本地缓存）
 	if val, found, _ := h.l1.Get(ctx, key); found {
 		h.stats.recordL1Hit()
@@ -98,19 +177,25 @@ func (h *HybridBackend) Get(ctx context.Context, key string) (interface{}, bool,
 	}
 	h.stats.recordL1Miss()
 
-	// 2. L1 未命中，查 L2（Redis 缓存）
-	if val, found, _ := h.l2.Get(ctx, key); found {
+	// 2. L1 未命中，查 L2（Redis 缓存），同时取回剩余 TTL；L2 熔断时直接返回未命中，由调用方回源
+	var val interface{}
+	var found bool
+	var remaining time.Duration
+	_ = h.callL2(ctx, func(ctx context.Context) error {
+		var err error
+		val, found, remaining, err = h.l2.GetWithTTL(ctx, key)
+		return err
+	})
+	if found {
 		h.stats.recordL2Hit()
 		h.stats.recordL2Fallback()
-		
-		// 3. 回写 L1（提升后续访问速度）
-		writeBackTTL := h.l1WriteBack
-		if writeBackTTL <= 0 {
-			writeBackTTL = 5 * time.Minute
+
+		// 3. 回写 L1（提升后续访问速度），L1 不能比 L2 活得更久；启用准入时只回写热点 key
+		if h.admitL1(key) {
+			_ = h.l1.Set(ctx, key, val, h.backfillTTL(remaining))
+			h.stats.recordL1Backfill()
 		}
-		_ = h.l1.Set(ctx, key, val, writeBackTTL)
-		h.stats.recordL1Backfill()
-		
+
 		return val, true, nil
 	}
 	h.stats.recordL2Miss()
@@ -118,6 +203,34 @@ func (h *HybridBackend) Get(ctx context.Context, key string) (interface{}, bool,
 	return nil, false, nil
 }
 
+// backfillTTL 计算回填 L1 的 TTL：min(L1WriteBackTTL, L2 剩余 TTL × 比例)
+// remaining 为 0 表示 L2 中的 key 没有过期时间。
+func (h *HybridBackend) backfillTTL(remaining time.Duration) time.Duration {
+	ttl := h.l1WriteBack
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	if remaining <= 0 {
+		return ttl
+	}
+	if bounded := h.scaleTTL(remaining); bounded < ttl {
+		ttl = bounded
+	}
+	return ttl
+}
+
+// scaleTTL 按 L1TTLRatio 缩短 TTL（未启用比例时原样返回）
+func (h *HybridBackend) scaleTTL(ttl time.Duration) time.Duration {
+	if h.l1TTLRatio <= 0 || h.l1TTLRatio >= 1 || ttl <= 0 {
+		return ttl
+	}
+	scaled := time.Duration(float64(ttl) * h.l1TTLRatio)
+	if scaled <= 0 {
+		scaled = time.Millisecond
+	}
+	return scaled
+}
+
 // Set 设置缓存值（同时写入 L1 和 L2）
 func (h *HybridBackend) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	h.mu.RLock()
@@ -128,11 +241,30 @@ func (h *HybridBackend) Set(ctx context.Context, key string, value interface{},
 	h.mu.RUnlock()
 
 	// 同时写入 L1 和 L2
-	err1 := h.l1.Set(ctx, key, value, ttl)
-	err2 := h.l2.Set(ctx, key, value, ttl)
+	degraded := false
+	var err1 error
+	if h.admitL1(key) {
+		err1 = h.l1.Set(ctx, key, value, h.scaleTTL(ttl))
+	} else {
+		// 非热点 key 不进入 L1，但要删除 L1 中可能残留的旧值
+		err1 = h.l1.Delete(ctx, key)
+	}
+	err2 := h.callL2(ctx, func(ctx context.Context) error {
+		return h.l2.Set(ctx, key, value, ttl)
+	})
+	if errors.Is(err2, ErrCircuitOpen) {
+		// 降级为只写 L1
+		err2 = nil
+		degraded = true
+	}
 
 	h.stats.recordSet()
 
+	// L2 写入成功后通知其他实例丢弃 L1 中的旧值
+	if err2 == nil && !degraded && h.invalidator != nil && !h.publishOnFlush {
+		h.invalidator.publish(ctx, key)
+	}
+
 	if err1 != nil {
 		return err1
 	}
@@ -149,16 +281,117 @@ func (h *HybridBackend) Delete(ctx context.Context, key string) error {
 	h.mu.RUnlock()
 
 	err1 := h.l1.Delete(ctx, key)
-	err2 := h.l2.Delete(ctx, key)
+	// L2 熔断时返回 ErrCircuitOpen：L2 中可能仍有旧值，调用方需要感知
+	err2 := h.callL2(ctx, func(ctx context.Context) error {
+		return h.l2.Delete(ctx, key)
+	})
 
 	h.stats.recordDelete()
 
+	if err2 == nil && h.invalidator != nil && !h.publishOnFlush {
+		h.invalidator.publish(ctx, key)
+	}
+
 	if err1 != nil {
 		return err1
 	}
 	return err2
 }
 
+// DeletePrefix 删除 L1 和 L2 中以 prefix 开头的 key，返回 L2 的删除数量
+func (h *HybridBackend) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return 0, nil
+	}
+	h.mu.RUnlock()
+
+	// 先删 L2 再删 L1：反过来时并发读可能在两步之间把 L2 的旧值回填到 L1
+	deleted := 0
+	err := h.callL2(ctx, func(ctx context.Context) error {
+		n, err := h.l2.DeletePrefix(ctx, prefix)
+		deleted = n
+		return err
+	})
+	_, _ = h.l1.DeletePrefix(ctx, prefix)
+
+	if err == nil && h.invalidator != nil {
+		h.invalidator.publishPrefixes(ctx, prefix)
+	}
+	return deleted, err
+}
+
+// admitL1 判断 key 是否可以进入 L1（未启用准入时总是允许）
+func (h *HybridBackend) admitL1(key string) bool {
+	if h.admission == nil || h.admission.admit(key) {
+		return true
+	}
+	h.stats.recordAdmissionRejected()
+	return false
+}
+
+// HotKeys 当前热点 key（按估算访问次数降序，未启用准入时返回 nil）
+func (h *HybridBackend) HotKeys() []HotKey {
+	if h.admission == nil {
+		return nil
+	}
+	return h.admission.hotKeys()
+}
+
+// callL2 通过熔断器调用 L2（未配置熔断器时直接调用）
+func (h *HybridBackend) callL2(ctx context.Context, fn func(ctx context.Context) error) error {
+	if h.l2Breaker == nil {
+		return fn(ctx)
+	}
+	err := h.l2Breaker.Execute(ctx, fn)
+	if errors.Is(err, ErrCircuitOpen) {
+		h.stats.recordL2Rejected()
+	}
+	return err
+}
+
+// Ping 探测 L2；L2 不可用或熔断器打开时只用 L1 服务，返回 ErrDegraded
+func (h *HybridBackend) Ping(ctx context.Context) error {
+	if h.l2Breaker != nil && h.l2Breaker.State() == CircuitOpen {
+		return fmt.Errorf("%w: L2 circuit breaker is open", ErrDegraded)
+	}
+	if err := h.l2.Ping(ctx); err != nil {
+		return fmt.Errorf("%w: L2 ping failed: %v", ErrDegraded, err)
+	}
+	return nil
+}
+
+// L2CircuitBreaker 获取 L2 熔断器（未配置时返回 nil）
+func (h *HybridBackend) L2CircuitBreaker() *CircuitBreaker {
+	return h.l2Breaker
+}
+
+// Clear 清空缓存（先清 L2，再清 L1）
+func (h *HybridBackend) Clear(ctx context.Context) error {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return nil
+	}
+	h.mu.RUnlock()
+
+	if err := h.l2.Clear(ctx); err != nil {
+		return err
+	}
+	return h.l1.Clear(ctx)
+}
+
+// Flush 立即将 L2 异步写回队列中的写入落到 Redis
+func (h *HybridBackend) Flush(ctx context.Context) error {
+	return h.l2.Flush(ctx)
+}
+
+// WriteBehindStats L2 异步写回统计（未启用时返回 nil）
+func (h *HybridBackend) WriteBehindStats() *WriteBehindStats {
+	return h.l2.WriteBehindStats()
+}
+
 // Close 关闭缓存后端
 func (h *HybridBackend) Close() error {
 	h.mu.Lock()
@@ -169,7 +402,10 @@ func (h *HybridBackend) Close() error {
 	h.closed = true
 	h.mu.Unlock()
 
-	// 先关闭 L2，再关闭 L1
+	// 先停止失效订阅，再关闭 L2，最后关闭 L1
+	if h.invalidator != nil {
+		_ = h.invalidator.close()
+	}
 	err1 := h.l2.Close()
 	err2 := h.l1.Close()
 
@@ -198,14 +434,15 @@ func (h *HybridBackend) Stats() *CacheStats {
 	}
 
 	return &CacheStats{
-		Hits:      totalHits,
-		Misses:    totalMisses,
-		Sets:      h.stats.getSets(),
-		Deletes:   h.stats.getDeletes(),
-		Evictions: l1Stats.Evictions, // L1 的淘汰数
-		Size:      l1Stats.Size + l2Stats.Size,
-		MaxSize:   l1Stats.MaxSize, // L1 的最大容量
-		HitRate:   hitRate,
+		Hits:        totalHits,
+		Misses:      totalMisses,
+		Sets:        h.stats.getSets(),
+		Deletes:     h.stats.getDeletes(),
+		Evictions:   l1Stats.Evictions, // L1 的淘汰数
+		Size:        l1Stats.Size + l2Stats.Size,
+		MaxSize:     l1Stats.MaxSize,     // L1 的最大容量
+		HitRate:     hitRate,
+		MemoryBytes: l2Stats.MemoryBytes, // L2 命名空间的内存估算
 	}
 }
 
@@ -218,6 +455,10 @@ func (s *HybridStats) recordL2Fallback() { atomicAddInt64(&s.l2Fallbacks, 1) }
 func (s *HybridStats) recordL1Backfill() { atomicAddInt64(&s.l1Backfills, 1) }
 func (s *HybridStats) recordSet()       { atomicAddInt64(&s.sets, 1) }
 func (s *HybridStats) recordDelete()    { atomicAddInt64(&s.deletes, 1) }
+func (s *HybridStats) recordInvalidationSent()     { atomicAddInt64(&s.invalidationsSent, 1) }
+func (s *HybridStats) recordInvalidationReceived() { atomicAddInt64(&s.invalidationsReceived, 1) }
+func (s *HybridStats) recordL2Rejected()           { atomicAddInt64(&s.l2Rejected, 1) }
+func (s *HybridStats) recordAdmissionRejected()    { atomicAddInt64(&s.admissionRejected, 1) }
 
 func (s *HybridStats) getL1Hits() int64     { return atomicLoadInt64(&s.l1Hits) }
 func (s *HybridStats) getL1Misses() int64   { return atomicLoadInt64(&s.l1Misses) }
@@ -228,6 +469,18 @@ func (s *HybridStats) getL1Backfills() int64 { return atomicLoadInt64(&s.l1Backf
 func (s *HybridStats) getSets() int64       { return atomicLoadInt64(&s.sets) }
 func (s *HybridStats) getDeletes() int64    { return atomicLoadInt64(&s.deletes) }
 
+// InvalidationsSent 广播的 L1 失效消息数
+func (s *HybridStats) InvalidationsSent() int64 { return atomicLoadInt64(&s.invalidationsSent) }
+
+// InvalidationsReceived 收到并生效的 L1 失效消息数
+func (s *HybridStats) InvalidationsReceived() int64 { return atomicLoadInt64(&s.invalidationsReceived) }
+
+// L2Rejected L2 熔断期间被跳过的操作数
+func (s *HybridStats) L2Rejected() int64 { return atomicLoadInt64(&s.l2Rejected) }
+
+// AdmissionRejected 未达到准入阈值、没有进入 L1 的次数
+func (s *HybridStats) AdmissionRejected() int64 { return atomicLoadInt64(&s.admissionRejected) }
+
 // GetL1 获取 L1 缓存（用于高级操作）
 func (h *HybridBackend) GetL1() *MemoryBackend {
 	return h.l1
@@ -245,6 +498,9 @@ func (h *HybridBackend) GetHybridStats() *HybridStats {
 
 // 确保实现 CacheBackend 接口
 var _ CacheBackend = (*HybridBackend)(nil)
+var _ WriteBehindBackend = (*HybridBackend)(nil)
+var _ HealthChecker = (*HybridBackend)(nil)
+var _ PrefixDeleter = (*HybridBackend)(nil)
 
 // init 注册混合缓存后端
 func init() {
@@ -252,6 +508,14 @@ func init() {
 		hybridConfig := DefaultHybridConfig()
 		hybridConfig.L1Config = config
 		hybridConfig.L2Config.Addr = "localhost:6379" // 默认 Redis 地址
+		if err := applyRedisOptions(hybridConfig.L2Config, config); err != nil {
+			return nil, err
+		}
+		breaker, err := circuitBreakerFromOptions(config)
+		if err != nil {
+			return nil, err
+		}
+		hybridConfig.L2CircuitBreaker = breaker
 		return NewHybridBackend(hybridConfig)
 	})
 }
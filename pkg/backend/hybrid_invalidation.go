@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/logger"
+)
+
+// l1Invalidator HybridBackend 的跨实例 L1 失效器
+// 本实例每次 Set/Delete 后通过 InvalidationBus 广播 {origin, cache, keys} 信封，
+// 其他实例收到后删除自己 L1 中的对应 key，忽略自己发出的消息。
+type l1Invalidator struct {
+	bus       InvalidationBus
+	ownsBus   bool // 总线由 HybridBackend 创建，关闭时一并关闭
+	cacheName string
+	originID  string
+	l1        *MemoryBackend
+	stats     *HybridStats
+
+	recovery         RecoveryAction // 总线重连后对 L1 的恢复动作
+	recoveryPrefixes []string
+
+	unsubscribe func()
+	closed      int32
+}
+
+// newL1Invalidator 订阅失效总线，recovery 为总线重连后对 L1 的恢复动作
+func newL1Invalidator(bus InvalidationBus, ownsBus bool, cacheName, originID string, l1 *MemoryBackend, stats *HybridStats,
+	recovery RecoveryAction, recoveryPrefixes []string) (*l1Invalidator, error) {
+	inv := &l1Invalidator{
+		bus:              bus,
+		ownsBus:          ownsBus,
+		cacheName:        cacheName,
+		originID:         originID,
+		l1:               l1,
+		stats:            stats,
+		recovery:         recovery,
+		recoveryPrefixes: recoveryPrefixes,
+	}
+
+	unsubscribe, err := bus.Subscribe(inv.handle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to invalidation bus: %w", err)
+	}
+	inv.unsubscribe = unsubscribe
+
+	if notifier, ok := bus.(ReconnectNotifier); ok {
+		notifier.OnReconnect(inv.recover)
+	}
+	return inv, nil
+}
+
+// recover 总线重连后恢复 L1：断开期间可能漏掉了失效消息
+func (inv *l1Invalidator) recover(downtime time.Duration) {
+	if atomic.LoadInt32(&inv.closed) == 1 {
+		return
+	}
+	if err := inv.recovery.Apply(context.Background(), inv.l1, inv.recoveryPrefixes...); err != nil {
+		logger.Warn("Hybrid backend: L1 recovery after reconnect failed: %v", err)
+		return
+	}
+	logger.Info("Hybrid backend: invalidation bus reconnected after %v, L1 recovery=%s", downtime, inv.recovery)
+}
+
+// handle 处理一条失效消息
+func (inv *l1Invalidator) handle(ctx context.Context, message *InvalidationMessage) {
+	if message.Origin == inv.originID || message.CacheName != inv.cacheName {
+		return
+	}
+
+	for _, key := range message.AllKeys() {
+		_ = inv.l1.Delete(ctx, key)
+	}
+	for _, prefix := range message.Prefixes {
+		_, _ = inv.l1.DeletePrefix(ctx, prefix)
+	}
+	if len(message.Tags) > 0 {
+		// L1 不记录标签，按标签失效时清空整个 L1
+		_ = inv.l1.Clear(ctx)
+	}
+	inv.stats.recordInvalidationReceived()
+	logger.Debug("Hybrid backend: L1 invalidated by %s, cache=%s, keys=%v, prefixes=%v, tags=%v",
+		message.Origin, message.CacheName, message.AllKeys(), message.Prefixes, message.Tags)
+}
+
+// publish 广播一条失效消息（失败只记录日志，不影响写操作本身）
+func (inv *l1Invalidator) publish(ctx context.Context, keys ...string) {
+	inv.send(ctx, &InvalidationMessage{
+		Origin:    inv.originID,
+		CacheName: inv.cacheName,
+		Keys:      keys,
+	})
+}
+
+// publishPrefixes 广播按前缀失效的消息
+func (inv *l1Invalidator) publishPrefixes(ctx context.Context, prefixes ...string) {
+	inv.send(ctx, &InvalidationMessage{
+		Origin:    inv.originID,
+		CacheName: inv.cacheName,
+		Prefixes:  prefixes,
+	})
+}
+
+// send 发布失效消息并记录统计
+func (inv *l1Invalidator) send(ctx context.Context, message *InvalidationMessage) {
+	message.InjectTrace(ctx)
+	if err := inv.bus.Publish(ctx, message); err != nil {
+		logger.Warn("Hybrid backend: failed to publish invalidation for keys=%v, prefixes=%v: %v",
+			message.Keys, message.Prefixes, err)
+		return
+	}
+	inv.stats.recordInvalidationSent()
+}
+
+// close 取消订阅；总线由自己创建时一并关闭
+func (inv *l1Invalidator) close() error {
+	atomic.StoreInt32(&inv.closed, 1)
+	inv.unsubscribe()
+	if inv.ownsBus {
+		return inv.bus.Close()
+	}
+	return nil
+}
+
+// NewInstanceID 生成本进程实例 ID：hostname-pid-随机串
+func NewInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf))
+}
@@ -239,3 +239,247 @@ func TestHybridBackend_Concurrent(t *testing.T) {
 		<-done
 	}
 }
+
+func TestHybridBackend_BackfillTTL(t *testing.T) {
+	h := &HybridBackend{l1WriteBack: 5 * time.Minute}
+
+	// L2 剩余 TTL 更短时以剩余 TTL 为准
+	if got := h.backfillTTL(3 * time.Second); got != 3*time.Second {
+		t.Errorf("Expected 3s, got %v", got)
+	}
+	// L2 剩余 TTL 更长时以 L1WriteBackTTL 为上限
+	if got := h.backfillTTL(time.Hour); got != 5*time.Minute {
+		t.Errorf("Expected 5m, got %v", got)
+	}
+	// L2 没有过期时间
+	if got := h.backfillTTL(0); got != 5*time.Minute {
+		t.Errorf("Expected 5m, got %v", got)
+	}
+
+	// 按比例缩短
+	h.l1TTLRatio = 0.5
+	if got := h.backfillTTL(4 * time.Second); got != 2*time.Second {
+		t.Errorf("Expected 2s, got %v", got)
+	}
+	if got := h.backfillTTL(time.Hour); got != 5*time.Minute {
+		t.Errorf("Expected ratio result to be capped at 5m, got %v", got)
+	}
+	if got := h.scaleTTL(10 * time.Minute); got != 5*time.Minute {
+		t.Errorf("Expected scaled set TTL 5m, got %v", got)
+	}
+}
+
+func TestHybridBackend_BackfillBoundedByL2(t *testing.T) {
+	config := DefaultHybridConfig()
+	config.L1Config.Name = "test-hybrid-ttl"
+	config.L2Config.StatsSampleInterval = 0
+
+	hybrid, err := NewHybridBackend(config)
+	if err != nil {
+		t.Skipf("Redis not available, skipping hybrid backend test: %v", err)
+	}
+	defer hybrid.Close()
+
+	ctx := context.Background()
+	if err := hybrid.GetL2().Set(ctx, "test:short", "value", 2*time.Second); err != nil {
+		t.Fatalf("Failed to set L2: %v", err)
+	}
+
+	if _, found, _ := hybrid.Get(ctx, "test:short"); !found {
+		t.Fatal("Expected L2 hit")
+	}
+	_, found, remaining, _ := hybrid.GetL1().GetWithTTL(ctx, "test:short")
+	if !found {
+		t.Fatal("Expected L1 to be backfilled")
+	}
+	if remaining > 2*time.Second {
+		t.Errorf("Expected L1 TTL bounded by L2 remaining TTL, got %v", remaining)
+	}
+}
+
+func TestHybridBackend_InvalidationHandle(t *testing.T) {
+	l1, _ := NewMemoryBackend(DefaultCacheConfig("users"))
+	defer l1.Close()
+
+	bus := NewInProcessBus()
+	stats := &HybridStats{}
+	inv, err := newL1Invalidator(bus, true, "users", "node-a", l1, stats, RecoveryFlushAll, nil)
+	if err != nil {
+		t.Fatalf("Failed to create invalidator: %v", err)
+	}
+	defer inv.close()
+
+	ctx := context.Background()
+	l1.Set(ctx, "user:1", "alice", time.Minute)
+
+	// 自己发出的消息被忽略
+	inv.publish(ctx, "user:1")
+	if _, found, _ := l1.Get(ctx, "user:1"); !found {
+		t.Error("Expected own invalidation to be ignored")
+	}
+	if got := stats.InvalidationsSent(); got != 1 {
+		t.Errorf("Expected 1 sent invalidation, got %d", got)
+	}
+
+	// 其他缓存的消息被忽略
+	bus.Publish(ctx, &InvalidationMessage{Origin: "node-b", CacheName: "orders", Keys: []string{"user:1"}})
+	if _, found, _ := l1.Get(ctx, "user:1"); !found {
+		t.Error("Expected invalidation for another cache to be ignored")
+	}
+
+	// 其他实例的消息删除 L1
+	bus.Publish(ctx, &InvalidationMessage{Origin: "node-b", CacheName: "users", Keys: []string{"user:1"}})
+	if _, found, _ := l1.Get(ctx, "user:1"); found {
+		t.Error("Expected key to be evicted from L1")
+	}
+	if got := stats.InvalidationsReceived(); got != 1 {
+		t.Errorf("Expected 1 received invalidation, got %d", got)
+	}
+}
+
+func TestHybridBackend_CrossInstanceInvalidation(t *testing.T) {
+	newInstance := func(id string) *HybridBackend {
+		config := DefaultHybridConfig()
+		config.L1Config.Name = "test-hybrid-inv"
+		config.L2Config.StatsSampleInterval = 0
+		config.InvalidationChannel = "test-hybrid-inv:invalidate"
+		config.InstanceID = id
+		h, err := NewHybridBackend(config)
+		if err != nil {
+			t.Skipf("Redis not available, skipping: %v", err)
+		}
+		return h
+	}
+
+	a := newInstance("node-a")
+	defer a.Close()
+	b := newInstance("node-b")
+	defer b.Close()
+
+	ctx := context.Background()
+	if err := a.Set(ctx, "user:1", "v1", time.Minute); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	// b 读取后 L1 中有旧值
+	if val, _, _ := b.Get(ctx, "user:1"); val != "v1" {
+		t.Fatalf("Expected v1, got %v", val)
+	}
+
+	a.Set(ctx, "user:1", "v2", time.Minute)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, found, _ := b.GetL1().Get(ctx, "user:1"); !found {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if val, _, _ := b.Get(ctx, "user:1"); val != "v2" {
+		t.Errorf("Expected v2 after invalidation, got %v", val)
+	}
+	// a 自己的 L1 不受自身消息影响
+	if _, found, _ := a.GetL1().Get(ctx, "user:1"); !found {
+		t.Error("Expected own L1 entry to survive own invalidation")
+	}
+	a.Delete(ctx, "user:1")
+}
+
+func TestHybridBackend_CrossInstanceDeletePrefix(t *testing.T) {
+	newInstance := func(id string) *HybridBackend {
+		config := DefaultHybridConfig()
+		config.L1Config.Name = "test-hybrid-inv-prefix"
+		config.L2Config.StatsSampleInterval = 0
+		config.InvalidationChannel = "test-hybrid-inv-prefix:invalidate"
+		config.InstanceID = id
+		h, err := NewHybridBackend(config)
+		if err != nil {
+			t.Skipf("Redis not available, skipping: %v", err)
+		}
+		return h
+	}
+
+	a := newInstance("node-a")
+	defer a.Close()
+	b := newInstance("node-b")
+	defer b.Close()
+
+	ctx := context.Background()
+	a.Set(ctx, "user:1", "v1", time.Minute)
+	a.Set(ctx, "order:1", "o1", time.Minute)
+	// b 读取后 L1 中有值
+	b.Get(ctx, "user:1")
+	b.Get(ctx, "order:1")
+
+	if _, err := a.DeletePrefix(ctx, "user:"); err != nil {
+		t.Fatalf("DeletePrefix failed: %v", err)
+	}
+	if _, found, _ := a.GetL1().Get(ctx, "user:1"); found {
+		t.Error("Expected own L1 to be cleared by prefix")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, found, _ := b.GetL1().Get(ctx, "user:1"); !found {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, found, _ := b.Get(ctx, "user:1"); found {
+		t.Error("Expected user:1 to be invalidated on other instance")
+	}
+	if _, found, _ := b.GetL1().Get(ctx, "order:1"); !found {
+		t.Error("Expected order:1 to survive prefix invalidation")
+	}
+	a.Delete(ctx, "order:1")
+}
+
+func TestHybridBackend_PublishPrefixes(t *testing.T) {
+	l1, _ := NewMemoryBackend(DefaultCacheConfig("users"))
+	defer l1.Close()
+
+	bus := NewInProcessBus()
+	stats := &HybridStats{}
+	inv, err := newL1Invalidator(bus, true, "users", "node-a", l1, stats, RecoveryFlushAll, nil)
+	if err != nil {
+		t.Fatalf("Failed to create invalidator: %v", err)
+	}
+	defer inv.close()
+
+	var received []*InvalidationMessage
+	bus.Subscribe(func(ctx context.Context, message *InvalidationMessage) {
+		received = append(received, message)
+	})
+
+	inv.publishPrefixes(context.Background(), "user:")
+	if len(received) != 1 || len(received[0].Prefixes) != 1 || received[0].Prefixes[0] != "user:" ||
+		len(received[0].Keys) != 0 || received[0].CacheName != "users" {
+		t.Errorf("Unexpected prefix invalidation: %+v", received)
+	}
+	if got := stats.InvalidationsSent(); got != 1 {
+		t.Errorf("Expected 1 sent invalidation, got %d", got)
+	}
+}
+
+func TestHybridBackend_InvalidationPrefixAndTags(t *testing.T) {
+	l1, _ := NewMemoryBackend(DefaultCacheConfig("users"))
+	defer l1.Close()
+	inv := &l1Invalidator{cacheName: "users", originID: "node-a", l1: l1, stats: &HybridStats{}}
+
+	ctx := context.Background()
+	l1.Set(ctx, "user:1", "alice", time.Minute)
+	l1.Set(ctx, "user:2", "bob", time.Minute)
+	l1.Set(ctx, "order:1", "book", time.Minute)
+
+	inv.handle(ctx, &InvalidationMessage{Origin: "node-b", CacheName: "users", Prefixes: []string{"user:"}})
+	if _, found, _ := l1.Get(ctx, "user:1"); found {
+		t.Error("Expected user:1 to be invalidated by prefix")
+	}
+	if _, found, _ := l1.Get(ctx, "order:1"); !found {
+		t.Error("Expected order:1 to survive prefix invalidation")
+	}
+
+	inv.handle(ctx, &InvalidationMessage{Origin: "node-b", CacheName: "users", Tags: []string{"vip"}})
+	if _, found, _ := l1.Get(ctx, "order:1"); found {
+		t.Error("Expected tag invalidation to clear L1")
+	}
+}
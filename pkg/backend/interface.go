@@ -17,10 +17,41 @@ type CacheBackend interface {
 	Stats() *CacheStats
 }
 
+// TTLGetter 可在读取时返回剩余 TTL 的后端
+// 多级缓存回填上层时用它保证上层不会比数据源活得更久。
+type TTLGetter interface {
+	// GetWithTTL 返回值及剩余 TTL，剩余 TTL 为 0 表示没有过期时间
+	GetWithTTL(ctx context.Context, key string) (interface{}, bool, time.Duration, error)
+}
+
+// Clearer 可清空全部数据的后端
+type Clearer interface {
+	Clear(ctx context.Context) error
+}
+
+// RemovalNotifier 可在条目被移除（过期、容量淘汰、删除、清空）时通知的后端
+type RemovalNotifier interface {
+	// OnRemove 注册移除回调；回调在后端持锁时同步调用，不能再访问该后端
+	OnRemove(fn func(key string))
+}
+
+// PrefixDeleter 可按 key 前缀批量删除的后端
+type PrefixDeleter interface {
+	// DeletePrefix 删除所有以 prefix 开头的 key，返回删除数量
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// HealthChecker 可探测连通性的后端（Redis、Redis Cluster、Hybrid、Tiered）
+type HealthChecker interface {
+	// Ping 探测后端；返回包装了 ErrDegraded 的错误表示仍可服务但部分依赖不可用
+	Ping(ctx context.Context) error
+}
+
 // CacheStats 缓存统计
 type CacheStats struct {
 	Hits, Misses, Sets, Deletes, Evictions, Size, MaxSize int64
 	HitRate                                               float64
+	MemoryBytes                                           int64 // 估算内存占用（字节），不支持的后端为 0
 }
 
 // BackendFactory 后端工厂函数类型
@@ -33,6 +64,19 @@ type CacheConfig struct {
 	DefaultTTL     time.Duration
 	MaxTTL         time.Duration
 	EvictionPolicy string
+
+	// Backend 后端名称（如 memory、redis），为空时由 CacheManager 按规则或默认后端选择
+	Backend string
+	// Options 后端专属选项，如 redis 的 addr、password、db、prefix
+	Options map[string]string
+}
+
+// Option 返回后端选项，未设置时返回 def
+func (c *CacheConfig) Option(key, def string) string {
+	if v, ok := c.Options[key]; ok && v != "" {
+		return v
+	}
+	return def
 }
 
 // BackendRegistry 后端注册表
@@ -84,6 +128,8 @@ func (e *BackendError) Error() string {
 var (
 	ErrEmptyName      = &BackendError{Code: "EMPTY_NAME", Message: "缓存名称不能为空"}
 	ErrInvalidMaxSize = &BackendError{Code: "INVALID_MAX_SIZE", Message: "最大容量必须大于 0"}
+	// ErrDegraded 后端降级：部分依赖不可用但仍可服务（如 Hybrid 的 L2 不可用时只用 L1）
+	ErrDegraded = &BackendError{Code: "DEGRADED", Message: "backend degraded"}
 )
 
 // KeyBuilder 键构建器
@@ -0,0 +1,385 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/logger"
+)
+
+// InvalidationHandler 失效消息处理函数
+type InvalidationHandler func(ctx context.Context, message *InvalidationMessage)
+
+// InvalidationBus 失效消息总线
+// 总线只负责投递，不保证过滤本实例发出的消息，
+// 订阅者需要根据 message.Origin 自行忽略。
+type InvalidationBus interface {
+	// Publish 发布失效消息
+	Publish(ctx context.Context, message *InvalidationMessage) error
+	// Subscribe 注册处理函数，返回取消订阅函数
+	Subscribe(handler InvalidationHandler) (unsubscribe func(), err error)
+	// Close 关闭总线
+	Close() error
+}
+
+// ReconnectNotifier 可以通知订阅断开与恢复的失效总线
+// 断开期间的失效消息可能丢失，订阅方应在重连后执行恢复动作（如清空 L1）。
+type ReconnectNotifier interface {
+	OnDisconnect(fn func(err error))
+	OnReconnect(fn func(downtime time.Duration))
+}
+
+// handlerRegistry 订阅者列表（各总线实现共用）
+type handlerRegistry struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]InvalidationHandler
+}
+
+func (r *handlerRegistry) add(handler InvalidationHandler) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.handlers == nil {
+		r.handlers = make(map[int]InvalidationHandler)
+	}
+	id := r.nextID
+	r.nextID++
+	r.handlers[id] = handler
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			delete(r.handlers, id)
+		})
+	}
+}
+
+func (r *handlerRegistry) dispatch(ctx context.Context, message *InvalidationMessage) {
+	r.mu.RLock()
+	handlers := make([]InvalidationHandler, 0, len(r.handlers))
+	for _, h := range r.handlers {
+		handlers = append(handlers, h)
+	}
+	r.mu.RUnlock()
+
+	ctx = message.ExtractTrace(ctx)
+	for _, h := range handlers {
+		h(ctx, message)
+	}
+}
+
+// ========== 进程内总线 ==========
+
+// InProcessBus 进程内失效总线（测试和单进程部署）
+// Publish 同步调用所有订阅者。
+type InProcessBus struct {
+	registry handlerRegistry
+	mu       sync.RWMutex
+	closed   bool
+}
+
+// NewInProcessBus 创建进程内失效总线
+func NewInProcessBus() *InProcessBus {
+	return &InProcessBus{}
+}
+
+// Publish 发布失效消息
+func (b *InProcessBus) Publish(ctx context.Context, message *InvalidationMessage) error {
+	b.mu.RLock()
+	closed := b.closed
+	b.mu.RUnlock()
+	if closed {
+		return errors.New("InProcessBus is closed")
+	}
+
+	// 经过一次编解码，与跨进程总线的行为保持一致（Key 并入 Keys，填充版本和时间戳）
+	data, err := message.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	decoded, err := UnmarshalInvalidationMessage(data)
+	if err != nil {
+		return err
+	}
+	b.registry.dispatch(ctx, decoded)
+	return nil
+}
+
+// Subscribe 注册处理函数
+func (b *InProcessBus) Subscribe(handler InvalidationHandler) (func(), error) {
+	return b.registry.add(handler), nil
+}
+
+// Close 关闭总线
+func (b *InProcessBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// ========== Redis Pub/Sub 总线 ==========
+
+// RedisInvalidationBus 基于 CacheInvalidator 的 Redis Pub/Sub 失效总线
+// 订阅由一个后台协程从 pub/sub 频道读取并分发；不要再对同一个 CacheInvalidator 调用 Subscribe。
+type RedisInvalidationBus struct {
+	invalidator *CacheInvalidator
+	registry    handlerRegistry
+	startOnce   sync.Once
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewRedisInvalidationBus 用已有的 CacheInvalidator 创建失效总线
+func NewRedisInvalidationBus(invalidator *CacheInvalidator) *RedisInvalidationBus {
+	return &RedisInvalidationBus{
+		invalidator: invalidator,
+		done:        make(chan struct{}),
+	}
+}
+
+// Publish 发布失效消息
+func (b *RedisInvalidationBus) Publish(ctx context.Context, message *InvalidationMessage) error {
+	return b.invalidator.Publish(ctx, message)
+}
+
+// Subscribe 注册处理函数（首次订阅时启动分发协程）
+func (b *RedisInvalidationBus) Subscribe(handler InvalidationHandler) (func(), error) {
+	if b.invalidator.IsClosed() {
+		return nil, errors.New("CacheInvalidator is closed")
+	}
+	unsubscribe := b.registry.add(handler)
+	b.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		b.cancel = cancel
+		go func() {
+			defer close(b.done)
+			err := b.invalidator.SubscribeMessages(ctx, func(message *InvalidationMessage) {
+				b.registry.dispatch(context.Background(), message)
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				logger.Warn("RedisInvalidationBus: subscription ended: %v", err)
+			}
+		}()
+	})
+	return unsubscribe, nil
+}
+
+// OnDisconnect 注册订阅断开回调
+func (b *RedisInvalidationBus) OnDisconnect(fn func(err error)) {
+	b.invalidator.OnDisconnect(fn)
+}
+
+// OnReconnect 注册重新订阅成功回调
+func (b *RedisInvalidationBus) OnReconnect(fn func(downtime time.Duration)) {
+	b.invalidator.OnReconnect(fn)
+}
+
+// Invalidator 获取底层 CacheInvalidator
+func (b *RedisInvalidationBus) Invalidator() *CacheInvalidator {
+	return b.invalidator
+}
+
+// Close 停止分发并关闭底层 CacheInvalidator
+func (b *RedisInvalidationBus) Close() error {
+	err := b.invalidator.Close()
+	if b.cancel != nil {
+		b.cancel()
+		<-b.done
+	}
+	return err
+}
+
+// ========== HTTP Webhook 总线 ==========
+
+// WebhookTokenHeader Webhook 鉴权请求头
+const WebhookTokenHeader = "X-Go-Cache-Token"
+
+// WebhookBusConfig HTTP Webhook 失效总线配置
+type WebhookBusConfig struct {
+	Peers   []string      // 其他实例的 Webhook 地址，如 "http://10.0.0.2:8080/cache/invalidate"
+	Token   string        // 共享令牌（为空表示不校验）
+	Timeout time.Duration // 单个 peer 的请求超时
+	Client  *http.Client  // 自定义 HTTP 客户端（为空时使用默认客户端）
+	MaxBody int64         // 接收消息的最大字节数
+}
+
+// DefaultWebhookBusConfig 默认 Webhook 总线配置
+func DefaultWebhookBusConfig() *WebhookBusConfig {
+	return &WebhookBusConfig{
+		Timeout: 3 * time.Second,
+		MaxBody: 1 << 20,
+	}
+}
+
+// WebhookBus HTTP Webhook 失效总线
+// Publish 并发 POST 到所有 peer；本实例通过 ServeHTTP 接收其他实例的消息，
+// 需要由应用挂载到 HTTP 路由上（peer 列表中不应包含自己）。
+type WebhookBus struct {
+	config   *WebhookBusConfig
+	client   *http.Client
+	registry handlerRegistry
+
+	mu     sync.RWMutex
+	peers  []string
+	closed bool
+}
+
+// NewWebhookBus 创建 HTTP Webhook 失效总线
+func NewWebhookBus(config *WebhookBusConfig) *WebhookBus {
+	if config == nil {
+		config = DefaultWebhookBusConfig()
+	}
+	c := *config
+	defaults := DefaultWebhookBusConfig()
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.MaxBody <= 0 {
+		c.MaxBody = defaults.MaxBody
+	}
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: c.Timeout}
+	}
+	return &WebhookBus{
+		config: &c,
+		client: client,
+		peers:  append([]string(nil), c.Peers...),
+	}
+}
+
+// SetPeers 替换 peer 列表（用于服务发现更新）
+func (b *WebhookBus) SetPeers(peers []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peers = append([]string(nil), peers...)
+}
+
+// Peers 当前 peer 列表
+func (b *WebhookBus) Peers() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]string(nil), b.peers...)
+}
+
+// Publish 将消息并发发送到所有 peer，返回所有失败 peer 的合并错误
+func (b *WebhookBus) Publish(ctx context.Context, message *InvalidationMessage) error {
+	b.mu.RLock()
+	closed := b.closed
+	peers := b.peers
+	b.mu.RUnlock()
+	if closed {
+		return errors.New("WebhookBus is closed")
+	}
+
+	// 在副本上写入追踪上下文，不修改调用方的消息
+	out := *message
+	if out.Trace == nil {
+		out.InjectTrace(ctx)
+	}
+	data, err := out.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	errs := make([]error, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			if err := b.post(ctx, peer, data); err != nil {
+				errs[i] = fmt.Errorf("peer %s: %w", peer, err)
+			}
+		}(i, peer)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// post 发送到单个 peer
+func (b *WebhookBus) post(ctx context.Context, peer string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, b.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.config.Token != "" {
+		req.Header.Set(WebhookTokenHeader, b.config.Token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Subscribe 注册处理函数
+func (b *WebhookBus) Subscribe(handler InvalidationHandler) (func(), error) {
+	return b.registry.add(handler), nil
+}
+
+// ServeHTTP 接收其他实例发来的失效消息
+func (b *WebhookBus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if b.config.Token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(WebhookTokenHeader)), []byte(b.config.Token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, b.config.MaxBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	message, err := UnmarshalInvalidationMessage(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b.registry.dispatch(r.Context(), message)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Close 关闭总线（之后的 Publish 返回错误）
+func (b *WebhookBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// 确保实现 InvalidationBus 接口
+var _ InvalidationBus = (*InProcessBus)(nil)
+var _ InvalidationBus = (*RedisInvalidationBus)(nil)
+var _ InvalidationBus = (*WebhookBus)(nil)
+var _ ReconnectNotifier = (*RedisInvalidationBus)(nil)
@@ -0,0 +1,225 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInProcessBus_PublishSubscribe(t *testing.T) {
+	bus := NewInProcessBus()
+	defer bus.Close()
+
+	var got []*InvalidationMessage
+	unsubscribe, err := bus.Subscribe(func(ctx context.Context, message *InvalidationMessage) {
+		got = append(got, message)
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := bus.Publish(ctx, &InvalidationMessage{Origin: "node-a", CacheName: "users", Key: "user:1"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(got))
+	}
+	if got[0].Version != InvalidationVersion || got[0].Key != "user:1" || got[0].Origin != "node-a" {
+		t.Errorf("Unexpected message: %+v", got[0])
+	}
+
+	unsubscribe()
+	bus.Publish(ctx, &InvalidationMessage{CacheName: "users", Key: "user:2"})
+	if len(got) != 1 {
+		t.Errorf("Expected no delivery after unsubscribe, got %d messages", len(got))
+	}
+
+	bus.Close()
+	if err := bus.Publish(ctx, &InvalidationMessage{Key: "user:3"}); err == nil {
+		t.Error("Expected error publishing to closed bus")
+	}
+}
+
+func TestWebhookBus_FanOut(t *testing.T) {
+	const token = "secret"
+
+	// 两个接收端
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	newPeer := func(name string) (*WebhookBus, *httptest.Server) {
+		config := DefaultWebhookBusConfig()
+		config.Token = token
+		bus := NewWebhookBus(config)
+		bus.Subscribe(func(ctx context.Context, message *InvalidationMessage) {
+			mu.Lock()
+			defer mu.Unlock()
+			received[name] = append(received[name], message.AllKeys()...)
+		})
+		return bus, httptest.NewServer(bus)
+	}
+	_, srvB := newPeer("b")
+	defer srvB.Close()
+	_, srvC := newPeer("c")
+	defer srvC.Close()
+
+	config := DefaultWebhookBusConfig()
+	config.Token = token
+	config.Peers = []string{srvB.URL, srvC.URL}
+	sender := NewWebhookBus(config)
+	defer sender.Close()
+
+	err := sender.Publish(context.Background(), &InvalidationMessage{Origin: "a", CacheName: "users", Keys: []string{"user:1", "user:2"}})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range []string{"b", "c"} {
+		if len(received[name]) != 2 || received[name][0] != "user:1" {
+			t.Errorf("Peer %s: expected [user:1 user:2], got %v", name, received[name])
+		}
+	}
+}
+
+func TestWebhookBus_PublishKeepsMessage(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(prev)
+
+	received := make(chan *InvalidationMessage, 1)
+	receiver := NewWebhookBus(DefaultWebhookBusConfig())
+	receiver.Subscribe(func(ctx context.Context, message *InvalidationMessage) { received <- message })
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	config := DefaultWebhookBusConfig()
+	config.Peers = []string{srv.URL}
+	sender := NewWebhookBus(config)
+	defer sender.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	// 追踪上下文写入发送的副本，调用方的消息可以复用
+	message := &InvalidationMessage{CacheName: "users", Keys: []string{"user:1"}}
+	if err := sender.Publish(ctx, message); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if message.Trace != nil {
+		t.Errorf("Expected caller's message to be left unchanged, got trace %v", message.Trace)
+	}
+	if got := <-received; got.Trace["traceparent"] == "" {
+		t.Error("Expected trace to be sent to peers")
+	}
+}
+
+func TestWebhookBus_PeerFailure(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	ok := httptest.NewServer(NewWebhookBus(nil))
+	defer ok.Close()
+
+	config := DefaultWebhookBusConfig()
+	config.Peers = []string{ok.URL, failing.URL}
+	bus := NewWebhookBus(config)
+
+	err := bus.Publish(context.Background(), &InvalidationMessage{Key: "k"})
+	if err == nil {
+		t.Fatal("Expected error for failing peer")
+	}
+	if !bytes.Contains([]byte(err.Error()), []byte(failing.URL)) {
+		t.Errorf("Expected error to name failing peer, got %v", err)
+	}
+}
+
+func TestWebhookBus_ServeHTTP(t *testing.T) {
+	config := DefaultWebhookBusConfig()
+	config.Token = "secret"
+	config.MaxBody = 256
+	bus := NewWebhookBus(config)
+
+	calls := 0
+	bus.Subscribe(func(ctx context.Context, message *InvalidationMessage) { calls++ })
+
+	body, _ := (&InvalidationMessage{CacheName: "users", Key: "user:1"}).Marshal()
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		body   []byte
+		status int
+	}{
+		{"wrong method", http.MethodGet, "secret", nil, http.StatusMethodNotAllowed},
+		{"missing token", http.MethodPost, "", body, http.StatusUnauthorized},
+		{"bad body", http.MethodPost, "secret", []byte(`{"v":99}`), http.StatusBadRequest},
+		{"truncated json", http.MethodPost, "secret", body[:len(body)-5], http.StatusBadRequest},
+		{"too large", http.MethodPost, "secret", bytes.Repeat([]byte("k"), 300), http.StatusRequestEntityTooLarge},
+		{"ok", http.MethodPost, "secret", body, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/invalidate", bytes.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set(WebhookTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			bus.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 dispatched message, got %d", calls)
+	}
+}
+
+func TestRedisInvalidationBus(t *testing.T) {
+	newBus := func(id string) *RedisInvalidationBus {
+		config := DefaultCacheInvalidatorConfig()
+		config.Channel = "test:invalidation-bus"
+		config.InstanceID = id
+		ci, err := NewCacheInvalidator(config)
+		if err != nil {
+			t.Skipf("Redis not available, skipping: %v", err)
+		}
+		return NewRedisInvalidationBus(ci)
+	}
+
+	a := newBus("node-a")
+	defer a.Close()
+	b := newBus("node-b")
+	defer b.Close()
+
+	got := make(chan *InvalidationMessage, 1)
+	if _, err := b.Subscribe(func(ctx context.Context, message *InvalidationMessage) {
+		got <- message
+	}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	if err := a.Publish(context.Background(), &InvalidationMessage{CacheName: "users", Key: "user:1"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	select {
+	case message := <-got:
+		if message.Origin != "node-a" || message.Key != "user:1" {
+			t.Errorf("Unexpected message: %+v", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for message")
+	}
+}
@@ -0,0 +1,187 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// InvalidationVersion 当前失效消息版本
+// 版本 0 表示旧格式：纯文本 "cache:key" / "key"，或没有 "v" 字段的 JSON。
+const InvalidationVersion = 1
+
+// InvalidationMessage 失效消息（版本化 JSON 信封）
+//
+//	{"v":1,"origin":"node-a","cache":"users","keys":["user:42"],"prefixes":["user:"],"tags":["vip"],"ts":1700000000000000000,"trace":{"traceparent":"..."}}
+type InvalidationMessage struct {
+	Version   int               `json:"v"`
+	Origin    string            `json:"origin,omitempty"`   // 发送方实例 ID，用于忽略自己发出的消息
+	CacheName string            `json:"cache,omitempty"`    // 缓存名称
+	Keys      []string          `json:"keys,omitempty"`     // 失效的 key（可批量）
+	Prefixes  []string          `json:"prefixes,omitempty"` // 按前缀失效
+	Tags      []string          `json:"tags,omitempty"`     // 按标签失效
+	Timestamp int64             `json:"ts"`                 // 发送时间（UnixNano）
+	Trace     map[string]string `json:"trace,omitempty"`    // 追踪上下文（W3C traceparent 等）
+
+	// Key 单个 key 的便捷字段：Marshal 时并入 Keys，解码时为第一个 key
+	Key string `json:"-"`
+}
+
+// legacyInvalidationJSON 版本 0 的 JSON 字段（未版本化的 {cache_name, key, origin, timestamp}）
+type legacyInvalidationJSON struct {
+	CacheName string `json:"cache_name"`
+	Key       string `json:"key"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// AllKeys 返回消息中的所有 key（合并 Key 与 Keys，去重）
+func (m *InvalidationMessage) AllKeys() []string {
+	if m.Key == "" {
+		return m.Keys
+	}
+	for _, k := range m.Keys {
+		if k == m.Key {
+			return m.Keys
+		}
+	}
+	return append([]string{m.Key}, m.Keys...)
+}
+
+// IsEmpty 消息是否不包含任何失效目标
+func (m *InvalidationMessage) IsEmpty() bool {
+	return m.Key == "" && len(m.Keys) == 0 && len(m.Prefixes) == 0 && len(m.Tags) == 0
+}
+
+// InjectTrace 将 ctx 中的追踪上下文写入消息（使用全局 TextMapPropagator）
+func (m *InvalidationMessage) InjectTrace(ctx context.Context) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		m.Trace = carrier
+	}
+}
+
+// ExtractTrace 从消息中恢复追踪上下文
+func (m *InvalidationMessage) ExtractTrace(ctx context.Context) context.Context {
+	if len(m.Trace) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(m.Trace))
+}
+
+// Marshal 序列化为当前版本的 JSON 信封
+func (m *InvalidationMessage) Marshal() ([]byte, error) {
+	out := *m
+	out.Version = InvalidationVersion
+	out.Keys = m.AllKeys()
+	if out.Timestamp == 0 {
+		out.Timestamp = time.Now().UnixNano()
+	}
+	return json.Marshal(&out)
+}
+
+// UnmarshalInvalidationMessage 反序列化消息
+// 看起来是 JSON 对象时按 JSON 信封解析（不合法时返回错误，不会当作纯文本）；
+// 其他内容按旧的纯文本格式解析，迁移期间新旧实例可以共存。
+func UnmarshalInvalidationMessage(data []byte) (*InvalidationMessage, error) {
+	trimmed := bytes.TrimSpace(data)
+	if looksLikeJSONObject(trimmed) {
+		return unmarshalInvalidationJSON(trimmed)
+	}
+	return unmarshalLegacyInvalidation(string(data)), nil
+}
+
+// looksLikeJSONObject 以 '{' 开头且其后第一个非空白字符为 '"' 或 '}'
+// 旧格式中以 Redis hash tag 开头的 key（如 "{users}:user-1"）不会被误判为 JSON。
+func looksLikeJSONObject(data []byte) bool {
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	rest := bytes.TrimLeft(data[1:], " \t\r\n")
+	return len(rest) == 0 || rest[0] == '"' || rest[0] == '}'
+}
+
+// unmarshalInvalidationJSON 解析 JSON 信封（包括没有 "v" 字段的版本 0 JSON）
+func unmarshalInvalidationJSON(data []byte) (*InvalidationMessage, error) {
+	var m InvalidationMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid invalidation message: %w", err)
+	}
+	if m.Version > InvalidationVersion {
+		return nil, fmt.Errorf("unsupported invalidation message version %d", m.Version)
+	}
+
+	if m.Version == 0 {
+		var legacy legacyInvalidationJSON
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("invalid invalidation message: %w", err)
+		}
+		if m.CacheName == "" {
+			m.CacheName = legacy.CacheName
+		}
+		if legacy.Key != "" {
+			m.Keys = append([]string{legacy.Key}, m.Keys...)
+		}
+		if m.Timestamp == 0 {
+			m.Timestamp = legacy.Timestamp
+		}
+	}
+
+	if len(m.Keys) > 0 {
+		m.Key = m.Keys[0]
+	}
+	return &m, nil
+}
+
+// unmarshalLegacyInvalidation 解析版本 0 的纯文本消息："key"、"cache:key" 或 "cache:key:timestamp"
+// 旧格式无法区分 key 本身包含的冒号，只在迁移期间使用。
+func unmarshalLegacyInvalidation(payload string) *InvalidationMessage {
+	message := func(cacheName, key string, ts int64) *InvalidationMessage {
+		return &InvalidationMessage{CacheName: cacheName, Key: key, Keys: []string{key}, Timestamp: ts}
+	}
+
+	// 尝试解析 cache:key 格式（从后往前找第一个冒号）
+	for i := len(payload) - 1; i >= 0; i-- {
+		if payload[i] == ':' && i > 0 {
+			// 检查冒号后面是否是纯数字（时间戳）
+			isTimestamp := true
+			for j := i + 1; j < len(payload); j++ {
+				if payload[j] < '0' || payload[j] > '9' {
+					isTimestamp = false
+					break
+				}
+			}
+			if isTimestamp && len(payload[i+1:]) > 0 {
+				// 格式：cache:key:timestamp，继续往前找 cache:key 分隔符
+				for k := i - 1; k >= 0; k-- {
+					if payload[k] == ':' {
+						return message(payload[:k], payload[k+1:i], parseInt64(payload[i+1:]))
+					}
+				}
+				// 只有 key:timestamp 格式
+				return message("", payload[:i], parseInt64(payload[i+1:]))
+			}
+			// 格式：cache:key（没有时间戳）
+			return message(payload[:i], payload[i+1:], time.Now().UnixNano())
+		}
+	}
+
+	// 简单格式：直接返回 key
+	return message("", payload, time.Now().UnixNano())
+}
+
+func parseInt64(s string) int64 {
+	var result int64
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0
+		}
+		result = result*10 + int64(c-'0')
+	}
+	return result
+}
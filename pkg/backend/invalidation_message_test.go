@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInvalidationMessage_RoundTrip(t *testing.T) {
+	msg := &InvalidationMessage{
+		Origin:    "node-a",
+		CacheName: "users",
+		Keys:      []string{"user:42", "user:43"},
+		Prefixes:  []string{"session:"},
+		Tags:      []string{"vip"},
+		Timestamp: 1700000000,
+	}
+
+	data, err := msg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	got, err := UnmarshalInvalidationMessage(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if got.Version != InvalidationVersion || got.Origin != "node-a" || got.CacheName != "users" {
+		t.Errorf("Unexpected header: %+v", got)
+	}
+	// key 中的冒号不会被拆分
+	if len(got.Keys) != 2 || got.Keys[0] != "user:42" || got.Key != "user:42" {
+		t.Errorf("Unexpected keys: %v (Key=%s)", got.Keys, got.Key)
+	}
+	if len(got.Prefixes) != 1 || got.Prefixes[0] != "session:" || len(got.Tags) != 1 {
+		t.Errorf("Unexpected prefixes/tags: %v %v", got.Prefixes, got.Tags)
+	}
+	if got.Timestamp != 1700000000 {
+		t.Errorf("Unexpected timestamp: %d", got.Timestamp)
+	}
+}
+
+func TestUnmarshalInvalidationMessage_Legacy(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantCache string
+		wantKey   string
+		wantTS    int64
+	}{
+		{"text key", "user-123", "", "user-123", 0},
+		{"text cache and key", "users:user-123", "users", "user-123", 0},
+		{"text with timestamp", "users:user-123:1700", "users", "user-123", 1700},
+		{"hash tag key", "{users}:user-1", "{users}", "user-1", 0},
+		{"unversioned json", `{"cache_name":"users","key":"user:42","origin":"node-b","timestamp":1700}`, "users", "user:42", 1700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := UnmarshalInvalidationMessage([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if msg.CacheName != tt.wantCache || msg.Key != tt.wantKey {
+				t.Errorf("Got cache=%q key=%q, want cache=%q key=%q", msg.CacheName, msg.Key, tt.wantCache, tt.wantKey)
+			}
+			if keys := msg.AllKeys(); len(keys) != 1 || keys[0] != tt.wantKey {
+				t.Errorf("Expected AllKeys [%s], got %v", tt.wantKey, keys)
+			}
+			if tt.wantTS != 0 && msg.Timestamp != tt.wantTS {
+				t.Errorf("Expected timestamp %d, got %d", tt.wantTS, msg.Timestamp)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInvalidationMessage_FutureVersion(t *testing.T) {
+	if _, err := UnmarshalInvalidationMessage([]byte(`{"v":99,"keys":["a"]}`)); err == nil {
+		t.Error("Expected error for unsupported version")
+	}
+}
+
+func TestUnmarshalInvalidationMessage_InvalidJSON(t *testing.T) {
+	// 截断或损坏的 JSON 不能退化为纯文本格式
+	for _, data := range []string{`{"v":1,"cache_name":"users","keys":["user:1"`, `{ "cache_name": }`, `{}garbage`} {
+		if msg, err := UnmarshalInvalidationMessage([]byte(data)); err == nil {
+			t.Errorf("Expected error for %q, got %+v", data, msg)
+		}
+	}
+}
+
+func TestInvalidationMessage_Trace(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(prev)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	msg := &InvalidationMessage{Keys: []string{"a"}}
+	msg.InjectTrace(ctx)
+	if msg.Trace["traceparent"] == "" {
+		t.Fatal("Expected traceparent to be injected")
+	}
+
+	data, _ := msg.Marshal()
+	decoded, _ := UnmarshalInvalidationMessage(data)
+	got := trace.SpanContextFromContext(decoded.ExtractTrace(context.Background()))
+	if got.TraceID() != traceID {
+		t.Errorf("Expected trace ID %s, got %s", traceID, got.TraceID())
+	}
+}
@@ -3,6 +3,7 @@ package backend
 import (
 	"container/list"
 	"context"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -74,6 +75,7 @@ type MemoryBackend struct {
 	stopCleanup chan struct{}
 	cleanupDone chan struct{}
 	closed      bool
+	onRemove    []func(key string) // 条目移除回调（持有 mu 时调用）
 }
 
 func NewMemoryBackend(config *CacheConfig) (*MemoryBackend, error) {
@@ -118,6 +120,7 @@ func (m *MemoryBackend) cleanupExpired() {
 			m.lru.Remove(entry.elem)
 			m.stats.DecSize()
 			m.stats.RecordEviction()
+			m.notifyRemoved(key)
 		}
 	}
 }
@@ -148,6 +151,31 @@ func (m *MemoryBackend) Get(ctx context.Context, key string) (interface{}, bool,
 	return cacheItem.Value, true, nil
 }
 
+// GetWithTTL 获取缓存值及剩余 TTL
+func (m *MemoryBackend) GetWithTTL(ctx context.Context, key string) (interface{}, bool, time.Duration, error) {
+	val, found, err := m.Get(ctx, key)
+	if err != nil || !found {
+		return nil, false, 0, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, exists := m.data[key]
+	if !exists {
+		return val, true, 0, nil
+	}
+	expiresAt := entry.value.(*CacheItem).ExpiresAt
+	if expiresAt.IsZero() {
+		return val, true, 0, nil
+	}
+	remaining := time.Until(expiresAt)
+	if remaining <= 0 {
+		// 刚好在两次读取之间过期
+		return nil, false, 0, nil
+	}
+	return val, true, remaining, nil
+}
+
 func (m *MemoryBackend) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	normalizedTTL := m.ttlMgr.Normalize(ttl)
 
@@ -190,10 +218,50 @@ func (m *MemoryBackend) Delete(ctx context.Context, key string) error {
 		m.lru.Remove(entry.elem)
 		m.stats.DecSize()
 		m.stats.RecordDelete()
+		m.notifyRemoved(key)
 	}
 	return nil
 }
 
+// DeletePrefix 删除所有以 prefix 开头的条目，返回删除数量
+func (m *MemoryBackend) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return 0, nil
+	}
+	deleted := 0
+	for key, entry := range m.data {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.data, key)
+			m.lru.Remove(entry.elem)
+			m.stats.DecSize()
+			m.stats.RecordDelete()
+			m.notifyRemoved(key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Clear 清空所有缓存条目
+func (m *MemoryBackend) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	if len(m.onRemove) > 0 {
+		for key := range m.data {
+			m.notifyRemoved(key)
+		}
+	}
+	m.data = make(map[string]*cacheEntry, m.config.MaxSize/10+1)
+	m.lru.Init()
+	m.stats.SetSize(0)
+	return nil
+}
+
 func (m *MemoryBackend) Close() error {
 	m.mu.Lock()
 	if m.closed {
@@ -214,7 +282,10 @@ func (m *MemoryBackend) Close() error {
 }
 
 func (m *MemoryBackend) Stats() *CacheStats {
-	m.stats.SetSize(int64(len(m.data)))
+	m.mu.RLock()
+	size := len(m.data)
+	m.mu.RUnlock()
+	m.stats.SetSize(int64(size))
 	return m.stats.Snapshot()
 }
 
@@ -238,11 +309,29 @@ func (m *MemoryBackend) evictLRU() {
 	entry := elem.Value.(*cacheEntry)
 	delete(m.data, entry.key)
 	m.stats.RecordEviction()
+	m.notifyRemoved(entry.key)
+}
+
+// OnRemove 注册条目移除回调（过期、容量淘汰、删除、清空时调用）
+// 回调在持有后端锁时同步执行，不能再访问该后端。
+func (m *MemoryBackend) OnRemove(fn func(key string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onRemove = append(m.onRemove, fn)
+}
+
+// notifyRemoved 通知移除回调（调用方持有 mu）
+func (m *MemoryBackend) notifyRemoved(key string) {
+	for _, fn := range m.onRemove {
+		fn(key)
+	}
 }
 
 func (m *MemoryBackend) evictFIFO() { m.evictLRU() }
 
 var _ CacheBackend = (*MemoryBackend)(nil)
+var _ TTLGetter = (*MemoryBackend)(nil)
+var _ RemovalNotifier = (*MemoryBackend)(nil)
 
 func init() {
 	Register("memory", func(config *CacheConfig) (CacheBackend, error) {
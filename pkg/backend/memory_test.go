@@ -161,6 +161,50 @@ func TestMemoryBackend(t *testing.T) {
 		}
 	})
 
+	t.Run("GetWithTTL", func(t *testing.T) {
+		config := DefaultCacheConfig("test")
+		backend, _ := NewMemoryBackend(config)
+		defer backend.Close()
+
+		backend.Set(ctx, "key1", "value1", 10*time.Second)
+		val, found, remaining, err := backend.GetWithTTL(ctx, "key1")
+		if err != nil || !found || val != "value1" {
+			t.Fatalf("Expected value1, got %v (found=%v, err=%v)", val, found, err)
+		}
+		if remaining <= 0 || remaining > 10*time.Second {
+			t.Errorf("Expected remaining TTL within 10s, got %v", remaining)
+		}
+
+		if _, found, _, _ := backend.GetWithTTL(ctx, "missing"); found {
+			t.Error("Expected miss for missing key")
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		config := DefaultCacheConfig("test")
+		backend, _ := NewMemoryBackend(config)
+		defer backend.Close()
+
+		backend.Set(ctx, "key1", "value1", time.Minute)
+		backend.Set(ctx, "key2", "value2", time.Minute)
+
+		if err := backend.Clear(ctx); err != nil {
+			t.Fatalf("Clear failed: %v", err)
+		}
+		if _, found, _ := backend.Get(ctx, "key1"); found {
+			t.Error("Expected key1 to be cleared")
+		}
+		if size := backend.Stats().Size; size != 0 {
+			t.Errorf("Expected size 0 after clear, got %d", size)
+		}
+
+		// 清空后仍可继续写入
+		backend.Set(ctx, "key3", "value3", time.Minute)
+		if _, found, _ := backend.Get(ctx, "key3"); !found {
+			t.Error("Expected key3 to exist after clear")
+		}
+	})
+
 	t.Run("Close", func(t *testing.T) {
 		config := DefaultCacheConfig("test")
 		backend, _ := NewMemoryBackend(config)
@@ -178,6 +222,34 @@ func TestMemoryBackend(t *testing.T) {
 	})
 }
 
+func TestMemoryBackendOnRemove(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultCacheConfig("test")
+	config.MaxSize = 2
+	backend, _ := NewMemoryBackend(config)
+	defer backend.Close()
+
+	var removed []string
+	backend.OnRemove(func(key string) { removed = append(removed, key) })
+
+	backend.Set(ctx, "a", 1, time.Minute)
+	backend.Set(ctx, "b", 2, time.Minute)
+	backend.Set(ctx, "c", 3, time.Minute) // 容量淘汰 a
+	backend.Delete(ctx, "b")
+	backend.Clear(ctx)
+
+	want := []string{"a", "b", "c"}
+	if len(removed) != len(want) {
+		t.Fatalf("Expected removals %v, got %v", want, removed)
+	}
+	for i := range want {
+		if removed[i] != want[i] {
+			t.Errorf("Expected removals %v, got %v", want, removed)
+			break
+		}
+	}
+}
+
 func TestMemoryBackendValidation(t *testing.T) {
 	t.Run("Empty name", func(t *testing.T) {
 		config := &CacheConfig{
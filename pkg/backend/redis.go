@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"math/rand/v2"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -27,6 +29,15 @@ type RedisConfig struct {
 	DialTimeout  time.Duration // 连接超时
 	ReadTimeout  time.Duration // 读取超时
 	WriteTimeout time.Duration // 写入超时
+
+	StatsSampleInterval time.Duration // 命名空间统计采样间隔（0 表示禁用后台采样）
+	StatsMemorySamples  int           // 每轮 MEMORY USAGE 抽样的 key 数量
+
+	EnableGeneration       bool          // 启用命名空间代数：key 形如 prefix:gen:key，Clear 变为 O(1)
+	GenerationChannel      string        // 代数变更广播频道
+	GenerationSyncInterval time.Duration // 代数轮询兜底间隔（0 表示只依赖 pub/sub）
+
+	WriteBehind *WriteBehindConfig // 异步写回（nil 或未启用时同步写入）
 }
 
 // DefaultRedisConfig 默认 Redis 配置
@@ -44,6 +55,15 @@ func DefaultRedisConfig() *RedisConfig {
 		DialTimeout:  5 * time.Second,
 		ReadTimeout:  3 * time.Second,
 		WriteTimeout: 3 * time.Second,
+
+		StatsSampleInterval: 1 * time.Minute,
+		StatsMemorySamples:  64,
+
+		EnableGeneration:       false,
+		GenerationChannel:      "go-cache:generation",
+		GenerationSyncInterval: 30 * time.Second,
+
+		WriteBehind: DefaultWriteBehindConfig(),
 	}
 }
 
@@ -54,6 +74,9 @@ type RedisBackend struct {
 	stats     *RedisStats
 	ttlMgr    *TTLManager
 	keyBuilder *DefaultKeyBuilder
+	sampler   *namespaceSampler
+	generation *generationManager // 未启用代数时为 nil
+	writeBehind *writeBehindQueue // 未启用异步写回时为 nil
 	closed    int32
 }
 
@@ -64,7 +87,8 @@ type RedisStats struct {
 	sets      int64
 	deletes   int64
 	errors    int64
-	size      int64 // 通过 DBSIZE 估算
+	size      int64 // 前缀下的 key 数量（SCAN 采样）
+	memory    int64 // 前缀下的内存占用估算（MEMORY USAGE 抽样）
 	lastCheck int64
 }
 
@@ -105,23 +129,118 @@ func NewRedisBackend(config *RedisConfig) (*RedisBackend, error) {
 	logger.Info("Redis backend: Successfully connected to Redis at %s (poolSize=%d, minIdleConns=%d)", 
 		config.Addr, config.PoolSize, config.MinIdleConns)
 
-	return &RedisBackend{
+	r := &RedisBackend{
 		client:     client,
 		config:     config,
 		stats:      &RedisStats{},
 		ttlMgr:     NewTTLManager(config.DefaultTTL, config.MaxTTL),
 		keyBuilder: NewDefaultKeyBuilder(":", config.Prefix),
-	}, nil
+	}
+	if config.EnableGeneration {
+		channel := config.GenerationChannel
+		if channel == "" {
+			channel = "go-cache:generation"
+		}
+		r.generation = newGenerationManager(client, config.Prefix, channel)
+		if err := r.generation.load(ctx); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to load namespace generation: %w", err)
+		}
+		if err := r.generation.start(ctx, config.GenerationSyncInterval); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	if config.WriteBehind != nil && config.WriteBehind.Enabled {
+		r.writeBehind = newWriteBehindQueue(config.WriteBehind, r.flushWrites)
+		r.writeBehind.start()
+		r.discardWritesOnGenerationChange()
+	}
+
+	r.sampler = newNamespaceSampler(client, r.scanPattern, config.StatsSampleInterval, config.StatsMemorySamples, r.recordSample)
+	if config.StatsSampleInterval > 0 {
+		r.sampler.start()
+	}
+	return r, nil
+}
+
+// scanPattern 返回当前命名空间的 SCAN 匹配模式（启用代数时只匹配当前代数）
+func (r *RedisBackend) scanPattern() string {
+	return r.buildKey("*")
+}
+
+// recordSample 记录采样结果
+func (r *RedisBackend) recordSample(size, memoryBytes int64) {
+	atomic.StoreInt64(&r.stats.size, size)
+	atomic.StoreInt64(&r.stats.memory, memoryBytes)
+	atomic.StoreInt64(&r.stats.lastCheck, time.Now().UnixNano())
+}
+
+// RefreshStats 立即对命名空间执行一次采样（SCAN 计数 + MEMORY USAGE 抽样）
+// 后台采样被禁用时可用于按需刷新 Size 和 MemoryBytes。
+func (r *RedisBackend) RefreshStats(ctx context.Context) error {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return errors.New("RedisBackend is closed")
+	}
+	return r.sampler.sample(ctx)
+}
+
+// LastSampleTime 返回最近一次采样完成的时间（从未采样时为零值）
+func (r *RedisBackend) LastSampleTime() time.Time {
+	ns := atomic.LoadInt64(&r.stats.lastCheck)
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
 }
 
 // buildKey 构建完整的 Redis key
+// 启用代数时 key 形如 prefix:gen:key
 func (r *RedisBackend) buildKey(key string) string {
+	if r.generation != nil {
+		key = strconv.FormatInt(r.generation.get(), 10) + ":" + key
+	}
 	if r.config.Prefix != "" {
 		return r.config.Prefix + ":" + key
 	}
 	return key
 }
 
+// Generation 返回当前命名空间代数（未启用代数时为 0）
+func (r *RedisBackend) Generation() int64 {
+	if r.generation == nil {
+		return 0
+	}
+	return r.generation.get()
+}
+
+// FlushNamespace 递增命名空间代数，O(1) 使整个缓存失效
+// 旧代数的 key 不再可达，并随 TTL 自然过期；其他实例通过 pub/sub 切换到新代数。
+func (r *RedisBackend) FlushNamespace(ctx context.Context) (int64, error) {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return 0, errors.New("RedisBackend is closed")
+	}
+	if r.generation == nil {
+		return 0, errors.New("namespace generation is not enabled")
+	}
+	gen, err := r.generation.bump(ctx)
+	if err != nil {
+		logger.Error("Redis backend: FlushNamespace failed, prefix=%s, error=%v", r.config.Prefix, err)
+		atomic.AddInt64(&r.stats.errors, 1)
+		return 0, err
+	}
+	return gen, nil
+}
+
+// OnGenerationChange 注册命名空间代数变更回调（本实例或其他实例触发的清空都会通知）
+// 可用于同步清理本地缓存。未启用代数时不会被调用。
+func (r *RedisBackend) OnGenerationChange(fn func(gen int64)) {
+	if r.generation != nil {
+		r.generation.onChange(fn)
+	}
+}
+
 // Get 从 Redis 获取缓存值
 func (r *RedisBackend) Get(ctx context.Context, key string) (interface{}, bool, error) {
 	if atomic.LoadInt32(&r.closed) == 1 {
@@ -129,6 +248,11 @@ func (r *RedisBackend) Get(ctx context.Context, key string) (interface{}, bool,
 		return nil, false, errors.New("RedisBackend is closed")
 	}
 
+	if op, ok := r.pendingWrite(key); ok {
+		result, found, _ := r.decodePending(op)
+		return result, found, nil
+	}
+
 	fullKey := r.buildKey(key)
 	logger.Debug("Redis backend: Getting cache key=%s, fullKey=%s", key, fullKey)
 
@@ -144,11 +268,62 @@ func (r *RedisBackend) Get(ctx context.Context, key string) (interface{}, bool,
 		return nil, false, err
 	}
 
+	result, found := r.decode(key, val)
+	return result, found, nil
+}
+
+// GetWithTTL 获取缓存值及其剩余 TTL（GET 与 PTTL 在同一个 pipeline 中执行）
+// 剩余 TTL 为 0 表示 key 没有过期时间。
+func (r *RedisBackend) GetWithTTL(ctx context.Context, key string) (interface{}, bool, time.Duration, error) {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		logger.Debug("Redis backend: GetWithTTL called on closed backend, key=%s", key)
+		return nil, false, 0, errors.New("RedisBackend is closed")
+	}
+
+	if op, ok := r.pendingWrite(key); ok {
+		result, found, ttl := r.decodePending(op)
+		return result, found, ttl, nil
+	}
+
+	fullKey := r.buildKey(key)
+	logger.Debug("Redis backend: Getting cache key with TTL, key=%s, fullKey=%s", key, fullKey)
+
+	pipe := r.client.Pipeline()
+	getCmd := pipe.Get(ctx, fullKey)
+	ttlCmd := pipe.PTTL(ctx, fullKey)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		logger.Error("Redis backend: GetWithTTL failed, key=%s, error=%v", key, err)
+		atomic.AddInt64(&r.stats.errors, 1)
+		return nil, false, 0, err
+	}
+
+	val, err := getCmd.Bytes()
+	if err != nil {
+		logger.Debug("Redis backend: Cache miss, key=%s", key)
+		atomic.AddInt64(&r.stats.misses, 1)
+		return nil, false, 0, nil
+	}
+
+	result, found := r.decode(key, val)
+	if !found {
+		return nil, false, 0, nil
+	}
+
+	// PTTL 返回 -1 表示没有过期时间，-2 表示 key 已不存在
+	remaining := ttlCmd.Val()
+	if remaining < 0 {
+		remaining = 0
+	}
+	return result, true, remaining, nil
+}
+
+// decode 反序列化 Redis 中的值并记录命中统计
+func (r *RedisBackend) decode(key string, val []byte) (interface{}, bool) {
 	// 检查是否为空值标记（缓存穿透保护）
 	if string(val) == NilMarker {
 		logger.Debug("Redis backend: Cache miss (nil marker), key=%s", key)
 		atomic.AddInt64(&r.stats.misses, 1)
-		return nil, false, nil
+		return nil, false
 	}
 
 	// 反序列化
@@ -161,7 +336,7 @@ func (r *RedisBackend) Get(ctx context.Context, key string) (interface{}, bool,
 
 	logger.Debug("Redis backend: Cache hit, key=%s", key)
 	atomic.AddInt64(&r.stats.hits, 1)
-	return result, true, nil
+	return result, true
 }
 
 // Set 设置缓存值
@@ -188,6 +363,17 @@ func (r *RedisBackend) Set(ctx context.Context, key string, value interface{}, t
 	// 标准化 TTL
 	normalizedTTL := r.ttlMgr.Normalize(ttl)
 
+	if r.writeBehind != nil {
+		err := r.writeBehind.enqueue(ctx, &writeOp{key: key, target: r.buildKey(key), data: data, ttl: normalizedTTL})
+		if err != errWriteBehindFull {
+			if err == nil {
+				atomic.AddInt64(&r.stats.sets, 1)
+			}
+			return err
+		}
+		// 队列已满，同步写入
+	}
+
 	fullKey := r.buildKey(key)
 	if err := r.client.Set(ctx, fullKey, data, normalizedTTL).Err(); err != nil {
 		logger.Error("Redis backend: Set failed, key=%s, error=%v", key, err)
@@ -233,6 +419,16 @@ func (r *RedisBackend) Delete(ctx context.Context, key string) error {
 
 	logger.Debug("Redis backend: Deleting cache key=%s", key)
 
+	if r.writeBehind != nil {
+		err := r.writeBehind.enqueue(ctx, &writeOp{key: key, target: r.buildKey(key), delete: true})
+		if err != errWriteBehindFull {
+			if err == nil {
+				atomic.AddInt64(&r.stats.deletes, 1)
+			}
+			return err
+		}
+	}
+
 	fullKey := r.buildKey(key)
 	if err := r.client.Del(ctx, fullKey).Err(); err != nil {
 		logger.Error("Redis backend: Delete failed, key=%s, error=%v", key, err)
@@ -252,23 +448,23 @@ func (r *RedisBackend) Close() error {
 		return nil // 已经关闭
 	}
 	logger.Info("Redis backend: Closing Redis connection")
+	if r.writeBehind != nil {
+		if err := r.writeBehind.close(); err != nil {
+			logger.Error("Redis backend: failed to flush pending writes on close, pending=%d, error=%v", r.writeBehind.depth(), err)
+		}
+	}
+	if r.config.StatsSampleInterval > 0 {
+		r.sampler.close()
+	}
+	if r.generation != nil {
+		r.generation.close()
+	}
 	return r.client.Close()
 }
 
 // Stats 获取缓存统计信息
+// Size 和 MemoryBytes 来自后台采样器的最近一次结果，只统计当前前缀下的 key。
 func (r *RedisBackend) Stats() *CacheStats {
-	// 异步更新大小信息
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-		
-		size, err := r.client.DBSize(ctx).Result()
-		if err == nil {
-			atomic.StoreInt64(&r.stats.size, size)
-			atomic.StoreInt64(&r.stats.lastCheck, time.Now().UnixNano())
-		}
-	}()
-
 	hits := atomic.LoadInt64(&r.stats.hits)
 	misses := atomic.LoadInt64(&r.stats.misses)
 	total := hits + misses
@@ -278,15 +474,106 @@ func (r *RedisBackend) Stats() *CacheStats {
 	}
 
 	return &CacheStats{
-		Hits:      hits,
-		Misses:    misses,
-		Sets:      atomic.LoadInt64(&r.stats.sets),
-		Deletes:   atomic.LoadInt64(&r.stats.deletes),
-		Evictions: 0, // Redis 自动淘汰，不单独统计
-		Size:      atomic.LoadInt64(&r.stats.size),
-		MaxSize:   0, // Redis 取决于内存配置
-		HitRate:   hitRate,
+		Hits:        hits,
+		Misses:      misses,
+		Sets:        atomic.LoadInt64(&r.stats.sets),
+		Deletes:     atomic.LoadInt64(&r.stats.deletes),
+		Evictions:   0, // Redis 自动淘汰，不单独统计
+		Size:        atomic.LoadInt64(&r.stats.size),
+		MaxSize:     0, // Redis 取决于内存配置
+		HitRate:     hitRate,
+		MemoryBytes: atomic.LoadInt64(&r.stats.memory),
+	}
+}
+
+// Flush 立即写入异步写回队列中的所有操作（未启用异步写回时为空操作）
+func (r *RedisBackend) Flush(ctx context.Context) error {
+	if r.writeBehind == nil {
+		return nil
+	}
+	return r.writeBehind.Flush(ctx)
+}
+
+// WriteBehindStats 异步写回统计（未启用时返回 nil）
+func (r *RedisBackend) WriteBehindStats() *WriteBehindStats {
+	if r.writeBehind == nil {
+		return nil
+	}
+	return r.writeBehind.stats()
+}
+
+// OnWriteBehindFlush 注册异步写入落盘后的回调（参数为未加前缀的 key）
+// 未启用异步写回时返回 false，调用方应在同步写入后自行处理。
+func (r *RedisBackend) OnWriteBehindFlush(fn func(keys []string)) bool {
+	if r.writeBehind == nil {
+		return false
+	}
+	r.writeBehind.onFlush(func(ops []*writeOp) {
+		keys := make([]string, len(ops))
+		for i, op := range ops {
+			keys[i] = op.key
+		}
+		fn(keys)
+	})
+	return true
+}
+
+// pendingWrite 查找尚未写入 Redis 的操作（保证本实例读到自己的写入）
+func (r *RedisBackend) pendingWrite(key string) (writeOp, bool) {
+	if r.writeBehind == nil {
+		return writeOp{}, false
+	}
+	op, ok := r.writeBehind.lookup(key)
+	if ok && op.target != r.buildKey(key) {
+		// 与代数变更并发入队的旧代数写入
+		return writeOp{}, false
+	}
+	return op, ok
+}
+
+// decodePending 解码待写入的操作，TTL 为入队时的 TTL
+func (r *RedisBackend) decodePending(op writeOp) (interface{}, bool, time.Duration) {
+	if op.delete {
+		atomic.AddInt64(&r.stats.misses, 1)
+		return nil, false, 0
+	}
+	result, found := r.decode(op.key, op.data)
+	return result, found, op.ttl
+}
+
+// discardWritesOnGenerationChange 代数变更（本实例 FlushNamespace 或其他实例清空）时丢弃排队中的写入
+// 这些写入属于旧代数，既不应再被读到，也不应写回。
+func (r *RedisBackend) discardWritesOnGenerationChange() {
+	if r.generation == nil || r.writeBehind == nil {
+		return
+	}
+	r.generation.onChange(func(gen int64) {
+		if n := r.writeBehind.discard(); n > 0 {
+			logger.Debug("Redis backend: discarded %d write-behind operations on generation %d", n, gen)
+		}
+	})
+}
+
+// flushWrites 用一个 pipeline 写入一批操作（写入入队时的代数）
+func (r *RedisBackend) flushWrites(ctx context.Context, ops []*writeOp) error {
+	pipe := r.client.Pipeline()
+	for _, op := range ops {
+		fullKey := op.target
+		if fullKey == "" {
+			fullKey = r.buildKey(op.key)
+		}
+		if op.delete {
+			pipe.Del(ctx, fullKey)
+		} else {
+			pipe.Set(ctx, fullKey, op.data, op.ttl)
+		}
 	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		atomic.AddInt64(&r.stats.errors, 1)
+		return err
+	}
+	logger.Debug("Redis backend: write-behind flushed %d operations", len(ops))
+	return nil
 }
 
 // Client 获取底层 Redis 客户端（用于高级操作）
@@ -299,8 +586,59 @@ func (r *RedisBackend) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
 
+// DeletePrefix 删除当前命名空间中以 prefix 开头的 key（SCAN + DEL），返回删除数量
+func (r *RedisBackend) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return 0, errors.New("RedisBackend is closed")
+	}
+	if r.writeBehind != nil {
+		// 先落盘排队中的写入，避免删除后又被写回
+		if err := r.Flush(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	deleted := 0
+	iter := r.client.Scan(ctx, 0, escapeScanPattern(r.buildKey(prefix))+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		n, err := r.client.Del(ctx, iter.Val()).Result()
+		if err != nil {
+			logger.Error("Redis backend: DeletePrefix failed, prefix=%s, error=%v", prefix, err)
+			atomic.AddInt64(&r.stats.errors, 1)
+			return deleted, err
+		}
+		deleted += int(n)
+	}
+	atomic.AddInt64(&r.stats.deletes, int64(deleted))
+	return deleted, iter.Err()
+}
+
+// escapeScanPattern 转义 SCAN MATCH 中的通配字符
+func escapeScanPattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
 // Clear 清空所有缓存（危险操作）
+// 启用代数时等价于 FlushNamespace，不会逐个删除 key；启用异步写回时排队中的写入被丢弃。
 func (r *RedisBackend) Clear(ctx context.Context) error {
+	if r.writeBehind != nil {
+		// 先丢弃排队中的写入，否则清空后仍会被读到并写回
+		if n := r.writeBehind.discard(); n > 0 {
+			logger.Debug("Redis backend: discarded %d write-behind operations on clear", n)
+		}
+	}
+	if r.generation != nil {
+		_, err := r.FlushNamespace(ctx)
+		return err
+	}
 	if r.config.Prefix != "" {
 		// 有前缀时只删除带前缀的 key
 		pattern := r.config.Prefix + ":*"
@@ -317,8 +655,12 @@ func (r *RedisBackend) Clear(ctx context.Context) error {
 	return r.client.FlushDB(ctx).Err()
 }
 
-// 确保实现 CacheBackend 接口
+// 确保实现 CacheBackend 和 TTLGetter 接口
 var _ CacheBackend = (*RedisBackend)(nil)
+var _ TTLGetter = (*RedisBackend)(nil)
+var _ WriteBehindBackend = (*RedisBackend)(nil)
+var _ HealthChecker = (*RedisBackend)(nil)
+var _ PrefixDeleter = (*RedisBackend)(nil)
 
 // init 注册 Redis 后端
 func init() {
@@ -326,6 +668,33 @@ func init() {
 		redisConfig := DefaultRedisConfig()
 		redisConfig.DefaultTTL = config.DefaultTTL
 		redisConfig.MaxTTL = config.MaxTTL
+		if err := applyRedisOptions(redisConfig, config); err != nil {
+			return nil, err
+		}
 		return NewRedisBackend(redisConfig)
 	})
 }
+
+// applyRedisOptions 应用 CacheConfig.Options 中的 Redis 选项
+//
+// 支持 addr、password、db、pool_size、prefix；prefix 只在指定时覆盖，未指定时保留 RedisConfig.Prefix（key 布局不变）。
+func applyRedisOptions(rc *RedisConfig, config *CacheConfig) error {
+	rc.Addr = config.Option("addr", rc.Addr)
+	rc.Password = config.Option("password", rc.Password)
+	rc.Prefix = config.Option("prefix", rc.Prefix)
+	if v := config.Option("db", ""); v != "" {
+		db, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid redis option db=%q: %w", v, err)
+		}
+		rc.DB = db
+	}
+	if v := config.Option("pool_size", ""); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid redis option pool_size=%q: %w", v, err)
+		}
+		rc.PoolSize = size
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package backend
 
 import (
 	"context"
+	"encoding/gob"
 	"errors"
 	"fmt"
 	"math/rand/v2"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -12,6 +14,12 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// gob 解码到 interface{} 时按注册名还原动态类型，JSON 风格的复合值需要预先注册
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
 // RedisClusterConfig Redis Cluster 配置
 type RedisClusterConfig struct {
 	Addrs         []string      // Cluster 节点地址列表
@@ -25,8 +33,20 @@ type RedisClusterConfig struct {
 	Prefix        string        // Key 前缀
 	DefaultTTL    time.Duration // 默认 TTL
 	MaxTTL        time.Duration // 最大 TTL
-	RouteByPrefix bool          // 是否按前缀路由（高级特性）
+	RouteByPrefix bool          // 是否按前缀路由：未指定 HashTagStrategy 时等价于 HashTagCacheName
 	Serializer    string        // 序列化器类型：json, gob, msgpack
+
+	HashTagStrategy    HashTagStrategy // hash tag 策略：""（不使用）, cache, entity, expr
+	HashTagExpr        string          // expr 策略的 SpEL 表达式，如 "parts[1]"
+	HashTagEntityDepth int             // entity 策略取 key 的前 N 段（默认 2）
+}
+
+// hashTagStrategy 返回生效的 hash tag 策略
+func (c *RedisClusterConfig) hashTagStrategy() HashTagStrategy {
+	if c.HashTagStrategy == HashTagNone && c.RouteByPrefix {
+		return HashTagCacheName
+	}
+	return c.HashTagStrategy
 }
 
 // DefaultRedisClusterConfig 默认 Cluster 配置
@@ -54,7 +74,7 @@ type RedisClusterBackend struct {
 	config     *RedisClusterConfig
 	stats      *RedisClusterStats
 	ttlMgr     *TTLManager
-	keyBuilder *DefaultKeyBuilder
+	keyBuilder *HashTagKeyBuilder
 	serializer serializer.Serializer
 	closed     int32
 }
@@ -116,22 +136,29 @@ func NewRedisClusterBackend(config *RedisClusterConfig) (*RedisClusterBackend, e
 		return nil, fmt.Errorf("failed to get serializer: %w", err)
 	}
 
+	keyBuilder := NewHashTagKeyBuilder(":", config.Prefix, config.hashTagStrategy(), config.HashTagExpr)
+	if config.HashTagEntityDepth > 0 {
+		keyBuilder.EntityDepth = config.HashTagEntityDepth
+	}
+
 	return &RedisClusterBackend{
 		client:     client,
 		config:     config,
 		stats:      &RedisClusterStats{},
 		ttlMgr:     NewTTLManager(config.DefaultTTL, config.MaxTTL),
-		keyBuilder: NewDefaultKeyBuilder(":", config.Prefix),
+		keyBuilder: keyBuilder,
 		serializer: ser,
 	}, nil
 }
 
-// buildKey 构建完整的 Redis key
+// buildKey 构建完整的 Redis key（包含前缀和 hash tag）
 func (r *RedisClusterBackend) buildKey(key string) string {
-	if r.config.Prefix != "" {
-		return r.config.Prefix + ":" + key
-	}
-	return key
+	return r.keyBuilder.BuildKey(key)
+}
+
+// Slot 返回业务 key 所在的集群槽位
+func (r *RedisClusterBackend) Slot(key string) int {
+	return HashSlot(r.buildKey(key))
 }
 
 // Get 从 Redis Cluster 获取缓存值
@@ -168,6 +195,12 @@ func (r *RedisClusterBackend) Get(ctx context.Context, key string) (interface{},
 	return result, true, nil
 }
 
+// marshal 序列化缓存值
+// 传入 interface{} 的指针，gob 才会记录动态类型，Get 解码到 interface{} 时才能还原。
+func (r *RedisClusterBackend) marshal(value interface{}) ([]byte, error) {
+	return r.serializer.Marshal(&value)
+}
+
 // Set 设置缓存值
 func (r *RedisClusterBackend) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	if atomic.LoadInt32(&r.closed) == 1 {
@@ -178,7 +211,7 @@ func (r *RedisClusterBackend) Set(ctx context.Context, key string, value interfa
 		value = NilMarker
 	}
 
-	data, err := r.serializer.Marshal(value)
+	data, err := r.marshal(value)
 	if err != nil {
 		return fmt.Errorf("failed to marshal value: %w", err)
 	}
@@ -295,16 +328,22 @@ func (r *RedisClusterBackend) ClusterNodes(ctx context.Context) (string, error)
 }
 
 // Clear 清空缓存（危险操作）
+// SCAN 只作用于单个节点，因此需要遍历所有主节点。
 func (r *RedisClusterBackend) Clear(ctx context.Context) error {
 	if r.config.Prefix != "" {
 		pattern := r.config.Prefix + ":*"
-		iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
-		for iter.Next(ctx) {
-			if err := r.client.Del(ctx, iter.Val()).Err(); err != nil {
-				return err
-			}
+		if r.keyBuilder.Strategy == HashTagCacheName {
+			pattern = "{" + r.config.Prefix + "}:*"
 		}
-		return iter.Err()
+		return r.client.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			iter := node.Scan(ctx, 0, pattern, 100).Iterator()
+			for iter.Next(ctx) {
+				if err := node.Del(ctx, iter.Val()).Err(); err != nil {
+					return err
+				}
+			}
+			return iter.Err()
+		})
 	}
 
 	// Cluster 模式下不支持 FLUSHDB，需要遍历所有槽位
@@ -313,6 +352,7 @@ func (r *RedisClusterBackend) Clear(ctx context.Context) error {
 
 // 确保实现 CacheBackend 接口
 var _ CacheBackend = (*RedisClusterBackend)(nil)
+var _ HealthChecker = (*RedisClusterBackend)(nil)
 
 // init 注册 Redis Cluster 后端
 func init() {
@@ -320,6 +360,12 @@ func init() {
 		clusterConfig := DefaultRedisClusterConfig()
 		clusterConfig.DefaultTTL = config.DefaultTTL
 		clusterConfig.MaxTTL = config.MaxTTL
+		// addrs 为逗号分隔的节点地址
+		if addrs := config.Option("addrs", ""); addrs != "" {
+			clusterConfig.Addrs = strings.Split(addrs, ",")
+		}
+		clusterConfig.Password = config.Option("password", clusterConfig.Password)
+		clusterConfig.Prefix = config.Option("prefix", clusterConfig.Prefix)
 		return NewRedisClusterBackend(clusterConfig)
 	})
 }
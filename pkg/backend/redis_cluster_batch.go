@@ -0,0 +1,218 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCrossSlot 多 key 操作中的 key 不在同一个槽位
+var ErrCrossSlot = &BackendError{Code: "CROSS_SLOT", Message: "keys in request don't hash to the same slot"}
+
+// tagSetSegment 标签集合 key 的固定段
+const tagSetSegment = "__tag__"
+
+// invalidateTagScript 原子删除标签集合及其全部成员（要求所有 key 位于同一槽位）
+var invalidateTagScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+for _, k in ipairs(members) do
+	redis.call('DEL', k)
+end
+redis.call('DEL', KEYS[1])
+return #members
+`)
+
+// tagSetAddScript 加入标签集合，并把集合的过期时间延长到不短于新成员的 TTL
+// ARGV[2] 为成员 TTL（毫秒），0 表示成员不过期，此时集合也不过期。
+var tagSetAddScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 0
+end
+local current = redis.call('PTTL', KEYS[1])
+if existed == 0 or (current >= 0 and current < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// groupBySlot 将完整 key 按槽位分组
+func groupBySlot(fullKeys []string) map[int][]string {
+	groups := make(map[int][]string)
+	for _, k := range fullKeys {
+		slot := HashSlot(k)
+		groups[slot] = append(groups[slot], k)
+	}
+	return groups
+}
+
+// MGet 批量获取缓存值
+// key 先按槽位分组，每组一次 MGET；配合 hash tag 策略，相关 key 只需一次往返。
+// 返回结果只包含命中的 key。
+func (r *RedisClusterBackend) MGet(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return nil, errors.New("RedisClusterBackend is closed")
+	}
+
+	fullToKey := make(map[string]string, len(keys))
+	fullKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		full := r.buildKey(key)
+		if _, dup := fullToKey[full]; dup {
+			continue
+		}
+		fullToKey[full] = key
+		fullKeys = append(fullKeys, full)
+	}
+
+	groups := groupBySlot(fullKeys)
+	pipe := r.client.Pipeline()
+	cmds := make(map[*redis.SliceCmd][]string, len(groups))
+	for _, group := range groups {
+		cmds[pipe.MGet(ctx, group...)] = group
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		atomic.AddInt64(&r.stats.errors, 1)
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(keys))
+	for cmd, group := range cmds {
+		for i, raw := range cmd.Val() {
+			key := fullToKey[group[i]]
+			s, ok := raw.(string)
+			if !ok || s == NilMarker {
+				atomic.AddInt64(&r.stats.misses, 1)
+				continue
+			}
+
+			var value interface{}
+			if err := r.serializer.Unmarshal([]byte(s), &value); err != nil {
+				value = s
+			}
+			result[key] = value
+			atomic.AddInt64(&r.stats.hits, 1)
+		}
+	}
+	return result, nil
+}
+
+// MSet 批量设置缓存值（Pipeline，集群客户端按槽位路由）
+func (r *RedisClusterBackend) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return errors.New("RedisClusterBackend is closed")
+	}
+
+	normalizedTTL := r.ttlMgr.Normalize(ttl)
+	pipe := r.client.Pipeline()
+	for key, value := range items {
+		if value == nil {
+			value = NilMarker
+		}
+		data, err := r.marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value for key %s: %w", key, err)
+		}
+		pipe.Set(ctx, r.buildKey(key), data, normalizedTTL)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		atomic.AddInt64(&r.stats.errors, 1)
+		return err
+	}
+	atomic.AddInt64(&r.stats.sets, int64(len(items)))
+	return nil
+}
+
+// Eval 执行 Lua 脚本
+// keys 为业务 key，会自动加上前缀和 hash tag；发送前校验是否位于同一槽位，
+// 跨槽位时返回 ErrCrossSlot 而不是等待服务端的 CROSSSLOT 错误。
+func (r *RedisClusterBackend) Eval(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return nil, errors.New("RedisClusterBackend is closed")
+	}
+
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = r.buildKey(key)
+	}
+	if len(groupBySlot(fullKeys)) > 1 {
+		return nil, ErrCrossSlot
+	}
+
+	return script.Run(ctx, r.client, fullKeys, args...).Result()
+}
+
+// tagSetKey 构建标签集合的完整 key（与缓存 key 使用相同的 hash tag 策略）
+func (r *RedisClusterBackend) tagSetKey(tag string) string {
+	return r.buildKey(tagSetSegment + ":" + tag)
+}
+
+// SetWithTags 设置缓存值并将其加入标签集合，用于按标签批量失效
+func (r *RedisClusterBackend) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if err := r.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	fullKey := r.buildKey(key)
+	// 标签集合的生命周期不短于其中最长的成员，成员全部过期后集合随之过期
+	ttlMillis := r.ttlMgr.Normalize(ttl).Milliseconds()
+	pipe := r.client.Pipeline()
+	for _, tag := range tags {
+		tagSetAddScript.Eval(ctx, pipe, []string{r.tagSetKey(tag)}, fullKey, ttlMillis)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		atomic.AddInt64(&r.stats.errors, 1)
+		return err
+	}
+	return nil
+}
+
+// InvalidateTag 删除标签下的所有缓存，返回删除的成员数
+// 标签集合与成员位于同一槽位时（如 HashTagCacheName 策略）通过 Lua 脚本原子执行，
+// 否则按槽位分组批量删除。
+func (r *RedisClusterBackend) InvalidateTag(ctx context.Context, tag string) (int64, error) {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return 0, errors.New("RedisClusterBackend is closed")
+	}
+
+	tagKey := r.tagSetKey(tag)
+	if r.keyBuilder.Strategy == HashTagCacheName && r.config.Prefix != "" {
+		n, err := invalidateTagScript.Run(ctx, r.client, []string{tagKey}).Int64()
+		if err != nil {
+			atomic.AddInt64(&r.stats.errors, 1)
+			return 0, err
+		}
+		atomic.AddInt64(&r.stats.deletes, n)
+		return n, nil
+	}
+
+	members, err := r.client.SMembers(ctx, tagKey).Result()
+	if err != nil {
+		atomic.AddInt64(&r.stats.errors, 1)
+		return 0, err
+	}
+
+	pipe := r.client.Pipeline()
+	for _, group := range groupBySlot(members) {
+		pipe.Del(ctx, group...)
+	}
+	pipe.Del(ctx, tagKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		atomic.AddInt64(&r.stats.errors, 1)
+		return 0, err
+	}
+
+	atomic.AddInt64(&r.stats.deletes, int64(len(members)))
+	return int64(len(members)), nil
+}
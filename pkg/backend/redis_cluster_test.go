@@ -2,8 +2,11 @@ package backend
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/coderiser/go-cache/pkg/serializer"
 )
 
 func TestRedisClusterBackend_Basic(t *testing.T) {
@@ -262,3 +265,101 @@ func TestDefaultRedisClusterConfig(t *testing.T) {
 		t.Errorf("Expected pool size 10, got %d", config.PoolSize)
 	}
 }
+
+// TestRedisClusterBackend_MarshalRoundTrip 测试各序列化器写入的值都能解码回 interface{}
+func TestRedisClusterBackend_MarshalRoundTrip(t *testing.T) {
+	values := []interface{}{"v", map[string]interface{}{"name": "alice"}, []interface{}{"a", "b"}}
+	for _, name := range []string{"json", "gob", "msgpack"} {
+		ser, err := serializer.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &RedisClusterBackend{serializer: ser}
+		for _, value := range values {
+			data, err := r.marshal(value)
+			if err != nil {
+				t.Fatalf("%s: marshal %v: %v", name, value, err)
+			}
+			var decoded interface{}
+			if err := ser.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("%s: unmarshal %v: %v", name, value, err)
+			}
+			if !reflect.DeepEqual(decoded, value) {
+				t.Errorf("%s: expected %#v, got %#v", name, value, decoded)
+			}
+		}
+	}
+}
+
+func TestRedisClusterBackend_HashTagMultiKey(t *testing.T) {
+	t.Skip("Skipping test that requires Redis Cluster")
+
+	config := DefaultRedisClusterConfig()
+	config.Prefix = "products"
+	config.HashTagStrategy = HashTagCacheName
+
+	backend, err := NewRedisClusterBackend(config)
+	if err != nil {
+		t.Fatalf("Failed to create cluster backend: %v", err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+
+	err = backend.MSet(ctx, map[string]interface{}{"1": "a", "2": "b"}, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("MSet failed: %v", err)
+	}
+
+	values, err := backend.MGet(ctx, []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatalf("MGet failed: %v", err)
+	}
+	if len(values) != 2 || values["1"] != "a" || values["2"] != "b" {
+		t.Errorf("Unexpected MGet result: %v", values)
+	}
+
+	if err := backend.SetWithTags(ctx, "3", "c", 5*time.Minute, "featured"); err != nil {
+		t.Fatalf("SetWithTags failed: %v", err)
+	}
+	n, err := backend.InvalidateTag(ctx, "featured")
+	if err != nil {
+		t.Fatalf("InvalidateTag failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 invalidated key, got %d", n)
+	}
+	if _, found, _ := backend.Get(ctx, "3"); found {
+		t.Error("Tagged key should be invalidated")
+	}
+}
+
+func TestRedisClusterBackend_TagSetExpiry(t *testing.T) {
+	t.Skip("Skipping test that requires Redis Cluster")
+
+	config := DefaultRedisClusterConfig()
+	config.Prefix = "products"
+	config.HashTagStrategy = HashTagCacheName
+	config.MaxTTL = 0
+
+	backend, err := NewRedisClusterBackend(config)
+	if err != nil {
+		t.Fatalf("Failed to create cluster backend: %v", err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+	tagKey := backend.tagSetKey("expiring")
+	backend.SetWithTags(ctx, "1", "a", time.Minute, "expiring")
+	backend.SetWithTags(ctx, "2", "b", 10*time.Second, "expiring")
+
+	// MaxTTL 为 0 时标签集合仍按最长成员的 TTL 过期
+	ttl, err := backend.client.PTTL(ctx, tagKey).Result()
+	if err != nil {
+		t.Fatalf("PTTL failed: %v", err)
+	}
+	if ttl < 50*time.Second || ttl > time.Minute {
+		t.Errorf("Expected tag set TTL close to 1m, got %v", ttl)
+	}
+	backend.InvalidateTag(ctx, "expiring")
+}
@@ -0,0 +1,195 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// generationSegment 命名空间代数计数器 key 的固定段
+const generationSegment = "__gen__"
+
+// generationManager 命名空间代数管理器
+// 代数计数器保存在 Redis 中并拼入每个 key（prefix:gen:key）。
+// 递增代数即可 O(1) 使整个命名空间失效，旧代数的 key 依靠 TTL 自然过期；
+// 其他实例通过 pub/sub（以及定期轮询兜底）感知新代数。
+type generationManager struct {
+	client     *redis.Client
+	namespace  string
+	counterKey string
+	channel    string
+	current    int64
+
+	mu        sync.RWMutex
+	listeners []func(gen int64)
+
+	pubsub   *redis.PubSub
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// newGenerationManager 创建代数管理器
+func newGenerationManager(client *redis.Client, namespace, channel string) *generationManager {
+	counterKey := generationSegment
+	if namespace != "" {
+		counterKey = namespace + ":" + generationSegment
+	}
+	return &generationManager{
+		client:     client,
+		namespace:  namespace,
+		counterKey: counterKey,
+		channel:    channel,
+		stop:       make(chan struct{}),
+	}
+}
+
+// load 从 Redis 读取当前代数（计数器不存在时为 0）
+func (g *generationManager) load(ctx context.Context) error {
+	gen, err := g.client.Get(ctx, g.counterKey).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}
+	g.observe(gen)
+	return nil
+}
+
+// start 订阅代数变更并启动轮询兜底
+func (g *generationManager) start(ctx context.Context, syncInterval time.Duration) error {
+	g.pubsub = g.client.Subscribe(ctx, g.channel)
+	if _, err := g.pubsub.Receive(ctx); err != nil {
+		g.pubsub.Close()
+		return fmt.Errorf("failed to subscribe to generation channel: %w", err)
+	}
+
+	g.wg.Add(1)
+	go g.listen()
+
+	if syncInterval > 0 {
+		g.wg.Add(1)
+		go g.poll(syncInterval)
+	}
+	return nil
+}
+
+func (g *generationManager) listen() {
+	defer g.wg.Done()
+	for msg := range g.pubsub.Channel() {
+		namespace, gen, err := parseGenerationMessage(msg.Payload)
+		if err != nil {
+			logger.Warn("Redis backend: invalid generation message %q: %v", msg.Payload, err)
+			continue
+		}
+		if namespace == g.namespace {
+			g.observe(gen)
+		}
+	}
+}
+
+// poll 定期重新读取计数器，弥补断线期间丢失的 pub/sub 消息
+func (g *generationManager) poll(interval time.Duration) {
+	defer g.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := g.load(ctx); err != nil {
+				logger.Warn("Redis backend: failed to sync generation for namespace %s: %v", g.namespace, err)
+			}
+			cancel()
+		case <-g.stop:
+			return
+		}
+	}
+}
+
+// get 当前代数
+func (g *generationManager) get() int64 {
+	return atomic.LoadInt64(&g.current)
+}
+
+// observe 记录新代数（只前进不后退），变化时通知监听者
+func (g *generationManager) observe(gen int64) {
+	for {
+		cur := atomic.LoadInt64(&g.current)
+		if gen <= cur {
+			return
+		}
+		if atomic.CompareAndSwapInt64(&g.current, cur, gen) {
+			break
+		}
+	}
+
+	logger.Info("Redis backend: namespace %s moved to generation %d", g.namespace, gen)
+	g.mu.RLock()
+	listeners := make([]func(int64), len(g.listeners))
+	copy(listeners, g.listeners)
+	g.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(gen)
+	}
+}
+
+// bump 递增代数并广播
+func (g *generationManager) bump(ctx context.Context) (int64, error) {
+	gen, err := g.client.Incr(ctx, g.counterKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	g.observe(gen)
+
+	if err := g.client.Publish(ctx, g.channel, formatGenerationMessage(g.namespace, gen)).Err(); err != nil {
+		// 广播失败不影响本实例，其他实例依靠轮询兜底
+		logger.Warn("Redis backend: failed to publish generation %d for namespace %s: %v", gen, g.namespace, err)
+	}
+	return gen, nil
+}
+
+// onChange 注册代数变更回调
+func (g *generationManager) onChange(fn func(gen int64)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.listeners = append(g.listeners, fn)
+}
+
+// close 停止订阅和轮询
+func (g *generationManager) close() {
+	g.stopOnce.Do(func() {
+		close(g.stop)
+		if g.pubsub != nil {
+			g.pubsub.Close()
+		}
+	})
+	g.wg.Wait()
+}
+
+// formatGenerationMessage 代数变更消息格式：namespace:gen
+func formatGenerationMessage(namespace string, gen int64) string {
+	return namespace + ":" + strconv.FormatInt(gen, 10)
+}
+
+// parseGenerationMessage 解析代数变更消息（命名空间本身可能包含冒号，按最后一个冒号切分）
+func parseGenerationMessage(payload string) (string, int64, error) {
+	i := strings.LastIndexByte(payload, ':')
+	if i < 0 {
+		return "", 0, errors.New("missing generation separator")
+	}
+	gen, err := strconv.ParseInt(payload[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return payload[:i], gen, nil
+}
@@ -0,0 +1,141 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGenerationMessage(t *testing.T) {
+	payload := formatGenerationMessage("app:users", 42)
+	if payload != "app:users:42" {
+		t.Errorf("Expected app:users:42, got %s", payload)
+	}
+
+	namespace, gen, err := parseGenerationMessage(payload)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if namespace != "app:users" || gen != 42 {
+		t.Errorf("Expected (app:users, 42), got (%s, %d)", namespace, gen)
+	}
+
+	if _, _, err := parseGenerationMessage("no-separator"); err == nil {
+		t.Error("Expected error for payload without separator")
+	}
+	if _, _, err := parseGenerationMessage("users:abc"); err == nil {
+		t.Error("Expected error for non-numeric generation")
+	}
+}
+
+func TestGenerationManager_ObserveOnlyForward(t *testing.T) {
+	g := newGenerationManager(nil, "users", "go-cache:generation")
+
+	var notified []int64
+	g.onChange(func(gen int64) { notified = append(notified, gen) })
+
+	g.observe(3)
+	g.observe(2) // 旧代数被忽略
+	g.observe(3) // 相同代数不重复通知
+	g.observe(5)
+
+	if g.get() != 5 {
+		t.Errorf("Expected generation 5, got %d", g.get())
+	}
+	if len(notified) != 2 || notified[0] != 3 || notified[1] != 5 {
+		t.Errorf("Expected notifications [3 5], got %v", notified)
+	}
+}
+
+func TestRedisBackendFlushNamespace(t *testing.T) {
+	config := DefaultRedisConfig()
+	config.Prefix = "gen-test"
+	config.EnableGeneration = true
+	config.StatsSampleInterval = 0
+
+	b1, err := NewRedisBackend(config)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer b1.Close()
+
+	b2, err := NewRedisBackend(config)
+	if err != nil {
+		t.Fatalf("Failed to create second backend: %v", err)
+	}
+	defer b2.Close()
+
+	ctx := context.Background()
+	if err := b1.Set(ctx, "key1", "value1", time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, found, _ := b2.Get(ctx, "key1"); !found {
+		t.Fatal("Expected key1 to be visible from second instance")
+	}
+
+	gen, err := b1.FlushNamespace(ctx)
+	if err != nil {
+		t.Fatalf("FlushNamespace failed: %v", err)
+	}
+	if _, found, _ := b1.Get(ctx, "key1"); found {
+		t.Error("Expected key1 to be invalidated after flush")
+	}
+
+	// 第二个实例通过 pub/sub 切换到新代数
+	deadline := time.Now().Add(2 * time.Second)
+	for b2.Generation() != gen && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if b2.Generation() != gen {
+		t.Fatalf("Expected second instance at generation %d, got %d", gen, b2.Generation())
+	}
+	if _, found, _ := b2.Get(ctx, "key1"); found {
+		t.Error("Expected key1 to be invalidated on second instance")
+	}
+}
+
+func TestRedisBackend_GenerationChangeDiscardsWrites(t *testing.T) {
+	q, f := newTestQueue(&WriteBehindConfig{})
+	r := &RedisBackend{generation: newGenerationManager(nil, "users", "go-cache:generation"), writeBehind: q}
+	r.discardWritesOnGenerationChange()
+
+	ctx := context.Background()
+	q.enqueue(ctx, &writeOp{key: "a", target: "users:0:a", data: []byte(`1`)})
+	r.generation.observe(1)
+
+	if _, ok := r.pendingWrite("a"); ok {
+		t.Error("Expected pending write of old generation to be discarded")
+	}
+	q.Flush(ctx)
+	if written := f.written(); len(written) != 0 {
+		t.Errorf("Expected no writes after generation change, got %v", written)
+	}
+}
+
+func TestRedisBackendFlushNamespaceWriteBehind(t *testing.T) {
+	config := DefaultRedisConfig()
+	config.Prefix = "gen-wb-test"
+	config.EnableGeneration = true
+	config.StatsSampleInterval = 0
+	config.WriteBehind.Enabled = true
+	config.WriteBehind.FlushInterval = time.Hour
+
+	b, err := NewRedisBackend(config)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer b.Close()
+
+	ctx := context.Background()
+	b.Set(ctx, "key1", "value1", time.Minute)
+	if _, err := b.FlushNamespace(ctx); err != nil {
+		t.Fatalf("FlushNamespace failed: %v", err)
+	}
+	if _, found, _ := b.Get(ctx, "key1"); found {
+		t.Error("Expected queued write to be invisible after FlushNamespace")
+	}
+	b.Flush(ctx)
+	if _, found, _ := b.Get(ctx, "key1"); found {
+		t.Error("Expected queued write not to reappear in the new generation")
+	}
+}
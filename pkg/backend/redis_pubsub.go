@@ -2,22 +2,90 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/coderiser/go-cache/pkg/logger"
 	"github.com/redis/go-redis/v9"
 )
 
 // CacheInvalidator 缓存失效广播器
+// 订阅断开后按指数退避重新订阅，重连成功后对恢复目标执行恢复动作（断开期间的失效消息可能已丢失）。
 type CacheInvalidator struct {
 	client   *redis.Client
 	pubsub   *redis.PubSub
 	channel  string
+	origin   string
 	closed   int32
+	done     chan struct{}
 	mu       sync.RWMutex
 	handlers []func(key string)
+
+	reconnectMu     sync.Mutex // 同一时间只有一个协程负责重新订阅
+	reconnect       reconnectPolicy
+	connected       int32
+	disconnects     int64
+	onDisconnect    []func(err error)
+	onReconnect     []func(downtime time.Duration)
+	recoveryTargets []CacheBackend
+}
+
+// reconnectPolicy 重新订阅与恢复策略
+type reconnectPolicy struct {
+	backoff          time.Duration
+	maxBackoff       time.Duration
+	healthCheck      time.Duration
+	recovery         RecoveryAction
+	recoveryPrefixes []string
+}
+
+// RecoveryAction 失效订阅重连后的恢复动作
+type RecoveryAction int
+
+const (
+	RecoveryFlushAll    RecoveryAction = iota // 清空整个本地缓存（默认）
+	RecoveryFlushPrefix                       // 只清理指定前缀的 key
+	RecoveryNone                              // 不处理（能容忍断开期间的旧数据）
+)
+
+// String 返回恢复动作名称
+func (a RecoveryAction) String() string {
+	switch a {
+	case RecoveryFlushAll:
+		return "flush-all"
+	case RecoveryFlushPrefix:
+		return "flush-prefix"
+	case RecoveryNone:
+		return "none"
+	}
+	return fmt.Sprintf("RecoveryAction(%d)", int(a))
+}
+
+// Apply 对本地缓存执行恢复动作
+// 后端不支持按前缀删除时退化为清空；不支持清空时返回错误。
+func (a RecoveryAction) Apply(ctx context.Context, target CacheBackend, prefixes ...string) error {
+	switch a {
+	case RecoveryNone:
+		return nil
+	case RecoveryFlushPrefix:
+		if pd, ok := target.(PrefixDeleter); ok {
+			for _, prefix := range prefixes {
+				if _, err := pd.DeletePrefix(ctx, prefix); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	c, ok := target.(Clearer)
+	if !ok {
+		return fmt.Errorf("recovery %s: backend %T cannot be cleared", a, target)
+	}
+	return c.Clear(ctx)
 }
 
 // CacheInvalidatorConfig 缓存失效广播器配置
@@ -28,6 +96,13 @@ type CacheInvalidatorConfig struct {
 	Channel     string        // Pub/Sub 频道名称
 	DialTimeout time.Duration // 连接超时
 	ReadTimeout time.Duration // 读取超时
+	InstanceID  string        // 本实例 ID（为空时自动生成），写入消息的 origin 字段
+
+	ReconnectBackoff    time.Duration  // 重新订阅的初始退避时间
+	ReconnectMaxBackoff time.Duration  // 重新订阅的最大退避时间
+	HealthCheckInterval time.Duration  // 空闲时发送 PING 检测连接的间隔
+	Recovery            RecoveryAction // 重连后对恢复目标执行的动作
+	RecoveryPrefixes    []string       // Recovery 为 RecoveryFlushPrefix 时清理的前缀
 }
 
 // DefaultCacheInvalidatorConfig 默认配置
@@ -39,6 +114,11 @@ func DefaultCacheInvalidatorConfig() *CacheInvalidatorConfig {
 		Channel:     "go-cache:invalidation",
 		DialTimeout: 5 * time.Second,
 		ReadTimeout: 3 * time.Second,
+
+		ReconnectBackoff:    100 * time.Millisecond,
+		ReconnectMaxBackoff: 30 * time.Second,
+		HealthCheckInterval: 10 * time.Second,
+		Recovery:            RecoveryFlushAll,
 	}
 }
 
@@ -86,52 +166,99 @@ func NewCacheInvalidator(config *CacheInvalidatorConfig) (*CacheInvalidator, err
 		return nil, fmt.Errorf("failed to subscribe to channel: %w", err)
 	}
 
-	return &CacheInvalidator{
-		client:   client,
-		pubsub:   pubsub,
-		channel:  config.Channel,
-		handlers: make([]func(key string), 0),
-	}, nil
+	origin := config.InstanceID
+	if origin == "" {
+		origin = NewInstanceID()
+	}
+
+	return newCacheInvalidator(client, pubsub, config.Channel, origin, config), nil
 }
 
-// Broadcast 广播缓存失效消息
-func (ci *CacheInvalidator) Broadcast(key string) error {
-	if atomic.LoadInt32(&ci.closed) == 1 {
-		return fmt.Errorf("CacheInvalidator is closed")
+// NewCacheInvalidatorFromClient 使用已有的 Redis 客户端创建 CacheInvalidator
+// 关闭 CacheInvalidator 只会取消订阅，不会关闭客户端。
+func NewCacheInvalidatorFromClient(client *redis.Client, channel, instanceID string) (*CacheInvalidator, error) {
+	if channel == "" {
+		channel = "go-cache:invalidation"
+	}
+	if instanceID == "" {
+		instanceID = NewInstanceID()
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	message := &InvalidationMessage{
-		Key:       key,
-		Timestamp: time.Now().UnixNano(),
+	pubsub := client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to channel: %w", err)
 	}
 
-	data, err := message.Marshal()
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+	return newCacheInvalidator(client, pubsub, channel, instanceID, DefaultCacheInvalidatorConfig()), nil
+}
+
+// newCacheInvalidator 组装 CacheInvalidator，未设置的重连参数使用默认值
+func newCacheInvalidator(client *redis.Client, pubsub *redis.PubSub, channel, origin string, config *CacheInvalidatorConfig) *CacheInvalidator {
+	defaults := DefaultCacheInvalidatorConfig()
+	policy := reconnectPolicy{
+		backoff:          config.ReconnectBackoff,
+		maxBackoff:       config.ReconnectMaxBackoff,
+		healthCheck:      config.HealthCheckInterval,
+		recovery:         config.Recovery,
+		recoveryPrefixes: config.RecoveryPrefixes,
+	}
+	if policy.backoff <= 0 {
+		policy.backoff = defaults.ReconnectBackoff
+	}
+	if policy.maxBackoff < policy.backoff {
+		policy.maxBackoff = defaults.ReconnectMaxBackoff
+	}
+	if policy.healthCheck <= 0 {
+		policy.healthCheck = defaults.HealthCheckInterval
 	}
 
-	return ci.client.Publish(ctx, ci.channel, string(data)).Err()
+	return &CacheInvalidator{
+		client:    client,
+		pubsub:    pubsub,
+		channel:   channel,
+		origin:    origin,
+		done:      make(chan struct{}),
+		handlers:  make([]func(key string), 0),
+		reconnect: policy,
+		connected: 1,
+	}
+}
+
+// Broadcast 广播缓存失效消息
+func (ci *CacheInvalidator) Broadcast(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return ci.Publish(ctx, &InvalidationMessage{Keys: []string{key}})
 }
 
 // BroadcastWithCache 广播带缓存名称的失效消息
 func (ci *CacheInvalidator) BroadcastWithCache(cacheName, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return ci.Publish(ctx, &InvalidationMessage{CacheName: cacheName, Keys: []string{key}})
+}
+
+// Publish 广播失效消息（可包含多个 key、前缀和标签）
+// 自动填充 origin、时间戳和 ctx 中的追踪上下文。
+func (ci *CacheInvalidator) Publish(ctx context.Context, message *InvalidationMessage) error {
 	if atomic.LoadInt32(&ci.closed) == 1 {
 		return fmt.Errorf("CacheInvalidator is closed")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	message := &InvalidationMessage{
-		CacheName: cacheName,
-		Key:       key,
-		Timestamp: time.Now().UnixNano(),
+	// 在副本上补全来源和追踪上下文，不修改调用方的消息
+	out := *message
+	if out.Origin == "" {
+		out.Origin = ci.origin
+	}
+	if out.Trace == nil {
+		out.InjectTrace(ctx)
 	}
 
-	data, err := message.Marshal()
+	data, err := out.Marshal()
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
@@ -139,59 +266,224 @@ func (ci *CacheInvalidator) BroadcastWithCache(cacheName, key string) error {
 	return ci.client.Publish(ctx, ci.channel, string(data)).Err()
 }
 
-// Subscribe 订阅缓存失效消息（阻塞式）
+// Subscribe 订阅缓存失效消息（阻塞式，直到 Close）
 func (ci *CacheInvalidator) Subscribe(callback func(key string)) {
 	ci.mu.Lock()
 	ci.handlers = append(ci.handlers, callback)
 	ci.mu.Unlock()
 
-	ch := ci.pubsub.Channel()
-	for msg := range ch {
-		if atomic.LoadInt32(&ci.closed) == 1 {
+	_ = ci.receive(context.Background(), func(payload string) {
+		ci.dispatchKeys(payload, callback)
+	})
+}
+
+// SubscribeWithContext 带上下文的订阅（可取消）
+func (ci *CacheInvalidator) SubscribeWithContext(ctx context.Context, callback func(key string)) error {
+	ci.mu.Lock()
+	ci.handlers = append(ci.handlers, callback)
+	ci.mu.Unlock()
+
+	return ci.receive(ctx, func(payload string) {
+		ci.dispatchKeys(payload, callback)
+	})
+}
+
+// SubscribeMessages 订阅完整的失效消息（可取消），忽略本实例发出的消息
+func (ci *CacheInvalidator) SubscribeMessages(ctx context.Context, handler func(message *InvalidationMessage)) error {
+	return ci.receive(ctx, func(payload string) {
+		message, err := UnmarshalInvalidationMessage([]byte(payload))
+		if err != nil {
+			logger.Warn("CacheInvalidator: invalid invalidation message %q: %v", payload, err)
 			return
 		}
+		if message.Origin != "" && message.Origin == ci.origin {
+			return
+		}
+		handler(message)
+	})
+}
 
-		message, err := UnmarshalInvalidationMessage([]byte(msg.Payload))
+// receive 读取订阅消息，连接断开时重新订阅；只在 ctx 取消或 Close 后返回
+func (ci *CacheInvalidator) receive(ctx context.Context, handle func(payload string)) error {
+	for {
+		if ci.IsClosed() {
+			return fmt.Errorf("CacheInvalidator is closed")
+		}
+		ci.mu.RLock()
+		pubsub := ci.pubsub
+		ci.mu.RUnlock()
+
+		msg, err := pubsub.ReceiveTimeout(ctx, ci.reconnect.healthCheck)
 		if err != nil {
-			// 解析失败，尝试直接使用 payload 作为 key
-			callback(msg.Payload)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if ci.IsClosed() {
+				return fmt.Errorf("CacheInvalidator is closed")
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				// 空闲超时：PING 一次，PONG 由下一次 Receive 读取
+				if err = pubsub.Ping(ctx); err == nil {
+					continue
+				}
+			}
+			if err := ci.resubscribe(ctx, pubsub, err); err != nil {
+				return err
+			}
 			continue
 		}
 
-		callback(message.Key)
+		if m, ok := msg.(*redis.Message); ok {
+			handle(m.Payload)
+		}
 	}
 }
 
-// SubscribeWithContext 带上下文的订阅（可取消）
-func (ci *CacheInvalidator) SubscribeWithContext(ctx context.Context, callback func(key string)) error {
-	ci.mu.Lock()
-	ci.handlers = append(ci.handlers, callback)
-	ci.mu.Unlock()
+// resubscribe 按指数退避重新订阅，成功后执行恢复动作
+// stale 是出错时使用的订阅；如果已被其他协程替换，直接返回。
+func (ci *CacheInvalidator) resubscribe(ctx context.Context, stale *redis.PubSub, cause error) error {
+	ci.reconnectMu.Lock()
+	defer ci.reconnectMu.Unlock()
 
-	ch := ci.pubsub.Channel()
-	for {
+	ci.mu.RLock()
+	current := ci.pubsub
+	ci.mu.RUnlock()
+	if current != stale {
+		return nil
+	}
+
+	disconnectedAt := time.Now()
+	atomic.StoreInt32(&ci.connected, 0)
+	atomic.AddInt64(&ci.disconnects, 1)
+	logger.Warn("CacheInvalidator: subscription to %s lost: %v", ci.channel, cause)
+	for _, fn := range ci.disconnectHooks() {
+		fn(cause)
+	}
+
+	backoff := ci.reconnect.backoff
+	for attempt := 1; ; attempt++ {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case msg, ok := <-ch:
-			if !ok {
-				return fmt.Errorf("subscription channel closed")
-			}
-			if atomic.LoadInt32(&ci.closed) == 1 {
-				return fmt.Errorf("CacheInvalidator is closed")
-			}
+		case <-ci.done:
+			return fmt.Errorf("CacheInvalidator is closed")
+		case <-time.After(backoff):
+		}
 
-			message, err := UnmarshalInvalidationMessage([]byte(msg.Payload))
-			if err != nil {
-				callback(msg.Payload)
-				continue
+		pubsub := ci.client.Subscribe(ctx, ci.channel)
+		subCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := pubsub.Receive(subCtx)
+		cancel()
+		if err != nil {
+			pubsub.Close()
+			logger.Warn("CacheInvalidator: resubscribe to %s failed (attempt %d): %v", ci.channel, attempt, err)
+			backoff *= 2
+			if backoff > ci.reconnect.maxBackoff {
+				backoff = ci.reconnect.maxBackoff
 			}
+			continue
+		}
+
+		ci.mu.Lock()
+		if ci.IsClosed() {
+			ci.mu.Unlock()
+			pubsub.Close()
+			return fmt.Errorf("CacheInvalidator is closed")
+		}
+		ci.pubsub = pubsub
+		ci.mu.Unlock()
+		_ = stale.Close()
+		atomic.StoreInt32(&ci.connected, 1)
+
+		downtime := time.Since(disconnectedAt)
+		logger.Info("CacheInvalidator: resubscribed to %s after %v", ci.channel, downtime)
+		ci.recover(ctx)
+		for _, fn := range ci.reconnectHooks() {
+			fn(downtime)
+		}
+		return nil
+	}
+}
+
+// recover 对所有恢复目标执行恢复动作
+func (ci *CacheInvalidator) recover(ctx context.Context) {
+	ci.mu.RLock()
+	targets := append([]CacheBackend(nil), ci.recoveryTargets...)
+	policy := ci.reconnect
+	ci.mu.RUnlock()
 
-			callback(message.Key)
+	for _, target := range targets {
+		if err := policy.recovery.Apply(ctx, target, policy.recoveryPrefixes...); err != nil {
+			logger.Warn("CacheInvalidator: recovery failed: %v", err)
 		}
 	}
 }
 
+func (ci *CacheInvalidator) disconnectHooks() []func(err error) {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+	return append(([]func(err error))(nil), ci.onDisconnect...)
+}
+
+func (ci *CacheInvalidator) reconnectHooks() []func(downtime time.Duration) {
+	ci.mu.RLock()
+	defer ci.mu.RUnlock()
+	return append(([]func(downtime time.Duration))(nil), ci.onReconnect...)
+}
+
+// OnDisconnect 注册订阅断开回调
+func (ci *CacheInvalidator) OnDisconnect(fn func(err error)) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.onDisconnect = append(ci.onDisconnect, fn)
+}
+
+// OnReconnect 注册重新订阅成功回调（在恢复动作之后调用），downtime 为断开时长
+func (ci *CacheInvalidator) OnReconnect(fn func(downtime time.Duration)) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.onReconnect = append(ci.onReconnect, fn)
+}
+
+// AddRecoveryTarget 添加恢复目标（通常是本地 L1），重连后对其执行恢复动作
+func (ci *CacheInvalidator) AddRecoveryTarget(target CacheBackend) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.recoveryTargets = append(ci.recoveryTargets, target)
+}
+
+// SetRecovery 设置重连后的恢复动作
+func (ci *CacheInvalidator) SetRecovery(action RecoveryAction, prefixes ...string) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.reconnect.recovery = action
+	ci.reconnect.recoveryPrefixes = prefixes
+}
+
+// IsConnected 订阅当前是否正常
+func (ci *CacheInvalidator) IsConnected() bool {
+	return atomic.LoadInt32(&ci.connected) == 1
+}
+
+// Disconnects 订阅断开次数
+func (ci *CacheInvalidator) Disconnects() int64 {
+	return atomic.LoadInt64(&ci.disconnects)
+}
+
+// dispatchKeys 解析消息并对其中每个 key 调用回调
+func (ci *CacheInvalidator) dispatchKeys(payload string, callback func(key string)) {
+	message, err := UnmarshalInvalidationMessage([]byte(payload))
+	if err != nil {
+		// 解析失败，尝试直接使用 payload 作为 key
+		callback(payload)
+		return
+	}
+	for _, key := range message.AllKeys() {
+		callback(key)
+	}
+}
+
 // OnInvalidation 注册失效回调（非阻塞）
 func (ci *CacheInvalidator) OnInvalidation(handler func(key string)) {
 	ci.mu.Lock()
@@ -204,7 +496,11 @@ func (ci *CacheInvalidator) Close() error {
 	if !atomic.CompareAndSwapInt32(&ci.closed, 0, 1) {
 		return nil
 	}
-	return ci.pubsub.Close()
+	close(ci.done)
+	ci.mu.RLock()
+	pubsub := ci.pubsub
+	ci.mu.RUnlock()
+	return pubsub.Close()
 }
 
 // IsClosed 检查是否已关闭
@@ -212,83 +508,12 @@ func (ci *CacheInvalidator) IsClosed() bool {
 	return atomic.LoadInt32(&ci.closed) == 1
 }
 
+// InstanceID 获取本实例 ID
+func (ci *CacheInvalidator) InstanceID() string {
+	return ci.origin
+}
+
 // Channel 获取频道名称
 func (ci *CacheInvalidator) Channel() string {
 	return ci.channel
 }
-
-// InvalidationMessage 失效消息
-type InvalidationMessage struct {
-	CacheName string `json:"cache_name"`
-	Key       string `json:"key"`
-	Timestamp int64  `json:"timestamp"`
-}
-
-// Marshal 序列化消息
-func (m *InvalidationMessage) Marshal() ([]byte, error) {
-	// 简单序列化，避免循环依赖
-	if m.CacheName != "" {
-		return []byte(fmt.Sprintf("%s:%s", m.CacheName, m.Key)), nil
-	}
-	return []byte(m.Key), nil
-}
-
-// UnmarshalInvalidationMessage 反序列化消息
-func UnmarshalInvalidationMessage(data []byte) (*InvalidationMessage, error) {
-	payload := string(data)
-
-	// 尝试解析 cache:key 格式（从后往前找第一个冒号）
-	for i := len(payload) - 1; i >= 0; i-- {
-		if payload[i] == ':' && i > 0 {
-			// 检查冒号后面是否是纯数字（时间戳）
-			isTimestamp := true
-			for j := i + 1; j < len(payload); j++ {
-				if payload[j] < '0' || payload[j] > '9' {
-					isTimestamp = false
-					break
-				}
-			}
-			if isTimestamp && len(payload[i+1:]) > 0 {
-				// 格式：cache:key:timestamp，继续往前找 cache:key 分隔符
-				for k := i - 1; k >= 0; k-- {
-					if payload[k] == ':' {
-						return &InvalidationMessage{
-							CacheName: payload[:k],
-							Key:       payload[k+1:i],
-							Timestamp: parseInt64(payload[i+1:]),
-						}, nil
-					}
-				}
-				// 只有 key:timestamp 格式
-				return &InvalidationMessage{
-					Key:       payload[:i],
-					Timestamp: parseInt64(payload[i+1:]),
-				}, nil
-			} else {
-				// 格式：cache:key（没有时间戳）
-				return &InvalidationMessage{
-					CacheName: payload[:i],
-					Key:       payload[i+1:],
-					Timestamp: time.Now().UnixNano(),
-				}, nil
-			}
-		}
-	}
-
-	// 简单格式：直接返回 key
-	return &InvalidationMessage{
-		Key:       payload,
-		Timestamp: time.Now().UnixNano(),
-	}, nil
-}
-
-func parseInt64(s string) int64 {
-	var result int64
-	for _, c := range s {
-		if c < '0' || c > '9' {
-			return 0
-		}
-		result = result*10 + int64(c-'0')
-	}
-	return result
-}
@@ -113,7 +113,7 @@ func TestInvalidationMessage_Marshal(t *testing.T) {
 		t.Fatalf("Marshal failed: %v", err)
 	}
 
-	expected := "users:user-123"
+	expected := `{"v":1,"cache":"users","keys":["user-123"],"ts":1234567890}`
 	if string(data) != expected {
 		t.Errorf("Expected %s, got %s", expected, string(data))
 	}
@@ -130,7 +130,7 @@ func TestInvalidationMessage_MarshalSimple(t *testing.T) {
 		t.Fatalf("Marshal failed: %v", err)
 	}
 
-	expected := "simple-key"
+	expected := `{"v":1,"keys":["simple-key"],"ts":1234567890}`
 	if string(data) != expected {
 		t.Errorf("Expected %s, got %s", expected, string(data))
 	}
@@ -217,3 +217,145 @@ func TestCacheInvalidator_BroadcastAfterClose(t *testing.T) {
 		t.Error("Expected error when broadcasting after close")
 	}
 }
+
+func TestRecoveryAction_Apply(t *testing.T) {
+	ctx := context.Background()
+	newL1 := func() *MemoryBackend {
+		l1, _ := NewMemoryBackend(DefaultCacheConfig("recovery"))
+		l1.Set(ctx, "user:1", "alice", time.Minute)
+		l1.Set(ctx, "order:1", "book", time.Minute)
+		return l1
+	}
+
+	l1 := newL1()
+	defer l1.Close()
+	RecoveryNone.Apply(ctx, l1)
+	if _, found, _ := l1.Get(ctx, "user:1"); !found {
+		t.Error("RecoveryNone should keep entries")
+	}
+	RecoveryFlushPrefix.Apply(ctx, l1, "user:")
+	if _, found, _ := l1.Get(ctx, "user:1"); found {
+		t.Error("RecoveryFlushPrefix should remove user:1")
+	}
+	if _, found, _ := l1.Get(ctx, "order:1"); !found {
+		t.Error("RecoveryFlushPrefix should keep order:1")
+	}
+	RecoveryFlushAll.Apply(ctx, l1)
+	if _, found, _ := l1.Get(ctx, "order:1"); found {
+		t.Error("RecoveryFlushAll should remove all entries")
+	}
+
+	// 不支持清空的后端返回错误
+	plain := struct{ CacheBackend }{newL1()}
+	defer plain.Close()
+	if err := RecoveryFlushAll.Apply(ctx, plain); err == nil {
+		t.Error("Expected error for backend without Clear")
+	}
+}
+
+// reconnectingBus 可以手动触发重连通知的进程内总线
+type reconnectingBus struct {
+	*InProcessBus
+	onReconnect []func(downtime time.Duration)
+}
+
+func (b *reconnectingBus) OnDisconnect(fn func(err error)) {}
+
+func (b *reconnectingBus) OnReconnect(fn func(downtime time.Duration)) {
+	b.onReconnect = append(b.onReconnect, fn)
+}
+
+func (b *reconnectingBus) reconnect() {
+	for _, fn := range b.onReconnect {
+		fn(time.Second)
+	}
+}
+
+func TestHybridBackend_InvalidationRecovery(t *testing.T) {
+	ctx := context.Background()
+	l1, _ := NewMemoryBackend(DefaultCacheConfig("users"))
+	defer l1.Close()
+
+	bus := &reconnectingBus{InProcessBus: NewInProcessBus()}
+	inv, err := newL1Invalidator(bus, true, "users", "node-a", l1, &HybridStats{}, RecoveryFlushPrefix, []string{"user:"})
+	if err != nil {
+		t.Fatalf("Failed to create invalidator: %v", err)
+	}
+
+	l1.Set(ctx, "user:1", "alice", time.Minute)
+	l1.Set(ctx, "order:1", "book", time.Minute)
+	bus.reconnect()
+	if _, found, _ := l1.Get(ctx, "user:1"); found {
+		t.Error("Expected user:1 to be flushed after reconnect")
+	}
+	if _, found, _ := l1.Get(ctx, "order:1"); !found {
+		t.Error("Expected order:1 to survive prefix recovery")
+	}
+
+	// 关闭后不再执行恢复
+	inv.close()
+	l1.Set(ctx, "user:2", "bob", time.Minute)
+	bus.reconnect()
+	if _, found, _ := l1.Get(ctx, "user:2"); !found {
+		t.Error("Expected no recovery after close")
+	}
+}
+
+func TestCacheInvalidator_Resubscribe(t *testing.T) {
+	config := DefaultCacheInvalidatorConfig()
+	config.Channel = "test:invalidation:resubscribe"
+	config.ReconnectBackoff = 10 * time.Millisecond
+	ci, err := NewCacheInvalidator(config)
+	if err != nil {
+		t.Skipf("Redis not available, skipping: %v", err)
+	}
+	defer ci.Close()
+
+	l1, _ := NewMemoryBackend(DefaultCacheConfig("resubscribe"))
+	defer l1.Close()
+	ci.AddRecoveryTarget(l1)
+
+	disconnected := make(chan error, 1)
+	reconnected := make(chan time.Duration, 1)
+	ci.OnDisconnect(func(err error) { disconnected <- err })
+	ci.OnReconnect(func(downtime time.Duration) { reconnected <- downtime })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys := make(chan string, 1)
+	go ci.SubscribeWithContext(ctx, func(key string) { keys <- key })
+
+	ctx2 := context.Background()
+	l1.Set(ctx2, "user:1", "alice", time.Minute)
+
+	// 断开所有 pub/sub 连接
+	if err := ci.client.Do(ctx2, "CLIENT", "KILL", "TYPE", "pubsub").Err(); err != nil {
+		t.Skipf("CLIENT KILL not permitted: %v", err)
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for disconnect")
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for resubscribe")
+	}
+	if _, found, _ := l1.Get(ctx2, "user:1"); found {
+		t.Error("Expected recovery target to be flushed after reconnect")
+	}
+
+	// 重连后可以继续收到消息
+	ci.Broadcast("user:2")
+	select {
+	case key := <-keys:
+		if key != "user:2" {
+			t.Errorf("Expected user:2, got %s", key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for message after reconnect")
+	}
+}
+
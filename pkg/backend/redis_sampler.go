@@ -0,0 +1,166 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// namespaceSampler Redis 命名空间采样器
+// 周期性地 SCAN 前缀下的 key 计数，并对其中一部分 key 执行 MEMORY USAGE，
+// 用平均值估算整个命名空间的内存占用。
+type namespaceSampler struct {
+	client   redis.Cmdable
+	pattern  func() string
+	interval time.Duration
+	samples  int
+	onSample func(size, memoryBytes int64)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+	running  int32
+}
+
+// newNamespaceSampler 创建命名空间采样器
+// pattern: 返回 SCAN 匹配模式，如 "user:*"（每轮采样时重新计算）
+// samples: 每轮 MEMORY USAGE 抽样的 key 数量
+func newNamespaceSampler(client redis.Cmdable, pattern func() string, interval time.Duration, samples int, onSample func(size, memoryBytes int64)) *namespaceSampler {
+	if samples <= 0 {
+		samples = 64
+	}
+	return &namespaceSampler{
+		client:   client,
+		pattern:  pattern,
+		interval: interval,
+		samples:  samples,
+		onSample: onSample,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// start 启动后台采样
+func (s *namespaceSampler) start() {
+	go s.run()
+}
+
+func (s *namespaceSampler) run() {
+	defer close(s.done)
+
+	s.sampleWithTimeout()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.sampleWithTimeout()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// sampleWithTimeout 以采样间隔作为单轮超时执行一次采样
+func (s *namespaceSampler) sampleWithTimeout() {
+	timeout := s.interval
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 停止时中断正在进行的 SCAN
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := s.sample(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		logger.Warn("Redis backend: namespace sampling failed, pattern=%s, error=%v", s.pattern(), err)
+	}
+}
+
+// sample 执行一次采样：SCAN 计数 + 蓄水池抽样 MEMORY USAGE
+func (s *namespaceSampler) sample(ctx context.Context) error {
+	// 同一时间只允许一轮采样
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreInt32(&s.running, 0)
+
+	var count int64
+	reservoir := make([]string, 0, s.samples)
+
+	iter := s.client.Scan(ctx, 0, s.pattern(), 1000).Iterator()
+	for iter.Next(ctx) {
+		count++
+		if len(reservoir) < s.samples {
+			reservoir = append(reservoir, iter.Val())
+		} else if j := rand.Int64N(count); j < int64(s.samples) {
+			reservoir[j] = iter.Val()
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	memory, err := s.estimateMemory(ctx, reservoir, count)
+	if err != nil {
+		return err
+	}
+
+	if s.onSample != nil {
+		s.onSample(count, memory)
+	}
+	return nil
+}
+
+// estimateMemory 根据抽样 key 的平均 MEMORY USAGE 估算总内存
+func (s *namespaceSampler) estimateMemory(ctx context.Context, keys []string, total int64) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.MemoryUsage(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	var sum, measured int64
+	for _, cmd := range cmds {
+		// 抽样期间过期的 key 返回 nil，跳过
+		usage, err := cmd.Result()
+		if err != nil {
+			continue
+		}
+		sum += usage
+		measured++
+	}
+	if measured == 0 {
+		return 0, nil
+	}
+	return sum * total / measured, nil
+}
+
+// close 停止后台采样并等待退出
+func (s *namespaceSampler) close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+}
@@ -114,6 +114,75 @@ func TestRedisBackendBasic(t *testing.T) {
 	})
 }
 
+// TestApplyRedisOptions 测试 CacheConfig.Options 到 Redis 配置的映射
+func TestApplyRedisOptions(t *testing.T) {
+	config := DefaultCacheConfig("session:web")
+	config.Options = map[string]string{"addr": "redis:6380", "password": "secret", "db": "2", "pool_size": "32"}
+
+	rc := DefaultRedisConfig()
+	if err := applyRedisOptions(rc, config); err != nil {
+		t.Fatalf("applyRedisOptions failed: %v", err)
+	}
+	if rc.Addr != "redis:6380" || rc.Password != "secret" || rc.DB != 2 || rc.PoolSize != 32 {
+		t.Errorf("Unexpected redis config: %+v", rc)
+	}
+	// 未指定 prefix 时保留默认前缀
+	if rc.Prefix != DefaultRedisConfig().Prefix {
+		t.Errorf("Expected default prefix, got %q", rc.Prefix)
+	}
+	config.Options["prefix"] = "web:"
+	if err := applyRedisOptions(rc, config); err != nil || rc.Prefix != "web:" {
+		t.Errorf("Expected prefix web:, got %q (%v)", rc.Prefix, err)
+	}
+
+	config.Options = map[string]string{"db": "two"}
+	if err := applyRedisOptions(DefaultRedisConfig(), config); err == nil {
+		t.Error("Expected error for invalid db option")
+	}
+}
+
+// TestRedisBackendDeletePrefix 测试按前缀删除
+func TestRedisBackendDeletePrefix(t *testing.T) {
+	config := &RedisConfig{
+		Addr:       "localhost:6379",
+		Prefix:     "delete-prefix-test",
+		DefaultTTL: 5 * time.Second,
+	}
+
+	backend, err := NewRedisBackend(config)
+	if err != nil {
+		t.Skipf("Redis not available, skipping: %v", err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+	backend.Set(ctx, "acme:1", "a1", 5*time.Second)
+	backend.Set(ctx, "acme:2", "a2", 5*time.Second)
+	backend.Set(ctx, "globex:1", "g1", 5*time.Second)
+	// 通配字符按字面匹配
+	backend.Set(ctx, "ac*:1", "x", 5*time.Second)
+
+	deleted, err := backend.DeletePrefix(ctx, "acme:")
+	if err != nil || deleted != 2 {
+		t.Fatalf("Expected 2 deleted, got %d (%v)", deleted, err)
+	}
+	if _, found, _ := backend.Get(ctx, "acme:1"); found {
+		t.Error("Expected acme:1 to be deleted")
+	}
+	for _, key := range []string{"globex:1", "ac*:1"} {
+		if _, found, _ := backend.Get(ctx, key); !found {
+			t.Errorf("Expected %s to be kept", key)
+		}
+	}
+	backend.Clear(ctx)
+}
+
+func TestEscapeScanPattern(t *testing.T) {
+	if got := escapeScanPattern(`p:a*b?[c]\`); got != `p:a\*b\?\[c\]\\` {
+		t.Errorf("Unexpected escaped pattern: %s", got)
+	}
+}
+
 // TestRedisBackendNilValue 测试空值缓存（穿透保护）
 func TestRedisBackendNilValue(t *testing.T) {
 	t.Skip("Skipping Redis test - requires running Redis instance")
@@ -263,6 +332,123 @@ func TestRedisBackendPrefix(t *testing.T) {
 	}
 }
 
+// TestRedisBackendNamespaceStats 测试命名空间采样只统计当前前缀
+func TestRedisBackendNamespaceStats(t *testing.T) {
+	config := &RedisConfig{
+		Addr:       "localhost:6379",
+		Prefix:     "sampler-test",
+		DefaultTTL: 5 * time.Second,
+	}
+
+	backend, err := NewRedisBackend(config)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+	// 其他前缀的 key 不应计入
+	backend.Client().Set(ctx, "other-prefix:key", "v", 5*time.Second)
+
+	for i := 0; i < 5; i++ {
+		if err := backend.Set(ctx, "key"+string(rune('0'+i)), "value", 5*time.Second); err != nil {
+			t.Fatalf("Failed to set: %v", err)
+		}
+	}
+
+	if err := backend.RefreshStats(ctx); err != nil {
+		t.Fatalf("RefreshStats failed: %v", err)
+	}
+
+	stats := backend.Stats()
+	if stats.Size != 5 {
+		t.Errorf("Expected size 5, got %d", stats.Size)
+	}
+	if stats.MemoryBytes <= 0 {
+		t.Errorf("Expected positive memory estimate, got %d", stats.MemoryBytes)
+	}
+	if backend.LastSampleTime().IsZero() {
+		t.Error("Expected last sample time to be set")
+	}
+}
+
+// TestRedisBackendWriteBehind 测试异步写回
+func TestRedisBackendWriteBehind(t *testing.T) {
+	config := DefaultRedisConfig()
+	config.Prefix = "test_write_behind"
+	config.StatsSampleInterval = 0
+	config.WriteBehind.Enabled = true
+	config.WriteBehind.FlushInterval = time.Hour
+
+	backend, err := NewRedisBackend(config)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+	if err := backend.Set(ctx, "key1", "value1", time.Minute); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+
+	// 尚未写入 Redis，但本实例能读到自己的写入
+	if exists, _ := backend.Client().Exists(ctx, "test_write_behind:key1").Result(); exists != 0 {
+		t.Error("Expected key not yet written to Redis")
+	}
+	if val, found, _ := backend.Get(ctx, "key1"); !found || val != "value1" {
+		t.Errorf("Expected pending value1, got %v (found=%v)", val, found)
+	}
+
+	if err := backend.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if exists, _ := backend.Client().Exists(ctx, "test_write_behind:key1").Result(); exists != 1 {
+		t.Error("Expected key written to Redis after flush")
+	}
+	if stats := backend.WriteBehindStats(); stats.Flushed != 1 || stats.QueueDepth != 0 {
+		t.Errorf("Unexpected write-behind stats: %+v", stats)
+	}
+
+	backend.Delete(ctx, "key1")
+	if _, found, _ := backend.Get(ctx, "key1"); found {
+		t.Error("Expected pending delete to hide the value")
+	}
+	backend.Flush(ctx)
+}
+
+// TestRedisBackendWriteBehindClear 测试 Clear 丢弃排队中的写入
+func TestRedisBackendWriteBehindClear(t *testing.T) {
+	config := DefaultRedisConfig()
+	config.Prefix = "test_write_behind_clear"
+	config.StatsSampleInterval = 0
+	config.WriteBehind.Enabled = true
+	config.WriteBehind.FlushInterval = time.Hour
+
+	backend, err := NewRedisBackend(config)
+	if err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+	backend.Set(ctx, "key1", "value1", time.Minute)
+	if err := backend.Clear(ctx); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, found, _ := backend.Get(ctx, "key1"); found {
+		t.Error("Expected pending write to be discarded by Clear")
+	}
+
+	// 清空后刷新不会把旧写入写回 Redis
+	backend.Flush(ctx)
+	if exists, _ := backend.Client().Exists(ctx, "test_write_behind_clear:key1").Result(); exists != 0 {
+		t.Error("Expected discarded write not to reach Redis")
+	}
+	if stats := backend.WriteBehindStats(); stats.QueueDepth != 0 || stats.Flushed != 0 {
+		t.Errorf("Unexpected write-behind stats: %+v", stats)
+	}
+}
+
 // TestRedisBackendClose 测试关闭
 func TestRedisBackendClose(t *testing.T) {
 	t.Skip("Skipping Redis test - requires running Redis instance")
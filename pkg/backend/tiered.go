@@ -0,0 +1,419 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/logger"
+)
+
+// TieredWriteMode 多级缓存写入模式
+type TieredWriteMode string
+
+const (
+	// WriteThroughAll 同时写入所有层（默认）
+	WriteThroughAll TieredWriteMode = "all"
+	// WriteLastOnly 只写入最后一层（数据源），上层依靠读取时回填，并删除上层旧值
+	WriteLastOnly TieredWriteMode = "last"
+)
+
+// TierTTLPolicy 单层 TTL 策略
+// 优先级：TTL（固定值） > Ratio（调用方 TTL 的比例） > 调用方 TTL，最终不超过 MaxTTL。
+type TierTTLPolicy struct {
+	TTL    time.Duration // 固定 TTL
+	Ratio  float64       // 相对调用方 TTL 的比例（0 < Ratio <= 1）
+	MaxTTL time.Duration // TTL 上限（0 表示不限制）
+}
+
+// Apply 计算本层实际使用的 TTL
+func (p TierTTLPolicy) Apply(ttl time.Duration) time.Duration {
+	result := ttl
+	if p.TTL > 0 {
+		result = p.TTL
+	} else if p.Ratio > 0 && p.Ratio < 1 && ttl > 0 {
+		result = time.Duration(float64(ttl) * p.Ratio)
+	}
+	if p.MaxTTL > 0 && (result <= 0 || result > p.MaxTTL) {
+		result = p.MaxTTL
+	}
+	return result
+}
+
+// Tier 缓存层
+type Tier struct {
+	Name        string        // 层名称（用于日志和统计）
+	Backend     CacheBackend  // 后端实现
+	TTL         TierTTLPolicy // 写入本层时的 TTL 策略
+	Backfill    bool          // 下层命中时是否回填本层
+	BackfillTTL time.Duration // 回填 TTL（默认 5 分钟）
+}
+
+// TieredConfig 多级缓存配置
+type TieredConfig struct {
+	Tiers     []*Tier         // 按访问顺序排列，第一层最快，最后一层为数据源
+	WriteMode TieredWriteMode // 写入模式（默认 WriteThroughAll）
+}
+
+// TieredBackend 多级缓存后端（任意 N 层 CacheBackend 组合）
+// 例如：分片内存 → Redis Cluster，或内存 → Redis → 磁盘。
+type TieredBackend struct {
+	mu        sync.RWMutex
+	tiers     []*Tier
+	writeMode TieredWriteMode
+	stats     *TieredStats
+	closed    bool
+}
+
+// TieredStats 多级缓存统计
+type TieredStats struct {
+	tierHits      []int64 // 各层命中次数
+	tierBackfills []int64 // 各层回填次数
+	misses        int64   // 所有层均未命中的次数
+	sets, deletes int64
+	errors        int64
+}
+
+// TierStats 单层统计快照
+type TierStats struct {
+	Name      string
+	Hits      int64
+	Backfills int64
+	Backend   *CacheStats
+}
+
+// NewTieredBackend 创建多级缓存后端
+func NewTieredBackend(config *TieredConfig) (*TieredBackend, error) {
+	if config == nil || len(config.Tiers) == 0 {
+		return nil, errors.New("tiered backend requires at least one tier")
+	}
+	for i, tier := range config.Tiers {
+		if tier == nil || tier.Backend == nil {
+			return nil, fmt.Errorf("tier %d has no backend", i)
+		}
+		if tier.Name == "" {
+			tier.Name = fmt.Sprintf("L%d", i+1)
+		}
+		if tier.BackfillTTL <= 0 {
+			tier.BackfillTTL = 5 * time.Minute
+		}
+	}
+
+	writeMode := config.WriteMode
+	if writeMode == "" {
+		writeMode = WriteThroughAll
+	}
+	if writeMode != WriteThroughAll && writeMode != WriteLastOnly {
+		return nil, fmt.Errorf("unknown tiered write mode: %s", writeMode)
+	}
+
+	return &TieredBackend{
+		tiers:     config.Tiers,
+		writeMode: writeMode,
+		stats: &TieredStats{
+			tierHits:      make([]int64, len(config.Tiers)),
+			tierBackfills: make([]int64, len(config.Tiers)),
+		},
+	}, nil
+}
+
+// NewTieredBackendFromRegistry 使用已注册的后端工厂按顺序创建各层
+// 例如 NewTieredBackendFromRegistry(config, "memory", "redis")
+func NewTieredBackendFromRegistry(config *CacheConfig, names ...string) (*TieredBackend, error) {
+	tiers := make([]*Tier, 0, len(names))
+	closeAll := func() {
+		for _, tier := range tiers {
+			tier.Backend.Close()
+		}
+	}
+
+	for i, name := range names {
+		factory, ok := GetFactory(name)
+		if !ok {
+			closeAll()
+			return nil, fmt.Errorf("backend %q is not registered", name)
+		}
+		b, err := factory(config)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to create tier %q: %w", name, err)
+		}
+		tiers = append(tiers, &Tier{
+			Name:     name,
+			Backend:  b,
+			Backfill: i < len(names)-1,
+		})
+	}
+
+	t, err := NewTieredBackend(&TieredConfig{Tiers: tiers})
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return t, nil
+}
+
+// Get 获取缓存值（逐层查询，命中后回填上层）
+func (t *TieredBackend) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	t.mu.RLock()
+	if t.closed {
+		t.mu.RUnlock()
+		return nil, false, nil
+	}
+	t.mu.RUnlock()
+
+	for i, tier := range t.tiers {
+		val, found, remaining, err := t.getFromTier(ctx, tier, key)
+		if err != nil {
+			// 单层故障不影响后续层
+			logger.Warn("Tiered backend: tier %s get failed, key=%s, error=%v", tier.Name, key, err)
+			atomicAddInt64(&t.stats.errors, 1)
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		atomicAddInt64(&t.stats.tierHits[i], 1)
+		t.backfill(ctx, i, key, val, remaining)
+		return val, true, nil
+	}
+
+	atomicAddInt64(&t.stats.misses, 1)
+	return nil, false, nil
+}
+
+// getFromTier 从单层读取；层支持 TTLGetter 时同时返回剩余 TTL
+func (t *TieredBackend) getFromTier(ctx context.Context, tier *Tier, key string) (interface{}, bool, time.Duration, error) {
+	if tg, ok := tier.Backend.(TTLGetter); ok {
+		return tg.GetWithTTL(ctx, key)
+	}
+	val, found, err := tier.Backend.Get(ctx, key)
+	return val, found, 0, err
+}
+
+// backfill 将第 hit 层命中的值回填到其上方允许回填的层
+// 回填 TTL 不超过命中层的剩余 TTL（remaining 为 0 表示未知或不过期）。
+func (t *TieredBackend) backfill(ctx context.Context, hit int, key string, val interface{}, remaining time.Duration) {
+	for j := 0; j < hit; j++ {
+		tier := t.tiers[j]
+		if !tier.Backfill {
+			continue
+		}
+		ttl := tier.BackfillTTL
+		if remaining > 0 && remaining < ttl {
+			ttl = remaining
+		}
+		if err := tier.Backend.Set(ctx, key, val, ttl); err != nil {
+			logger.Warn("Tiered backend: backfill tier %s failed, key=%s, error=%v", tier.Name, key, err)
+			continue
+		}
+		atomicAddInt64(&t.stats.tierBackfills[j], 1)
+	}
+}
+
+// Set 设置缓存值（按写入模式写入各层）
+func (t *TieredBackend) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	t.mu.RLock()
+	if t.closed {
+		t.mu.RUnlock()
+		return nil
+	}
+	t.mu.RUnlock()
+
+	last := len(t.tiers) - 1
+	var firstErr error
+
+	// 先写下层再写上层，避免上层短暂领先于数据源
+	for i := last; i >= 0; i-- {
+		tier := t.tiers[i]
+		var err error
+		if i == last || t.writeMode == WriteThroughAll {
+			err = tier.Backend.Set(ctx, key, value, tier.TTL.Apply(ttl))
+		} else {
+			// 只写最后一层时删除上层旧值，下次读取再回填
+			err = tier.Backend.Delete(ctx, key)
+		}
+		if err != nil {
+			atomicAddInt64(&t.stats.errors, 1)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	atomicAddInt64(&t.stats.sets, 1)
+	return firstErr
+}
+
+// Delete 删除缓存值（从最后一层到第一层）
+func (t *TieredBackend) Delete(ctx context.Context, key string) error {
+	t.mu.RLock()
+	if t.closed {
+		t.mu.RUnlock()
+		return nil
+	}
+	t.mu.RUnlock()
+
+	var firstErr error
+	for i := len(t.tiers) - 1; i >= 0; i-- {
+		if err := t.tiers[i].Backend.Delete(ctx, key); err != nil {
+			atomicAddInt64(&t.stats.errors, 1)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	atomicAddInt64(&t.stats.deletes, 1)
+	return firstErr
+}
+
+// Close 关闭所有层（从最后一层到第一层）
+func (t *TieredBackend) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	var firstErr error
+	for i := len(t.tiers) - 1; i >= 0; i-- {
+		if err := t.tiers[i].Backend.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// DeletePrefix 在各层删除以 prefix 开头的 key（从最后一层到第一层），返回最后一层的删除数量
+// 任何一层不支持前缀删除时返回错误，否则该层的旧数据会被回填到上层。
+func (t *TieredBackend) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	t.mu.RLock()
+	if t.closed {
+		t.mu.RUnlock()
+		return 0, nil
+	}
+	t.mu.RUnlock()
+
+	for _, tier := range t.tiers {
+		if _, ok := tier.Backend.(PrefixDeleter); !ok {
+			return 0, fmt.Errorf("tier %s does not support prefix deletion", tier.Name)
+		}
+	}
+
+	deleted := 0
+	var firstErr error
+	for i := len(t.tiers) - 1; i >= 0; i-- {
+		n, err := t.tiers[i].Backend.(PrefixDeleter).DeletePrefix(ctx, prefix)
+		if err != nil {
+			atomicAddInt64(&t.stats.errors, 1)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if i == len(t.tiers)-1 {
+			deleted = n
+		}
+	}
+	return deleted, firstErr
+}
+
+// Ping 探测实现了 HealthChecker 的各层
+// 部分层不可用或降级时返回 ErrDegraded，所有层都不可用时返回错误。
+func (t *TieredBackend) Ping(ctx context.Context) error {
+	var problems []string
+	down := 0
+	for _, tier := range t.tiers {
+		checker, ok := tier.Backend.(HealthChecker)
+		if !ok {
+			continue
+		}
+		if err := checker.Ping(ctx); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", tier.Name, err))
+			if !errors.Is(err, ErrDegraded) {
+				down++
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if down == len(t.tiers) {
+		return fmt.Errorf("all tiers unavailable: %s", strings.Join(problems, "; "))
+	}
+	return fmt.Errorf("%w: %s", ErrDegraded, strings.Join(problems, "; "))
+}
+
+// Stats 获取合并后的统计信息
+func (t *TieredBackend) Stats() *CacheStats {
+	var hits, size, memory int64
+	for i, tier := range t.tiers {
+		hits += atomicLoadInt64(&t.stats.tierHits[i])
+		s := tier.Backend.Stats()
+		size += s.Size
+		memory += s.MemoryBytes
+	}
+	misses := atomicLoadInt64(&t.stats.misses)
+	total := hits + misses
+
+	hitRate := 0.0
+	if total > 0 {
+		hitRate = float64(hits) / float64(total)
+	}
+
+	first := t.tiers[0].Backend.Stats()
+	return &CacheStats{
+		Hits:        hits,
+		Misses:      misses,
+		Sets:        atomicLoadInt64(&t.stats.sets),
+		Deletes:     atomicLoadInt64(&t.stats.deletes),
+		Evictions:   first.Evictions, // 第一层的淘汰数
+		Size:        size,
+		MaxSize:     first.MaxSize, // 第一层的最大容量
+		HitRate:     hitRate,
+		MemoryBytes: memory,
+	}
+}
+
+// TierStats 获取各层统计
+func (t *TieredBackend) TierStats() []TierStats {
+	result := make([]TierStats, len(t.tiers))
+	for i, tier := range t.tiers {
+		result[i] = TierStats{
+			Name:      tier.Name,
+			Hits:      atomicLoadInt64(&t.stats.tierHits[i]),
+			Backfills: atomicLoadInt64(&t.stats.tierBackfills[i]),
+			Backend:   tier.Backend.Stats(),
+		}
+	}
+	return result
+}
+
+// Tiers 获取各层（用于高级操作）
+func (t *TieredBackend) Tiers() []*Tier {
+	return t.tiers
+}
+
+// WriteMode 获取写入模式
+func (t *TieredBackend) WriteMode() TieredWriteMode {
+	return t.writeMode
+}
+
+// 确保实现 CacheBackend 接口
+var _ CacheBackend = (*TieredBackend)(nil)
+var _ HealthChecker = (*TieredBackend)(nil)
+var _ PrefixDeleter = (*TieredBackend)(nil)
+
+// init 注册多级缓存后端（默认 memory → redis，可用 tiers 选项指定，如 "memory,redis"）
+func init() {
+	Register("tiered", func(config *CacheConfig) (CacheBackend, error) {
+		return NewTieredBackendFromRegistry(config, strings.Split(config.Option("tiers", "memory,redis"), ",")...)
+	})
+}
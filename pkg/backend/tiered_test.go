@@ -0,0 +1,258 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestTiers(t *testing.T, n int) []*Tier {
+	t.Helper()
+	tiers := make([]*Tier, n)
+	for i := 0; i < n; i++ {
+		config := DefaultCacheConfig("tier")
+		config.MaxSize = 100
+		b, err := NewMemoryBackend(config)
+		if err != nil {
+			t.Fatalf("Failed to create memory backend: %v", err)
+		}
+		tiers[i] = &Tier{Backend: b, Backfill: i < n-1}
+	}
+	return tiers
+}
+
+func TestTieredBackend_GetBackfill(t *testing.T) {
+	tiers := newTestTiers(t, 3)
+	tiered, err := NewTieredBackend(&TieredConfig{Tiers: tiers})
+	if err != nil {
+		t.Fatalf("Failed to create tiered backend: %v", err)
+	}
+	defer tiered.Close()
+
+	ctx := context.Background()
+
+	// 只写入最后一层
+	tiers[2].Backend.Set(ctx, "key1", "value1", time.Minute)
+
+	val, found, err := tiered.Get(ctx, "key1")
+	if err != nil || !found || val != "value1" {
+		t.Fatalf("Expected value1 from last tier, got %v (found=%v, err=%v)", val, found, err)
+	}
+
+	// 上层已被回填
+	for i := 0; i < 2; i++ {
+		if _, found, _ := tiers[i].Backend.Get(ctx, "key1"); !found {
+			t.Errorf("Expected tier %d to be backfilled", i)
+		}
+	}
+
+	stats := tiered.TierStats()
+	if stats[2].Hits != 1 || stats[0].Backfills != 1 || stats[1].Backfills != 1 {
+		t.Errorf("Unexpected tier stats: %+v", stats)
+	}
+	if stats[0].Name != "L1" || stats[2].Name != "L3" {
+		t.Errorf("Expected default tier names, got %s and %s", stats[0].Name, stats[2].Name)
+	}
+
+	// 第二次读取命中第一层
+	tiered.Get(ctx, "key1")
+	if hits := tiered.TierStats()[0].Hits; hits != 1 {
+		t.Errorf("Expected 1 hit on first tier, got %d", hits)
+	}
+}
+
+func TestTieredBackend_BackfillBoundedByRemainingTTL(t *testing.T) {
+	tiers := newTestTiers(t, 2)
+	tiered, _ := NewTieredBackend(&TieredConfig{Tiers: tiers})
+	defer tiered.Close()
+
+	ctx := context.Background()
+	tiers[1].Backend.Set(ctx, "key1", "value1", 2*time.Second)
+
+	if _, found, _ := tiered.Get(ctx, "key1"); !found {
+		t.Fatal("Expected to find key1")
+	}
+
+	_, found, remaining, _ := tiers[0].Backend.(TTLGetter).GetWithTTL(ctx, "key1")
+	if !found {
+		t.Fatal("Expected first tier to be backfilled")
+	}
+	if remaining <= 0 || remaining > 2*time.Second {
+		t.Errorf("Expected backfill TTL bounded by 2s, got %v", remaining)
+	}
+}
+
+func TestTieredBackend_NoBackfill(t *testing.T) {
+	tiers := newTestTiers(t, 2)
+	tiers[0].Backfill = false
+	tiered, _ := NewTieredBackend(&TieredConfig{Tiers: tiers})
+	defer tiered.Close()
+
+	ctx := context.Background()
+	tiers[1].Backend.Set(ctx, "key1", "value1", time.Minute)
+
+	if _, found, _ := tiered.Get(ctx, "key1"); !found {
+		t.Fatal("Expected to find key1")
+	}
+	if _, found, _ := tiers[0].Backend.Get(ctx, "key1"); found {
+		t.Error("Expected first tier not to be backfilled")
+	}
+}
+
+func TestTieredBackend_WriteModes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("WriteThroughAll", func(t *testing.T) {
+		tiers := newTestTiers(t, 2)
+		tiered, _ := NewTieredBackend(&TieredConfig{Tiers: tiers})
+		defer tiered.Close()
+
+		tiered.Set(ctx, "key1", "value1", time.Minute)
+		for i, tier := range tiers {
+			if _, found, _ := tier.Backend.Get(ctx, "key1"); !found {
+				t.Errorf("Expected tier %d to contain key1", i)
+			}
+		}
+	})
+
+	t.Run("WriteLastOnly", func(t *testing.T) {
+		tiers := newTestTiers(t, 2)
+		tiered, _ := NewTieredBackend(&TieredConfig{Tiers: tiers, WriteMode: WriteLastOnly})
+		defer tiered.Close()
+
+		// 上层的旧值在写入时被删除
+		tiers[0].Backend.Set(ctx, "key1", "stale", time.Minute)
+		tiered.Set(ctx, "key1", "fresh", time.Minute)
+
+		if _, found, _ := tiers[0].Backend.Get(ctx, "key1"); found {
+			t.Error("Expected first tier to be invalidated")
+		}
+		if val, _, _ := tiered.Get(ctx, "key1"); val != "fresh" {
+			t.Errorf("Expected fresh, got %v", val)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tiers := newTestTiers(t, 1)
+		defer tiers[0].Backend.Close()
+		if _, err := NewTieredBackend(&TieredConfig{Tiers: tiers, WriteMode: "bogus"}); err == nil {
+			t.Error("Expected error for unknown write mode")
+		}
+	})
+}
+
+func TestTieredBackend_Delete(t *testing.T) {
+	tiers := newTestTiers(t, 2)
+	tiered, _ := NewTieredBackend(&TieredConfig{Tiers: tiers})
+	defer tiered.Close()
+
+	ctx := context.Background()
+	tiered.Set(ctx, "key1", "value1", time.Minute)
+	tiered.Delete(ctx, "key1")
+
+	for i, tier := range tiers {
+		if _, found, _ := tier.Backend.Get(ctx, "key1"); found {
+			t.Errorf("Expected tier %d to be deleted", i)
+		}
+	}
+	if _, found, _ := tiered.Get(ctx, "key1"); found {
+		t.Error("Expected miss after delete")
+	}
+	if misses := tiered.Stats().Misses; misses != 1 {
+		t.Errorf("Expected 1 miss, got %d", misses)
+	}
+}
+
+func TestTierTTLPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy TierTTLPolicy
+		ttl    time.Duration
+		want   time.Duration
+	}{
+		{"passthrough", TierTTLPolicy{}, time.Hour, time.Hour},
+		{"fixed", TierTTLPolicy{TTL: time.Minute}, time.Hour, time.Minute},
+		{"ratio", TierTTLPolicy{Ratio: 0.1}, time.Hour, 6 * time.Minute},
+		{"max", TierTTLPolicy{MaxTTL: 10 * time.Minute}, time.Hour, 10 * time.Minute},
+		{"max for default ttl", TierTTLPolicy{MaxTTL: 10 * time.Minute}, 0, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Apply(tt.ttl); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestTieredBackend_Validation(t *testing.T) {
+	if _, err := NewTieredBackend(nil); err == nil {
+		t.Error("Expected error for nil config")
+	}
+	if _, err := NewTieredBackend(&TieredConfig{Tiers: []*Tier{{Name: "empty"}}}); err == nil {
+		t.Error("Expected error for tier without backend")
+	}
+	if _, err := NewTieredBackendFromRegistry(DefaultCacheConfig("x"), "memory", "nonexistent"); err == nil {
+		t.Error("Expected error for unregistered backend")
+	}
+	if _, ok := GetFactory("tiered"); !ok {
+		t.Error("Expected tiered backend to be registered")
+	}
+}
+
+// pingBackend 可控制 Ping 结果的内存后端
+type pingBackend struct {
+	*MemoryBackend
+	err error
+}
+
+func (p *pingBackend) Ping(ctx context.Context) error { return p.err }
+
+func TestTieredBackend_Ping(t *testing.T) {
+	ctx := context.Background()
+	tiers := newTestTiers(t, 2)
+	remote := &pingBackend{MemoryBackend: tiers[1].Backend.(*MemoryBackend)}
+	tiers[1].Backend = remote
+	tiered, _ := NewTieredBackend(&TieredConfig{Tiers: tiers})
+	defer tiered.Close()
+
+	if err := tiered.Ping(ctx); err != nil {
+		t.Errorf("Expected healthy tiers, got %v", err)
+	}
+
+	// 只有一层不可用：降级
+	remote.err = errors.New("connection refused")
+	if err := tiered.Ping(ctx); !errors.Is(err, ErrDegraded) {
+		t.Errorf("Expected ErrDegraded, got %v", err)
+	}
+
+	// 所有层都不可用
+	local := &pingBackend{MemoryBackend: tiers[0].Backend.(*MemoryBackend), err: errors.New("down")}
+	tiers[0].Backend = local
+	if err := tiered.Ping(ctx); err == nil || errors.Is(err, ErrDegraded) {
+		t.Errorf("Expected tiers to be down, got %v", err)
+	}
+}
+
+func TestTieredBackend_DeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	tiers := newTestTiers(t, 2)
+	tiered, _ := NewTieredBackend(&TieredConfig{Tiers: tiers})
+	defer tiered.Close()
+
+	tiered.Set(ctx, "acme:1", "a1", time.Minute)
+	tiered.Set(ctx, "acme:2", "a2", time.Minute)
+	tiered.Set(ctx, "globex:1", "g1", time.Minute)
+
+	deleted, err := tiered.DeletePrefix(ctx, "acme:")
+	if err != nil || deleted != 2 {
+		t.Fatalf("Expected 2 deleted, got %d (%v)", deleted, err)
+	}
+	for i, tier := range tiers {
+		if _, found, _ := tier.Backend.Get(ctx, "acme:1"); found {
+			t.Errorf("Expected acme:1 to be deleted from tier %d", i)
+		}
+		if _, found, _ := tier.Backend.Get(ctx, "globex:1"); !found {
+			t.Errorf("Expected globex:1 to be kept in tier %d", i)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/logger"
+)
+
+// WriteBehindPolicy 写回队列满时的处理策略
+type WriteBehindPolicy string
+
+const (
+	// WriteBehindBlock 阻塞等待队列空位（背压），直到 ctx 取消
+	WriteBehindBlock WriteBehindPolicy = "block"
+	// WriteBehindDrop 丢弃新的写入（删除操作仍同步执行，避免留下旧值）
+	WriteBehindDrop WriteBehindPolicy = "drop"
+	// WriteBehindSync 退化为同步写入
+	WriteBehindSync WriteBehindPolicy = "sync"
+)
+
+// ErrWriteBehindClosed 写回队列已关闭
+var ErrWriteBehindClosed = &BackendError{Code: "WRITE_BEHIND_CLOSED", Message: "write-behind queue is closed"}
+
+// WriteBehindConfig 异步写回配置
+type WriteBehindConfig struct {
+	Enabled       bool              // 是否启用异步写回
+	QueueSize     int               // 队列容量（按不同 key 计数，同一 key 的多次写入合并）
+	BatchSize     int               // 每批 pipeline 写入的最大操作数
+	FlushInterval time.Duration     // 定时刷新间隔
+	Policy        WriteBehindPolicy // 队列满时的策略
+	FlushTimeout  time.Duration     // 关闭时刷新剩余写入的超时
+}
+
+// DefaultWriteBehindConfig 默认异步写回配置（默认不启用）
+func DefaultWriteBehindConfig() *WriteBehindConfig {
+	return &WriteBehindConfig{
+		Enabled:       false,
+		QueueSize:     10000,
+		BatchSize:     100,
+		FlushInterval: 100 * time.Millisecond,
+		Policy:        WriteBehindBlock,
+		FlushTimeout:  5 * time.Second,
+	}
+}
+
+// WriteBehindStats 异步写回统计
+type WriteBehindStats struct {
+	QueueDepth int64 // 当前待写入的 key 数量
+	Enqueued   int64 // 入队的写入数
+	Coalesced  int64 // 被同 key 后续写入合并的次数
+	Flushed    int64 // 成功写入后端的操作数
+	Dropped    int64 // 队列满被丢弃的写入数
+	Errors     int64 // 批量写入失败次数
+}
+
+// WriteBehindBackend 支持异步写回的后端
+type WriteBehindBackend interface {
+	// Flush 立即写入所有待写入的操作
+	Flush(ctx context.Context) error
+	// WriteBehindStats 异步写回统计（未启用时返回 nil）
+	WriteBehindStats() *WriteBehindStats
+}
+
+// writeOp 一次待写入的操作
+type writeOp struct {
+	key    string        // 未加前缀的 key
+	target string        // 入队时的完整 key（含前缀和代数），为空时由 flushFn 自行构建
+	data   []byte        // 序列化后的值
+	ttl    time.Duration // 已标准化的 TTL
+	delete bool          // true 表示删除
+}
+
+// errWriteBehindFull 队列已满且策略要求同步写入（内部使用）
+var errWriteBehindFull = &BackendError{Code: "WRITE_BEHIND_FULL", Message: "write-behind queue is full"}
+
+// writeBehindQueue 按 key 合并的有界写回队列
+// 写入先进入 pending，由后台协程按批次交给 flushFn；
+// 批次写入期间的操作保存在 inflight 中，保证本实例读到自己的写入。
+type writeBehindQueue struct {
+	config    *WriteBehindConfig
+	flushFn   func(ctx context.Context, ops []*writeOp) error
+	onFlushed []func(ops []*writeOp)
+
+	mu       sync.Mutex
+	pending  map[string]*writeOp
+	order    []string // 按入队顺序排列的 key
+	inflight map[string]*writeOp
+	space    chan struct{} // 出队时关闭，唤醒阻塞的写入者
+	closed   bool
+
+	flushMu sync.Mutex // 串行化批次写入，保证同一 key 的写入顺序
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+
+	enqueued, coalesced, flushed, dropped, errors int64
+}
+
+// newWriteBehindQueue 创建写回队列
+func newWriteBehindQueue(config *WriteBehindConfig, flushFn func(ctx context.Context, ops []*writeOp) error) *writeBehindQueue {
+	defaults := DefaultWriteBehindConfig()
+	c := *config
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaults.QueueSize
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaults.BatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaults.FlushInterval
+	}
+	if c.Policy == "" {
+		c.Policy = defaults.Policy
+	}
+	if c.FlushTimeout <= 0 {
+		c.FlushTimeout = defaults.FlushTimeout
+	}
+
+	return &writeBehindQueue{
+		config:   &c,
+		flushFn:  flushFn,
+		pending:  make(map[string]*writeOp),
+		inflight: make(map[string]*writeOp),
+		space:    make(chan struct{}),
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// start 启动后台刷新协程
+func (q *writeBehindQueue) start() {
+	go q.run()
+}
+
+func (q *writeBehindQueue) run() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(q.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-q.kick:
+		case <-q.stop:
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), q.config.FlushTimeout)
+		if err := q.Flush(ctx); err != nil {
+			logger.Warn("Write-behind: flush failed, pending=%d, error=%v", q.depth(), err)
+		}
+		cancel()
+	}
+}
+
+// enqueue 写入队列；返回 errWriteBehindFull 时调用方应同步写入
+func (q *writeBehindQueue) enqueue(ctx context.Context, op *writeOp) error {
+	q.mu.Lock()
+	for {
+		if q.closed {
+			q.mu.Unlock()
+			return ErrWriteBehindClosed
+		}
+
+		// 同一 key 已在队列中：原地覆盖，不占用新的位置
+		if existing, ok := q.pending[op.key]; ok {
+			*existing = *op
+			q.mu.Unlock()
+			atomic.AddInt64(&q.enqueued, 1)
+			atomic.AddInt64(&q.coalesced, 1)
+			return nil
+		}
+
+		if len(q.pending) < q.config.QueueSize {
+			q.pending[op.key] = op
+			q.order = append(q.order, op.key)
+			full := len(q.pending) >= q.config.BatchSize
+			q.mu.Unlock()
+			atomic.AddInt64(&q.enqueued, 1)
+			if full {
+				q.signal()
+			}
+			return nil
+		}
+
+		switch q.config.Policy {
+		case WriteBehindDrop:
+			q.mu.Unlock()
+			if op.delete {
+				return errWriteBehindFull
+			}
+			atomic.AddInt64(&q.dropped, 1)
+			logger.Debug("Write-behind: queue full, dropped write for key=%s", op.key)
+			return nil
+		case WriteBehindSync:
+			q.mu.Unlock()
+			return errWriteBehindFull
+		default:
+			wait := q.space
+			q.mu.Unlock()
+			q.signal()
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			q.mu.Lock()
+		}
+	}
+}
+
+// signal 唤醒后台协程立即刷新
+func (q *writeBehindQueue) signal() {
+	select {
+	case q.kick <- struct{}{}:
+	default:
+	}
+}
+
+// lookup 查找尚未写入后端的操作（先查队列，再查正在写入的批次）
+func (q *writeBehindQueue) lookup(key string) (writeOp, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if op, ok := q.pending[key]; ok {
+		return *op, true
+	}
+	if op, ok := q.inflight[key]; ok {
+		return *op, true
+	}
+	return writeOp{}, false
+}
+
+// take 取出最多 n 个操作并标记为写入中
+func (q *writeBehindQueue) take(n int) []*writeOp {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n > len(q.order) {
+		n = len(q.order)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	ops := make([]*writeOp, 0, n)
+	for _, key := range q.order[:n] {
+		op := q.pending[key]
+		delete(q.pending, key)
+		q.inflight[key] = op
+		ops = append(ops, op)
+	}
+	q.order = q.order[n:]
+
+	// 通知阻塞的写入者有空位了
+	close(q.space)
+	q.space = make(chan struct{})
+	return ops
+}
+
+// finish 结束一个批次；失败时把未被新写入覆盖的操作放回队首
+func (q *writeBehindQueue) finish(ops []*writeOp, failed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var requeue []string
+	for _, op := range ops {
+		if q.inflight[op.key] == op {
+			delete(q.inflight, op.key)
+		}
+		if !failed {
+			continue
+		}
+		if _, ok := q.pending[op.key]; ok {
+			continue // 已被更新的写入覆盖
+		}
+		if len(q.pending) >= q.config.QueueSize {
+			atomic.AddInt64(&q.dropped, 1)
+			continue
+		}
+		q.pending[op.key] = op
+		requeue = append(requeue, op.key)
+	}
+	if len(requeue) > 0 {
+		q.order = append(requeue, q.order...)
+	}
+}
+
+// flushBatch 写入一个批次，返回写入的操作数
+func (q *writeBehindQueue) flushBatch(ctx context.Context) (int, error) {
+	ops := q.take(q.config.BatchSize)
+	if len(ops) == 0 {
+		return 0, nil
+	}
+
+	if err := q.flushFn(ctx, ops); err != nil {
+		atomic.AddInt64(&q.errors, 1)
+		q.finish(ops, true)
+		return 0, err
+	}
+	q.finish(ops, false)
+	atomic.AddInt64(&q.flushed, int64(len(ops)))
+
+	for _, fn := range q.onFlushed {
+		fn(ops)
+	}
+	return len(ops), nil
+}
+
+// Flush 写入队列中的所有操作（包括调用期间新入队的）
+func (q *writeBehindQueue) Flush(ctx context.Context) error {
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := q.flushBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+	}
+}
+
+// onFlush 注册批次写入成功后的回调
+func (q *writeBehindQueue) onFlush(fn func(ops []*writeOp)) {
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+	q.onFlushed = append(q.onFlushed, fn)
+}
+
+// depth 当前队列深度
+func (q *writeBehindQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// discard 丢弃所有待写入的操作（等待进行中的批次写完），返回丢弃的操作数
+// 用于 Clear：丢弃后读取不会再命中队列，清空之后也不会再被写回。
+func (q *writeBehindQueue) discard() int {
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.pending) + len(q.inflight)
+	q.pending = make(map[string]*writeOp)
+	q.inflight = make(map[string]*writeOp)
+	q.order = nil
+
+	// 通知阻塞的写入者有空位了
+	close(q.space)
+	q.space = make(chan struct{})
+	return n
+}
+
+// stats 统计快照
+func (q *writeBehindQueue) stats() *WriteBehindStats {
+	return &WriteBehindStats{
+		QueueDepth: int64(q.depth()),
+		Enqueued:   atomic.LoadInt64(&q.enqueued),
+		Coalesced:  atomic.LoadInt64(&q.coalesced),
+		Flushed:    atomic.LoadInt64(&q.flushed),
+		Dropped:    atomic.LoadInt64(&q.dropped),
+		Errors:     atomic.LoadInt64(&q.errors),
+	}
+}
+
+// close 拒绝新的写入，停止后台协程并刷新剩余操作
+func (q *writeBehindQueue) close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	// 唤醒阻塞的写入者，让它们看到 closed
+	close(q.space)
+	q.space = make(chan struct{})
+	q.mu.Unlock()
+
+	close(q.stop)
+	<-q.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), q.config.FlushTimeout)
+	defer cancel()
+	return q.Flush(ctx)
+}
@@ -0,0 +1,235 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingFlusher 记录每个批次的测试 flushFn
+type recordingFlusher struct {
+	mu      sync.Mutex
+	batches [][]writeOp
+	err     error
+}
+
+func (f *recordingFlusher) flush(ctx context.Context, ops []*writeOp) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	batch := make([]writeOp, len(ops))
+	for i, op := range ops {
+		batch[i] = *op
+	}
+	f.batches = append(f.batches, batch)
+	return nil
+}
+
+func (f *recordingFlusher) written() map[string]writeOp {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make(map[string]writeOp)
+	for _, batch := range f.batches {
+		for _, op := range batch {
+			result[op.key] = op
+		}
+	}
+	return result
+}
+
+// newTestQueue 创建不启动后台协程的队列（由测试显式 Flush）
+func newTestQueue(config *WriteBehindConfig) (*writeBehindQueue, *recordingFlusher) {
+	f := &recordingFlusher{}
+	config.Enabled = true
+	config.FlushInterval = time.Hour
+	return newWriteBehindQueue(config, f.flush), f
+}
+
+func TestWriteBehind_CoalesceAndFlush(t *testing.T) {
+	q, f := newTestQueue(&WriteBehindConfig{BatchSize: 2})
+	ctx := context.Background()
+
+	q.enqueue(ctx, &writeOp{key: "a", data: []byte(`1`)})
+	q.enqueue(ctx, &writeOp{key: "b", data: []byte(`2`)})
+	q.enqueue(ctx, &writeOp{key: "a", data: []byte(`3`)})
+	q.enqueue(ctx, &writeOp{key: "c", delete: true})
+
+	stats := q.stats()
+	if stats.QueueDepth != 3 || stats.Coalesced != 1 || stats.Enqueued != 4 {
+		t.Fatalf("Unexpected stats before flush: %+v", stats)
+	}
+
+	// 读取尚未写入的值
+	if op, ok := q.lookup("a"); !ok || string(op.data) != "3" {
+		t.Errorf("Expected pending write 3 for key a, got %+v", op)
+	}
+
+	if err := q.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(f.batches) != 2 {
+		t.Errorf("Expected 2 batches of size 2, got %d", len(f.batches))
+	}
+	written := f.written()
+	if string(written["a"].data) != "3" || !written["c"].delete {
+		t.Errorf("Unexpected written ops: %+v", written)
+	}
+	if _, ok := q.lookup("a"); ok {
+		t.Error("Expected no pending write after flush")
+	}
+	if stats := q.stats(); stats.QueueDepth != 0 || stats.Flushed != 3 {
+		t.Errorf("Unexpected stats after flush: %+v", stats)
+	}
+}
+
+func TestWriteBehind_OverflowPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Drop", func(t *testing.T) {
+		q, _ := newTestQueue(&WriteBehindConfig{QueueSize: 1, Policy: WriteBehindDrop})
+		q.enqueue(ctx, &writeOp{key: "a"})
+		if err := q.enqueue(ctx, &writeOp{key: "b"}); err != nil {
+			t.Errorf("Expected dropped write to return nil, got %v", err)
+		}
+		if dropped := q.stats().Dropped; dropped != 1 {
+			t.Errorf("Expected 1 dropped write, got %d", dropped)
+		}
+		// 删除不能被丢弃
+		if err := q.enqueue(ctx, &writeOp{key: "b", delete: true}); err != errWriteBehindFull {
+			t.Errorf("Expected delete to fall back to sync write, got %v", err)
+		}
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		q, _ := newTestQueue(&WriteBehindConfig{QueueSize: 1, Policy: WriteBehindSync})
+		q.enqueue(ctx, &writeOp{key: "a"})
+		if err := q.enqueue(ctx, &writeOp{key: "b"}); err != errWriteBehindFull {
+			t.Errorf("Expected errWriteBehindFull, got %v", err)
+		}
+		// 同一 key 仍然合并
+		if err := q.enqueue(ctx, &writeOp{key: "a"}); err != nil {
+			t.Errorf("Expected coalesced write, got %v", err)
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		q, f := newTestQueue(&WriteBehindConfig{QueueSize: 1, Policy: WriteBehindBlock})
+		q.enqueue(ctx, &writeOp{key: "a"})
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		if err := q.enqueue(timeoutCtx, &writeOp{key: "b"}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+
+		// 刷新腾出空位后阻塞的写入继续
+		done := make(chan error, 1)
+		go func() { done <- q.enqueue(ctx, &writeOp{key: "c"}) }()
+		time.Sleep(10 * time.Millisecond)
+		q.Flush(ctx)
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Expected blocked write to succeed, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Blocked write was not released")
+		}
+		q.Flush(ctx)
+		if _, ok := f.written()["c"]; !ok {
+			t.Error("Expected key c to be written")
+		}
+	})
+}
+
+func TestWriteBehind_FailedBatchRequeued(t *testing.T) {
+	q, f := newTestQueue(&WriteBehindConfig{})
+	ctx := context.Background()
+
+	f.err = errors.New("connection refused")
+	q.enqueue(ctx, &writeOp{key: "a", data: []byte(`1`)})
+	if err := q.Flush(ctx); err == nil {
+		t.Fatal("Expected flush error")
+	}
+	if stats := q.stats(); stats.QueueDepth != 1 || stats.Errors != 1 {
+		t.Errorf("Expected failed write to be requeued, got %+v", stats)
+	}
+
+	f.err = nil
+	if err := q.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if _, ok := f.written()["a"]; !ok {
+		t.Error("Expected requeued write to be flushed")
+	}
+}
+
+func TestWriteBehind_Discard(t *testing.T) {
+	q, f := newTestQueue(&WriteBehindConfig{QueueSize: 1, Policy: WriteBehindBlock})
+	ctx := context.Background()
+
+	q.enqueue(ctx, &writeOp{key: "a", data: []byte(`1`)})
+	if n := q.discard(); n != 1 {
+		t.Errorf("Expected 1 discarded op, got %d", n)
+	}
+	if _, ok := q.lookup("a"); ok {
+		t.Error("Expected discarded op to be invisible")
+	}
+
+	// 丢弃后腾出空间，新写入不会阻塞
+	if err := q.enqueue(ctx, &writeOp{key: "b", data: []byte(`2`)}); err != nil {
+		t.Fatalf("Enqueue after discard failed: %v", err)
+	}
+	q.Flush(ctx)
+	written := f.written()
+	if _, ok := written["a"]; ok || len(written) != 1 {
+		t.Errorf("Expected only b to be written, got %v", written)
+	}
+}
+
+func TestWriteBehind_CloseFlushes(t *testing.T) {
+	f := &recordingFlusher{}
+	q := newWriteBehindQueue(&WriteBehindConfig{Enabled: true, FlushInterval: time.Hour}, f.flush)
+	q.start()
+
+	var flushedKeys []string
+	q.onFlush(func(ops []*writeOp) {
+		for _, op := range ops {
+			flushedKeys = append(flushedKeys, op.key)
+		}
+	})
+
+	ctx := context.Background()
+	q.enqueue(ctx, &writeOp{key: "a"})
+	if err := q.close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(flushedKeys) != 1 || flushedKeys[0] != "a" {
+		t.Errorf("Expected pending write flushed on close, got %v", flushedKeys)
+	}
+	if err := q.enqueue(ctx, &writeOp{key: "b"}); err != ErrWriteBehindClosed {
+		t.Errorf("Expected ErrWriteBehindClosed, got %v", err)
+	}
+}
+
+func TestWriteBehind_BackgroundFlush(t *testing.T) {
+	f := &recordingFlusher{}
+	q := newWriteBehindQueue(&WriteBehindConfig{Enabled: true, FlushInterval: 10 * time.Millisecond}, f.flush)
+	q.start()
+	defer q.close()
+
+	q.enqueue(context.Background(), &writeOp{key: "a"})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := f.written()["a"]; ok {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("Expected background flush within interval")
+}
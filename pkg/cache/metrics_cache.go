@@ -72,6 +72,15 @@ var (
 		},
 		[]string{"cache_name"},
 	)
+
+	// cacheMemoryBytes 缓存内存占用估算
+	cacheMemoryBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "go_cache_memory_bytes",
+			Help: "Estimated memory used by the cache in bytes",
+		},
+		[]string{"cache_name"},
+	)
 )
 
 // init 注册 Prometheus 指标
@@ -83,6 +92,7 @@ func init() {
 	prometheus.MustRegister(cacheErrors)
 	prometheus.MustRegister(cacheOperationDuration)
 	prometheus.MustRegister(cacheSize)
+	prometheus.MustRegister(cacheMemoryBytes)
 }
 
 // MetricsCache 带 Prometheus 指标的缓存包装器
@@ -166,6 +176,7 @@ func (m *MetricsCache) Stats() *backend.CacheStats {
 	
 	// 更新缓存大小指标
 	cacheSize.WithLabelValues(m.cacheName).Set(float64(stats.Size))
+	cacheMemoryBytes.WithLabelValues(m.cacheName).Set(float64(stats.MemoryBytes))
 	
 	return stats
 }
@@ -299,6 +310,7 @@ func (m *MetricsCacheWithConfig) Close() error {
 func (m *MetricsCacheWithConfig) Stats() *backend.CacheStats {
 	stats := m.backend.Stats()
 	cacheSize.WithLabelValues(m.name).Set(float64(stats.Size))
+	cacheMemoryBytes.WithLabelValues(m.name).Set(float64(stats.MemoryBytes))
 	return stats
 }
 
@@ -312,6 +324,7 @@ func UnregisterMetrics(cacheName string) {
 	cacheErrors.DeleteLabelValues(cacheName, "set")
 	cacheErrors.DeleteLabelValues(cacheName, "delete")
 	cacheSize.DeleteLabelValues(cacheName)
+	cacheMemoryBytes.DeleteLabelValues(cacheName)
 }
 
 // GetAllMetrics 获取所有指标的当前值（用于调试）
@@ -323,5 +336,6 @@ func GetAllMetrics(cacheName string) map[string]interface{} {
 		"deletes":  cacheDeletes.WithLabelValues(cacheName),
 		"errors":   cacheErrors.WithLabelValues(cacheName, "get"),
 		"size":     cacheSize.WithLabelValues(cacheName),
+		"memory":   cacheMemoryBytes.WithLabelValues(cacheName),
 	}
 }
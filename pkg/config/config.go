@@ -2,14 +2,17 @@ package config
 
 import (
 	"os"
+	"path"
+	"strconv"
 	"time"
 
+	"github.com/coderiser/go-cache/pkg/core"
 	"gopkg.in/yaml.v3"
 )
 
 // CacheConfig 单个缓存配置
 type CacheConfig struct {
-	Backend    string        `yaml:"backend"`              // 后端类型：memory, redis
+	Backend    string        `yaml:"backend"`              // 后端类型：memory, redis（为空时按 backend_rules、default_backend 选择）
 	Addr       string        `yaml:"addr"`                 // Redis 地址
 	Password   string        `yaml:"password"`             // Redis 密码
 	DB         int           `yaml:"db"`                   // Redis 数据库
@@ -17,33 +20,109 @@ type CacheConfig struct {
 	DefaultTTL time.Duration `yaml:"default_ttl"`          // 默认 TTL
 	MaxTTL     time.Duration `yaml:"max_ttl"`              // 最大 TTL
 	Prefix     string        `yaml:"prefix"`               // Key 前缀
+
+	Options map[string]string `yaml:"options"` // 其他后端专属选项（如 tiered 的 tiers、redis-cluster 的 addrs）
+
+	Bulkhead       *BulkheadConfig       `yaml:"bulkhead"`        // 回源并发限制
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker"` // L2 熔断器（hybrid 后端），设置后启用
 }
 
-// Config 根配置
-type Config struct {
-	Caches map[string]*CacheConfig `yaml:"caches"` // 缓存配置映射
+// CircuitBreakerConfig 熔断器配置，未设置的字段使用默认值
+//
+//	caches:
+//	  users:
+//	    backend: hybrid
+//	    circuit_breaker:
+//	      error_rate_threshold: 0.5
+//	      slow_call_threshold: 200ms
+//	      open_timeout: 5s
+type CircuitBreakerConfig struct {
+	Window                time.Duration `yaml:"window"`                   // 统计窗口
+	MinRequests           int64         `yaml:"min_requests"`             // 窗口内最少请求数
+	ErrorRateThreshold    float64       `yaml:"error_rate_threshold"`     // 错误率阈值（0~1）
+	SlowCallThreshold     time.Duration `yaml:"slow_call_threshold"`      // 慢调用阈值
+	SlowCallRateThreshold float64       `yaml:"slow_call_rate_threshold"` // 慢调用率阈值（0~1）
+	OpenTimeout           time.Duration `yaml:"open_timeout"`             // 打开后多久进入半开
+	HalfOpenMaxCalls      int64         `yaml:"half_open_max_calls"`      // 半开状态下的探测请求数
+	CallTimeout           time.Duration `yaml:"call_timeout"`             // 单次调用超时
 }
 
-// Load 从 YAML 文件加载配置
-func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// options 转换为后端选项（"circuit_breaker" 与 "circuit_breaker.*"）
+func (c *CircuitBreakerConfig) options(opts map[string]string) {
+	opts["circuit_breaker"] = "true"
+	durations := map[string]time.Duration{
+		"window":              c.Window,
+		"slow_call_threshold": c.SlowCallThreshold,
+		"open_timeout":        c.OpenTimeout,
+		"call_timeout":        c.CallTimeout,
 	}
-
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+	for name, d := range durations {
+		if d > 0 {
+			opts["circuit_breaker."+name] = d.String()
+		}
+	}
+	rates := map[string]float64{
+		"error_rate_threshold":     c.ErrorRateThreshold,
+		"slow_call_rate_threshold": c.SlowCallRateThreshold,
+	}
+	for name, f := range rates {
+		if f > 0 {
+			opts["circuit_breaker."+name] = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	counts := map[string]int64{
+		"min_requests":        c.MinRequests,
+		"half_open_max_calls": c.HalfOpenMaxCalls,
 	}
+	for name, n := range counts {
+		if n > 0 {
+			opts["circuit_breaker."+name] = strconv.FormatInt(n, 10)
+		}
+	}
+}
+
+// BulkheadConfig 回源并发限制配置
+//
+//	caches:
+//	  orders:
+//	    bulkhead:
+//	      max_concurrent: 20
+//	      queue_timeout: 200ms
+type BulkheadConfig struct {
+	MaxConcurrent int           `yaml:"max_concurrent"` // 同时执行的回源数上限
+	QueueTimeout  time.Duration `yaml:"queue_timeout"`  // 排队等待上限（0 表示不排队）
+}
 
-	// 应用默认值
-	for name, cache := range cfg.Caches {
+// BackendRule 按缓存名通配选择后端
+//
+//	default_backend: memory
+//	backend_rules:
+//	  - pattern: "session:*"
+//	    backend: redis
+type BackendRule struct {
+	Pattern string `yaml:"pattern"` // 缓存名通配（path.Match 语法）
+	Backend string `yaml:"backend"` // 后端名称
+}
+
+// Config 根配置
+type Config struct {
+	Caches         map[string]*CacheConfig `yaml:"caches"`          // 缓存配置映射
+	DefaultBackend string                  `yaml:"default_backend"` // 默认后端（为空时为 memory）
+	BackendRules   []BackendRule           `yaml:"backend_rules"`   // 后端通配规则，按顺序匹配
+}
+
+// applyDefaults 应用默认值；未指定后端的缓存按通配规则、默认后端选择
+func (c *Config) applyDefaults() {
+	if c.DefaultBackend == "" {
+		c.DefaultBackend = "memory"
+	}
+	for name, cache := range c.Caches {
 		if cache == nil {
 			cache = &CacheConfig{}
-			cfg.Caches[name] = cache
+			c.Caches[name] = cache
 		}
 		if cache.Backend == "" {
-			cache.Backend = "memory"
+			cache.Backend = c.backendFor(name)
 		}
 		if cache.DefaultTTL == 0 {
 			cache.DefaultTTL = 30 * time.Minute
@@ -54,8 +133,105 @@ func Load(path string) (*Config, error) {
 		if cache.Backend == "memory" && cache.MaxSize == 0 {
 			cache.MaxSize = 10000
 		}
-		_ = name // 可用于日志
 	}
+}
+
+// backendFor 返回第一条匹配规则的后端，没有匹配时返回默认后端
+func (c *Config) backendFor(name string) string {
+	for _, rule := range c.BackendRules {
+		if ok, _ := path.Match(rule.Pattern, name); ok {
+			return rule.Backend
+		}
+	}
+	return c.DefaultBackend
+}
+
+// ApplyCaches 将后端选择规则和各缓存配置注册到缓存管理器
+// 规则引用的自定义后端需要先通过 RegisterBackend 注册。
+func (c *Config) ApplyCaches(m core.CacheManager) error {
+	if c.DefaultBackend != "" {
+		if err := m.SetDefaultBackend(c.DefaultBackend); err != nil {
+			return err
+		}
+	}
+	for _, rule := range c.BackendRules {
+		if err := m.AddBackendRule(rule.Pattern, rule.Backend); err != nil {
+			return err
+		}
+	}
+	for name, cache := range c.Caches {
+		if err := m.RegisterCacheConfig(name, cache.cacheConfig(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheConfig 转换为后端配置，Redis 连接字段合并到 Options
+func (c *CacheConfig) cacheConfig(name string) *core.CacheConfig {
+	cfg := core.DefaultCacheConfig(name)
+	cfg.Backend = c.Backend
+	if c.MaxSize > 0 {
+		cfg.MaxSize = c.MaxSize
+	}
+	if c.DefaultTTL > 0 {
+		cfg.DefaultTTL = c.DefaultTTL
+	}
+	if c.MaxTTL > 0 {
+		cfg.MaxTTL = c.MaxTTL
+	}
+
+	cfg.Options = make(map[string]string, len(c.Options)+4)
+	for k, v := range c.Options {
+		cfg.Options[k] = v
+	}
+	if c.Addr != "" {
+		cfg.Options["addr"] = c.Addr
+	}
+	if c.Password != "" {
+		cfg.Options["password"] = c.Password
+	}
+	if c.DB != 0 {
+		cfg.Options["db"] = strconv.Itoa(c.DB)
+	}
+	if c.Prefix != "" {
+		cfg.Options["prefix"] = c.Prefix
+	}
+	if c.CircuitBreaker != nil {
+		c.CircuitBreaker.options(cfg.Options)
+	}
+	return cfg
+}
+
+// ApplyProtection 将各缓存的回源并发限制写入保护配置
+func (c *Config) ApplyProtection(p *core.ProtectionConfig) {
+	for name, cache := range c.Caches {
+		if cache == nil || cache.Bulkhead == nil || cache.Bulkhead.MaxConcurrent <= 0 {
+			continue
+		}
+		if p.Bulkheads == nil {
+			p.Bulkheads = make(map[string]*core.BulkheadConfig)
+		}
+		p.Bulkheads[name] = &core.BulkheadConfig{
+			MaxConcurrent: cache.Bulkhead.MaxConcurrent,
+			QueueTimeout:  cache.Bulkhead.QueueTimeout,
+		}
+	}
+}
+
+// Load 从 YAML 文件加载配置
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.applyDefaults()
 
 	return &cfg, nil
 }
@@ -67,26 +243,7 @@ func LoadFromString(data string) (*Config, error) {
 		return nil, err
 	}
 
-	// 应用默认值
-	for name, cache := range cfg.Caches {
-		if cache == nil {
-			cache = &CacheConfig{}
-			cfg.Caches[name] = cache
-		}
-		if cache.Backend == "" {
-			cache.Backend = "memory"
-		}
-		if cache.DefaultTTL == 0 {
-			cache.DefaultTTL = 30 * time.Minute
-		}
-		if cache.MaxTTL == 0 {
-			cache.MaxTTL = 24 * time.Hour
-		}
-		if cache.Backend == "memory" && cache.MaxSize == 0 {
-			cache.MaxSize = 10000
-		}
-		_ = name
-	}
+	cfg.applyDefaults()
 
 	return &cfg, nil
 }
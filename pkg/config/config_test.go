@@ -4,6 +4,9 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"github.com/coderiser/go-cache/pkg/backend"
+	"github.com/coderiser/go-cache/pkg/core"
 )
 
 func TestLoadFromString(t *testing.T) {
@@ -139,3 +142,142 @@ func TestLoadNonExistent(t *testing.T) {
 		t.Error("Expected error for non-existent file")
 	}
 }
+
+func TestApplyProtection(t *testing.T) {
+	cfg, err := LoadFromString(`
+caches:
+  orders:
+    bulkhead:
+      max_concurrent: 20
+      queue_timeout: 200ms
+  users:
+    default_ttl: 1h
+`)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	protection := core.DefaultProtectionConfig()
+	cfg.ApplyProtection(protection)
+
+	orders := protection.Bulkheads["orders"]
+	if orders == nil || orders.MaxConcurrent != 20 || orders.QueueTimeout != 200*time.Millisecond {
+		t.Errorf("Unexpected orders bulkhead: %+v", orders)
+	}
+	if _, ok := protection.Bulkheads["users"]; ok {
+		t.Error("Expected no bulkhead for users")
+	}
+}
+
+func TestBackendRules(t *testing.T) {
+	cfg, err := LoadFromString(`
+default_backend: local
+backend_rules:
+  - pattern: "session:*"
+    backend: redis
+caches:
+  session:web:
+    addr: redis:6380
+    db: 2
+    options:
+      pool_size: "32"
+  users:
+  orders:
+    backend: memory
+`)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	for name, want := range map[string]string{"session:web": "redis", "users": "local", "orders": "memory"} {
+		if got := cfg.Caches[name].Backend; got != want {
+			t.Errorf("Expected %s backend to be %s, got %s", name, want, got)
+		}
+	}
+
+	session := cfg.Caches["session:web"].cacheConfig("session:web")
+	want := map[string]string{"addr": "redis:6380", "db": "2", "pool_size": "32"}
+	for k, v := range want {
+		if session.Options[k] != v {
+			t.Errorf("Expected option %s=%s, got %q", k, v, session.Options[k])
+		}
+	}
+}
+
+func TestApplyCaches(t *testing.T) {
+	cfg, err := LoadFromString(`
+backend_rules:
+  - pattern: "local:*"
+    backend: local
+caches:
+  users:
+    max_size: 500
+    default_ttl: 1h
+`)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	manager := core.NewCacheManager()
+	defer manager.Close()
+	var created []string
+	manager.RegisterBackend("local", func(c *core.CacheConfig) (core.CacheBackend, error) {
+		created = append(created, c.Name)
+		return backend.NewMemoryBackend(c)
+	})
+	if err := cfg.ApplyCaches(manager); err != nil {
+		t.Fatalf("ApplyCaches failed: %v", err)
+	}
+
+	manager.GetCache("users")
+	info, err := manager.CacheInfo("users")
+	if err != nil {
+		t.Fatalf("CacheInfo failed: %v", err)
+	}
+	if info.Backend != "memory" || info.Config.MaxSize != 500 || info.Config.DefaultTTL != time.Hour {
+		t.Errorf("Unexpected users cache: backend=%s config=%+v", info.Backend, info.Config)
+	}
+
+	// 未在配置中列出的缓存也按规则选择后端
+	manager.GetCache("local:tokens")
+	if len(created) != 1 || created[0] != "local:tokens" {
+		t.Errorf("Expected local:tokens on local backend, got %v", created)
+	}
+}
+
+func TestCircuitBreakerConfig(t *testing.T) {
+	cfg, err := LoadFromString(`
+caches:
+  users:
+    backend: hybrid
+    circuit_breaker:
+      error_rate_threshold: 0.25
+      min_requests: 10
+      open_timeout: 2s
+  orders:
+    backend: hybrid
+`)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	users := cfg.Caches["users"].cacheConfig("users")
+	want := map[string]string{
+		"circuit_breaker":                      "true",
+		"circuit_breaker.error_rate_threshold": "0.25",
+		"circuit_breaker.min_requests":         "10",
+		"circuit_breaker.open_timeout":         "2s",
+	}
+	for k, v := range want {
+		if users.Options[k] != v {
+			t.Errorf("Expected option %s=%s, got %q", k, v, users.Options[k])
+		}
+	}
+	// 未设置的阈值不写入选项，由后端使用默认值
+	if _, ok := users.Options["circuit_breaker.window"]; ok {
+		t.Error("Expected unset window to be omitted")
+	}
+	if orders := cfg.Caches["orders"].cacheConfig("orders"); orders.Options["circuit_breaker"] != "" {
+		t.Error("Expected no circuit breaker without config")
+	}
+}
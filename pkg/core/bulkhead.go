@@ -0,0 +1,195 @@
+package core
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrLoaderSaturated 缓存的回源并发已满且排队超时
+var ErrLoaderSaturated = errors.New("cache loader saturated")
+
+// BulkheadConfig 单个缓存的回源并发限制
+// singleflight 只合并相同 key，大量不同 key 同时过期时仍会同时回源；bulkhead 限制整个缓存的回源并发。
+type BulkheadConfig struct {
+	MaxConcurrent int           // 同时执行的回源数上限（0 表示不限制）
+	QueueTimeout  time.Duration // 排队等待上限（0 表示不排队，并发已满时立即拒绝）
+}
+
+// WithPriority 设置回源排队优先级（数字越大越先获得名额，默认 0）
+func WithPriority(priority int) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.priority = priority }
+}
+
+// SetBulkhead 设置缓存的回源并发限制（覆盖 ProtectionConfig.Bulkheads），nil 表示不限制
+// 已经在排队或执行的回源仍使用旧的限制。
+func (p *CacheProtection) SetBulkhead(cacheName string, config *BulkheadConfig) {
+	p.bulkheads.Store(cacheName, newBulkhead(config))
+}
+
+// bulkhead 返回当前缓存的并发限制器（不限制时返回 nil）
+func (p *CacheProtection) bulkhead() *bulkhead {
+	if b, ok := p.bulkheads.Load(p.cacheName); ok {
+		return b.(*bulkhead)
+	}
+	b, _ := p.bulkheads.LoadOrStore(p.cacheName, newBulkhead(p.config.Bulkheads[p.cacheName]))
+	return b.(*bulkhead)
+}
+
+// reconfigureBulkheads 按新配置重建配置有变化的缓存的并发限制
+// 配置未变化的缓存保留原有限制器（包括 SetBulkhead 的覆盖和已占用的名额）。
+func reconfigureBulkheads(bulkheads *sync.Map, old, updated map[string]*BulkheadConfig) {
+	for name, config := range updated {
+		if !sameBulkheadConfig(old[name], config) {
+			bulkheads.Store(name, newBulkhead(config))
+		}
+	}
+	for name := range old {
+		if _, ok := updated[name]; !ok {
+			bulkheads.Delete(name)
+		}
+	}
+}
+
+// sameBulkheadConfig 判断两个并发限制配置是否相同
+func sameBulkheadConfig(a, b *BulkheadConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// loadLimited 在缓存的回源并发限制内执行回源
+func (p *CacheProtection) loadLimited(ctx context.Context, loader func(context.Context) (interface{}, error), options *protectedGetOptions) (interface{}, error) {
+	b := p.bulkhead()
+	if b == nil {
+		return p.load(ctx, loader, options)
+	}
+
+	wait, err := b.acquire(ctx, options.priority)
+	p.stats.recordQueueWait(p.cacheName, wait)
+	if err != nil {
+		if errors.Is(err, ErrLoaderSaturated) {
+			p.stats.record(p.cacheName, EventLoaderSaturated)
+			return nil, fmt.Errorf("%w: cache %q (max %d concurrent loads)", err, p.cacheName, b.config.MaxConcurrent)
+		}
+		return nil, err
+	}
+	defer b.release()
+	return p.load(ctx, loader, options)
+}
+
+// bulkhead 带优先级排队的信号量
+type bulkhead struct {
+	config BulkheadConfig
+
+	mu      sync.Mutex
+	inUse   int
+	waiters bulkheadQueue
+	seq     uint64
+}
+
+func newBulkhead(config *BulkheadConfig) *bulkhead {
+	if config == nil || config.MaxConcurrent <= 0 {
+		return nil
+	}
+	return &bulkhead{config: *config}
+}
+
+// acquire 获取一个名额，返回排队时间
+func (b *bulkhead) acquire(ctx context.Context, priority int) (time.Duration, error) {
+	start := time.Now()
+
+	b.mu.Lock()
+	if b.inUse < b.config.MaxConcurrent && b.waiters.Len() == 0 {
+		b.inUse++
+		b.mu.Unlock()
+		return 0, nil
+	}
+	if b.config.QueueTimeout <= 0 {
+		b.mu.Unlock()
+		return 0, ErrLoaderSaturated
+	}
+	b.seq++
+	w := &bulkheadWaiter{priority: priority, seq: b.seq, ready: make(chan struct{})}
+	heap.Push(&b.waiters, w)
+	b.mu.Unlock()
+
+	timer := time.NewTimer(b.config.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return time.Since(start), nil
+	case <-timer.C:
+		err = ErrLoaderSaturated
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if w.index < 0 {
+		// 超时的同时已经拿到了名额
+		return time.Since(start), nil
+	}
+	heap.Remove(&b.waiters, w.index)
+	return time.Since(start), err
+}
+
+// release 归还名额，有排队者时直接交给优先级最高的
+func (b *bulkhead) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.waiters.Len() > 0 {
+		w := heap.Pop(&b.waiters).(*bulkheadWaiter)
+		close(w.ready)
+		return
+	}
+	b.inUse--
+}
+
+type bulkheadWaiter struct {
+	priority int
+	seq      uint64 // 同优先级先到先得
+	ready    chan struct{}
+	index    int // 在堆中的位置，出堆后为 -1
+}
+
+// bulkheadQueue 排队者的堆（container/heap）
+type bulkheadQueue []*bulkheadWaiter
+
+func (q bulkheadQueue) Len() int { return len(q) }
+
+func (q bulkheadQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q bulkheadQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *bulkheadQueue) Push(x interface{}) {
+	w := x.(*bulkheadWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *bulkheadQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestBulkhead 测试按缓存限制回源并发
+func TestBulkhead(t *testing.T) {
+	ctx := context.Background()
+	miss := func() (interface{}, bool, error) { return nil, false, nil }
+	noSet := func(interface{}, time.Duration) error { return nil }
+
+	t.Run("Reject when saturated", func(t *testing.T) {
+		config := DefaultProtectionConfig()
+		config.Bulkheads = map[string]*BulkheadConfig{"orders": {MaxConcurrent: 1}}
+		protection := NewCacheProtection(config)
+		orders := protection.ForCache("orders")
+
+		started, release := make(chan struct{}), make(chan struct{})
+		go orders.ProtectedGet(ctx, "order:1", miss, func() (interface{}, error) {
+			close(started)
+			<-release
+			return "o1", nil
+		}, noSet)
+		<-started
+
+		_, err := orders.ProtectedGet(ctx, "order:2", miss, func() (interface{}, error) { return "o2", nil }, noSet)
+		if !errors.Is(err, ErrLoaderSaturated) {
+			t.Errorf("Expected ErrLoaderSaturated, got %v", err)
+		}
+		// 其他缓存不受影响
+		if v, err := protection.ForCache("users").ProtectedGet(ctx, "user:1", miss,
+			func() (interface{}, error) { return "u1", nil }, noSet); err != nil || v != "u1" {
+			t.Errorf("Expected unrestricted cache to load, got %v (%v)", v, err)
+		}
+		close(release)
+
+		if stats := orders.GetStats(); stats.LoaderSaturated != 1 || stats.LoaderErrors != 0 {
+			t.Errorf("Expected 1 saturation and no loader errors, got %+v", stats)
+		}
+	})
+
+	t.Run("Queue with priority", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		protection.SetBulkhead("orders", &BulkheadConfig{MaxConcurrent: 1, QueueTimeout: time.Second})
+		orders := protection.ForCache("orders")
+
+		started, release := make(chan struct{}), make(chan struct{})
+		go orders.ProtectedGet(ctx, "first", miss, func() (interface{}, error) {
+			close(started)
+			<-release
+			return "v", nil
+		}, noSet)
+		<-started
+
+		var mu sync.Mutex
+		var order []string
+		var wg sync.WaitGroup
+		enqueue := func(key string, priority int) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				orders.ProtectedGet(ctx, key, miss, func() (interface{}, error) {
+					mu.Lock()
+					order = append(order, key)
+					mu.Unlock()
+					return "v", nil
+				}, noSet, WithPriority(priority))
+			}()
+			time.Sleep(10 * time.Millisecond)
+		}
+		enqueue("low", 0)
+		enqueue("high", 10)
+		close(release)
+		wg.Wait()
+
+		if len(order) != 2 || order[0] != "high" {
+			t.Errorf("Expected high priority loader first, got %v", order)
+		}
+	})
+
+	t.Run("Kept across SetProtectionConfig", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+		manager.GetProtection().SetBulkhead("orders", &BulkheadConfig{MaxConcurrent: 1})
+
+		var loads int32
+		started, release := make(chan struct{}), make(chan struct{})
+		loader := func() (interface{}, error) {
+			if atomic.AddInt32(&loads, 1) == 1 {
+				close(started)
+				<-release
+			}
+			return "o1", nil
+		}
+		go manager.GetProtection().ForCache("orders").ProtectedGet(ctx, "order:1", miss, loader, noSet)
+		<-started
+
+		config := DefaultProtectionConfig()
+		config.Bulkheads = map[string]*BulkheadConfig{"users": {MaxConcurrent: 1}}
+		manager.SetProtectionConfig(config)
+		orders := manager.GetProtection().ForCache("orders")
+
+		// 重新配置后仍加入进行中的回源
+		joined := make(chan interface{})
+		go func() {
+			v, _ := orders.ProtectedGet(ctx, "order:1", miss, loader, noSet)
+			joined <- v
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		// SetBulkhead 的覆盖和已占用的名额保留
+		if _, err := orders.ProtectedGet(ctx, "order:2", miss, loader, noSet); !errors.Is(err, ErrLoaderSaturated) {
+			t.Errorf("Expected ErrLoaderSaturated after reconfiguration, got %v", err)
+		}
+		close(release)
+		if v := <-joined; v != "o1" || atomic.LoadInt32(&loads) != 1 {
+			t.Errorf("Expected to join in-flight load, got %v (%d loads)", v, loads)
+		}
+
+		// 新配置中的限制生效
+		if b := manager.GetProtection().ForCache("users").bulkhead(); b == nil || b.config.MaxConcurrent != 1 {
+			t.Errorf("Expected users bulkhead from new config, got %+v", b)
+		}
+		config = DefaultProtectionConfig()
+		manager.SetProtectionConfig(config)
+		if b := manager.GetProtection().ForCache("users").bulkhead(); b != nil {
+			t.Errorf("Expected users bulkhead removed with config, got %+v", b)
+		}
+	})
+
+	t.Run("Queue timeout serves stale", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		protection.SetBulkhead("orders", &BulkheadConfig{MaxConcurrent: 1, QueueTimeout: 10 * time.Millisecond})
+		orders := protection.ForCache("orders")
+
+		store := &staleStore{}
+		opts := []ProtectedGetOption{WithTTL(time.Minute), WithStaleIfError(time.Millisecond)}
+		orders.ProtectedGet(ctx, "order:1", store.get, func() (interface{}, error) { return "old", nil }, store.set, opts...)
+		store.age(time.Hour)
+
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+		go orders.ProtectedGet(ctx, "order:2", miss, func() (interface{}, error) {
+			close(started)
+			<-release
+			return "v", nil
+		}, noSet)
+		<-started
+
+		// 宽限期已过，但回源被拒绝时仍返回旧值
+		v, err := orders.ProtectedGet(ctx, "order:1", store.get, func() (interface{}, error) { return "new", nil }, store.set, opts...)
+		if err != nil || v != "old" {
+			t.Errorf("Expected stale value when saturated, got %v (%v)", v, err)
+		}
+	})
+}
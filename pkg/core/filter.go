@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/coderiser/go-cache/pkg/backend"
+)
+
+// ExistenceFilter 存在性过滤器（布隆过滤器等）
+type ExistenceFilter = backend.ExistenceFilter
+
+// FilterBootstrap 启动时向过滤器灌入所有已存在的 key（如遍历数据库主键）
+type FilterBootstrap func(ctx context.Context, add func(key string) error) error
+
+// SetFilter 为缓存挂载存在性过滤器
+// 未命中时过滤器判定不存在的 key 直接返回 nil，不调用回源函数，也不写入空值标记；
+// 回源得到非 nil 值时自动加入过滤器。bootstrap 不为 nil 时先灌入数据，成功后才生效；
+// filter 为 nil 时移除过滤器。
+func (p *CacheProtection) SetFilter(ctx context.Context, cacheName string, filter ExistenceFilter, bootstrap FilterBootstrap) error {
+	if filter == nil {
+		p.filters.Delete(cacheName)
+		return nil
+	}
+	if bootstrap != nil {
+		add := func(key string) error { return filter.Add(ctx, key) }
+		if err := bootstrap(ctx, add); err != nil {
+			return fmt.Errorf("filter bootstrap for cache %q failed: %w", cacheName, err)
+		}
+	}
+	p.filters.Store(cacheName, filter)
+	return nil
+}
+
+// WithFilterKey 设置过滤器检查和加入时使用的 key（默认与缓存 key 相同）
+// 缓存 key 带有过滤器之外的前缀时使用（如多租户前缀），过滤器仍按业务 key 灌入和判断。
+func WithFilterKey(key string) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.filterKey = key }
+}
+
+// filterKeyFor 返回过滤器使用的 key
+func (o *protectedGetOptions) filterKeyFor(key string) string {
+	if o.filterKey != "" {
+		return o.filterKey
+	}
+	return key
+}
+
+// Filter 返回当前缓存的存在性过滤器（没有时返回 nil）
+func (p *CacheProtection) Filter() ExistenceFilter {
+	if filter, ok := p.filters.Load(p.cacheName); ok {
+		return filter.(ExistenceFilter)
+	}
+	return nil
+}
+
+// MarkExists 把 key 加入当前缓存的过滤器（绕过 ProtectedGet 写入缓存时调用，如 @cacheput）
+func (p *CacheProtection) MarkExists(ctx context.Context, key string) {
+	filter := p.Filter()
+	if filter == nil {
+		return
+	}
+	if err := filter.Add(ctx, key); err != nil {
+		log.Printf("[WARN] Failed to add key '%s' to filter of cache '%s': %v", key, p.cacheName, err)
+	}
+}
+
+// mightExist 过滤器是否允许回源；没有过滤器或过滤器出错时放行
+func (p *CacheProtection) mightExist(ctx context.Context, key string) bool {
+	filter := p.Filter()
+	if filter == nil {
+		return true
+	}
+	ok, err := filter.MightContain(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] Filter check for key '%s' in cache '%s' failed, allowing load: %v", key, p.cacheName, err)
+		return true
+	}
+	return ok
+}
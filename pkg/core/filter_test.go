@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/backend"
+)
+
+// failingFilter 总是出错的过滤器
+type failingFilter struct{}
+
+func (failingFilter) Add(ctx context.Context, key string) error { return errors.New("filter down") }
+func (failingFilter) MightContain(ctx context.Context, key string) (bool, error) {
+	return false, errors.New("filter down")
+}
+
+// TestExistenceFilter 测试过滤器拦截不存在的 key
+func TestExistenceFilter(t *testing.T) {
+	ctx := context.Background()
+	protection := NewCacheProtection(nil)
+	filter := backend.NewScalableBloomFilter(nil)
+
+	err := protection.SetFilter(ctx, "users", filter, func(ctx context.Context, add func(string) error) error {
+		return add("user:1")
+	})
+	if err != nil {
+		t.Fatalf("SetFilter failed: %v", err)
+	}
+
+	users := protection.ForCache("users")
+	cache := newTestCache(t)
+	calls := 0
+	loader := func(ctx context.Context) (string, error) {
+		calls++
+		return "alice", nil
+	}
+
+	// 过滤器判定不存在：不回源，不写空值标记
+	v, err := ProtectedGet(ctx, users, cache, "user:404", loader)
+	if err != nil || v != "" || calls != 0 {
+		t.Fatalf("Expected rejection without load, got %q (%v) after %d calls", v, err, calls)
+	}
+	if _, found, _ := cache.Get(ctx, "user:404"); found {
+		t.Error("Expected no nil marker for rejected key")
+	}
+	if stats := users.GetStats(); stats.FilterRejected != 1 {
+		t.Errorf("Expected 1 rejection, got %+v", stats)
+	}
+
+	// 灌入过的 key 正常回源
+	if v, _ := ProtectedGet(ctx, users, cache, "user:1", loader, WithTTL(time.Minute)); v != "alice" || calls != 1 {
+		t.Errorf("Expected bootstrapped key to load, got %q after %d calls", v, calls)
+	}
+
+	// 其他缓存不受影响
+	if v, _ := ProtectedGet(ctx, protection.ForCache("orders"), cache, "order:1", loader); v != "alice" {
+		t.Errorf("Expected cache without filter to load, got %q", v)
+	}
+
+	// 通过 MarkExists 写入的 key 之后可以回源
+	users.MarkExists(ctx, "user:2")
+	if v, _ := ProtectedGet(ctx, users, cache, "user:2", loader); v != "alice" {
+		t.Errorf("Expected marked key to load, got %q", v)
+	}
+}
+
+// TestExistenceFilterFailOpen 测试过滤器出错时放行
+func TestExistenceFilterFailOpen(t *testing.T) {
+	ctx := context.Background()
+	protection := NewCacheProtection(nil)
+	protection.SetFilter(ctx, "users", failingFilter{}, nil)
+
+	v, err := ProtectedGet(ctx, protection.ForCache("users"), newTestCache(t), "user:1",
+		func(ctx context.Context) (string, error) { return "alice", nil })
+	if err != nil || v != "alice" {
+		t.Errorf("Expected load when filter fails, got %q (%v)", v, err)
+	}
+
+	bootstrapErr := errors.New("db down")
+	err = protection.SetFilter(ctx, "orders", backend.NewScalableBloomFilter(nil),
+		func(ctx context.Context, add func(string) error) error { return bootstrapErr })
+	if !errors.Is(err, bootstrapErr) {
+		t.Errorf("Expected bootstrap error, got %v", err)
+	}
+	if protection.ForCache("orders").Filter() != nil {
+		t.Error("Expected filter not to be installed after failed bootstrap")
+	}
+}
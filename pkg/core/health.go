@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/backend"
+)
+
+// HealthChecker 可探测连通性的后端
+type HealthChecker = backend.HealthChecker
+
+// HealthStatus 健康状态
+type HealthStatus string
+
+const (
+	// HealthOK 正常
+	HealthOK HealthStatus = "ok"
+	// HealthDegraded 降级：部分依赖不可用但仍可服务
+	HealthDegraded HealthStatus = "degraded"
+	// HealthDown 不可用
+	HealthDown HealthStatus = "down"
+)
+
+// healthCheckTimeout 单个缓存探测的超时（ctx 没有更早的截止时间时）
+const healthCheckTimeout = 2 * time.Second
+
+// CacheHealth 单个缓存的健康状态
+type CacheHealth struct {
+	Name        string        `json:"name"`
+	Status      HealthStatus  `json:"status"`
+	Probed      bool          `json:"probed"`                  // 后端实现了 HealthChecker；否则视为正常
+	Latency     time.Duration `json:"latency_ns"`              // 本次探测耗时
+	LastError   string        `json:"last_error,omitempty"`    // 最近一次探测失败的错误（恢复后保留）
+	LastErrorAt *time.Time    `json:"last_error_at,omitempty"` // 最近一次探测失败的时间
+}
+
+// HealthReport 缓存子系统健康报告
+type HealthReport struct {
+	Status    HealthStatus  `json:"status"` // 所有缓存中最差的状态
+	CheckedAt time.Time     `json:"checked_at"`
+	Caches    []CacheHealth `json:"caches"` // 按缓存名排序
+}
+
+// healthError 最近一次探测失败
+type healthError struct {
+	message string
+	at      time.Time
+}
+
+// healthTracker 记录各缓存最近一次探测失败
+type healthTracker struct {
+	mu     sync.Mutex
+	errors map[string]healthError
+}
+
+func (t *healthTracker) record(name string, err error, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.errors == nil {
+		t.errors = make(map[string]healthError)
+	}
+	t.errors[name] = healthError{message: err.Error(), at: at}
+}
+
+func (t *healthTracker) last(name string) (healthError, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.errors[name]
+	return e, ok
+}
+
+// Health 并发探测所有已创建或注册的缓存
+//
+// 未实现 HealthChecker 的后端（如内存后端）视为正常；探测返回包装了 backend.ErrDegraded 的错误时为降级，其他错误为不可用。
+func (m *cacheManagerImpl) Health(ctx context.Context) *HealthReport {
+	names := m.ListCaches()
+	report := &HealthReport{Status: HealthOK, CheckedAt: time.Now(), Caches: make([]CacheHealth, len(names))}
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Caches[i] = m.checkCache(ctx, name)
+		}()
+	}
+	wg.Wait()
+
+	for _, h := range report.Caches {
+		report.Status = worseHealth(report.Status, h.Status)
+	}
+	return report
+}
+
+// checkCache 探测单个缓存
+func (m *cacheManagerImpl) checkCache(ctx context.Context, name string) CacheHealth {
+	h := CacheHealth{Name: name, Status: HealthOK}
+
+	m.mu.RLock()
+	c, ok := m.caches[name]
+	m.mu.RUnlock()
+	if !ok {
+		// 探测期间被移除
+		h.Status = HealthDown
+		h.LastError = ErrCacheNotFound.Error()
+		return h
+	}
+
+	if checker, ok := c.(HealthChecker); ok {
+		h.Probed = true
+		pctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		start := time.Now()
+		err := checker.Ping(pctx)
+		h.Latency = time.Since(start)
+		cancel()
+
+		if err != nil {
+			h.Status = HealthDown
+			if errors.Is(err, backend.ErrDegraded) {
+				h.Status = HealthDegraded
+			}
+			m.health.record(name, err, time.Now())
+		}
+	}
+
+	if e, ok := m.health.last(name); ok {
+		h.LastError = e.message
+		at := e.at
+		h.LastErrorAt = &at
+	}
+	return h
+}
+
+// worseHealth 返回两者中较差的状态
+func worseHealth(a, b HealthStatus) HealthStatus {
+	rank := func(s HealthStatus) int {
+		switch s {
+		case HealthDown:
+			return 2
+		case HealthDegraded:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+// NewHealthHandler 以 JSON 输出 CacheManager.Health 的 HTTP 处理器
+//
+// 整体状态为 down 时返回 503，ok 和 degraded 返回 200，可直接用作 Kubernetes readiness 探针。
+func NewHealthHandler(m CacheManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := m.Health(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == HealthDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/backend"
+)
+
+// pingCache 可控制 Ping 结果的缓存
+type pingCache struct {
+	CacheBackend
+	mu  sync.Mutex
+	err error
+}
+
+func (p *pingCache) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *pingCache) setErr(err error) {
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+}
+
+func newPingCache(t *testing.T, name string) *pingCache {
+	t.Helper()
+	c, err := backend.NewMemoryBackend(DefaultCacheConfig(name))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	return &pingCache{CacheBackend: c}
+}
+
+func TestCacheManager_Health(t *testing.T) {
+	ctx := context.Background()
+	manager := NewCacheManager()
+	defer manager.Close()
+
+	manager.GetCache("local")
+	remote := newPingCache(t, "remote")
+	hybrid := newPingCache(t, "hybrid")
+	manager.RegisterCache("remote", remote)
+	manager.RegisterCache("hybrid", hybrid)
+
+	report := manager.Health(ctx)
+	if report.Status != HealthOK || len(report.Caches) != 3 {
+		t.Fatalf("Expected 3 healthy caches, got %+v", report)
+	}
+	byName := func(r *HealthReport) map[string]CacheHealth {
+		m := make(map[string]CacheHealth)
+		for _, h := range r.Caches {
+			m[h.Name] = h
+		}
+		return m
+	}
+	if h := byName(report)["local"]; h.Probed || h.Status != HealthOK {
+		t.Errorf("Expected memory cache to be ok without probing, got %+v", h)
+	}
+	if h := byName(report)["remote"]; !h.Probed || h.LastError != "" {
+		t.Errorf("Expected remote cache to be probed, got %+v", h)
+	}
+
+	// 降级与不可用取最差状态
+	hybrid.setErr(fmt.Errorf("%w: L2 ping failed", backend.ErrDegraded))
+	if report := manager.Health(ctx); report.Status != HealthDegraded {
+		t.Errorf("Expected degraded, got %s", report.Status)
+	}
+	remote.setErr(errors.New("connection refused"))
+	report = manager.Health(ctx)
+	if report.Status != HealthDown {
+		t.Errorf("Expected down, got %s", report.Status)
+	}
+	if h := byName(report)["remote"]; h.Status != HealthDown || h.LastError != "connection refused" || h.LastErrorAt == nil {
+		t.Errorf("Unexpected remote health: %+v", h)
+	}
+
+	// 恢复后保留最近一次错误
+	remote.setErr(nil)
+	hybrid.setErr(nil)
+	report = manager.Health(ctx)
+	if h := byName(report)["remote"]; report.Status != HealthOK || h.Status != HealthOK || h.LastError != "connection refused" {
+		t.Errorf("Expected recovered remote with last error, got %s %+v", report.Status, h)
+	}
+}
+
+// slowPingCache Ping 阻塞到 ctx 结束
+type slowPingCache struct {
+	CacheBackend
+}
+
+func (s *slowPingCache) Ping(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCacheManager_HealthTimeout(t *testing.T) {
+	manager := NewCacheManager()
+	defer manager.Close()
+
+	manager.RegisterCache("slow", &slowPingCache{CacheBackend: newPingCache(t, "slow")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	report := manager.Health(ctx)
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected health check to respect ctx deadline, took %v", time.Since(start))
+	}
+	if report.Status != HealthDown || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Errorf("Expected slow cache to be down, got %+v", report)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	manager := NewCacheManager()
+	defer manager.Close()
+
+	remote := newPingCache(t, "remote")
+	manager.RegisterCache("remote", remote)
+
+	serve := func() (*httptest.ResponseRecorder, HealthReport) {
+		rec := httptest.NewRecorder()
+		NewHealthHandler(manager).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var report HealthReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Invalid JSON response: %v (%s)", err, rec.Body.String())
+		}
+		return rec, report
+	}
+
+	rec, report := serve()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" || report.Status != HealthOK {
+		t.Errorf("Expected 200 ok, got %d %+v", rec.Code, report)
+	}
+
+	remote.setErr(fmt.Errorf("%w: L2 circuit breaker is open", backend.ErrDegraded))
+	if rec, report := serve(); rec.Code != http.StatusOK || report.Status != HealthDegraded {
+		t.Errorf("Expected 200 degraded, got %d %+v", rec.Code, report)
+	}
+
+	remote.setErr(errors.New("connection refused"))
+	rec, report = serve()
+	if rec.Code != http.StatusServiceUnavailable || report.Status != HealthDown || report.Caches[0].LastError != "connection refused" {
+		t.Errorf("Expected 503 down, got %d %+v", rec.Code, report)
+	}
+}
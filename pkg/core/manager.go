@@ -2,9 +2,15 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"path"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coderiser/go-cache/pkg/backend"
@@ -43,41 +49,111 @@ type CacheItem = backend.CacheItem
 type MethodMeta struct {
 	CacheName, KeyExpr, TTLExpr, Condition, Unless, CacheType string
 	Sync, Before bool
+
+	// RefreshAfterExpr 写后刷新间隔（duration 字符串）
+	RefreshAfterExpr string
+	// StaleExpr / StaleIfErrorExpr stale-while-revalidate 窗口和回源失败宽限期（duration 字符串）
+	StaleExpr, StaleIfErrorExpr string
+
+	// MethodName 方法名（#method）；ArgNames 参数名，按位置映射为 #name
+	MethodName string
+	ArgNames   []string
+	// Target 调用目标（#target / #this），可选
+	Target interface{}
 }
 
 // CacheManager 缓存管理器接口
 type CacheManager interface {
 	GetCache(name string) (CacheBackend, error)
+	// RegisterCache 注册缓存实例；同名缓存已存在时替换，被替换的实例会被关闭
+	RegisterCache(name string, cache CacheBackend) error
+	// RegisterCacheConfig 注册缓存配置，GetCache 首次创建该缓存时使用
+	RegisterCacheConfig(name string, cfg *CacheConfig) error
+	// RemoveCache 移除并关闭缓存实例
+	RemoveCache(name string) error
+	// ListCaches 返回已创建或注册的缓存名称（按名称排序）
+	ListCaches() []string
+	// CacheInfo 返回缓存的后端类型、配置和统计
+	CacheInfo(name string) (*CacheInfo, error)
 	RegisterBackend(name string, factory BackendFactory) error
-	Execute(ctx context.Context, meta *MethodMeta, args []reflect.Value) (interface{}, error)
+	// SetDefaultBackend 设置未指定后端、也未匹配规则的缓存使用的后端（默认 memory）
+	SetDefaultBackend(name string) error
+	// AddBackendRule 按缓存名通配规则选择后端，如 "session:*" → redis；按添加顺序匹配
+	AddBackendRule(pattern, backendName string) error
+	// Execute 按注解执行缓存逻辑，invoke 调用原始方法
+	Execute(ctx context.Context, meta *MethodMeta, args []reflect.Value, invoke Invoker) (interface{}, error)
 	Close() error
 	GetProtection() *CacheProtection
 	SetProtectionConfig(config *ProtectionConfig) error
-	// Invalidate 使缓存失效
+	// Invalidate 使缓存失效（设置了失效总线时同时通知其他实例）
 	Invalidate(ctx context.Context, cache string, key string) error
+	// SetInvalidationBus 设置跨实例失效总线（nil 表示只在本地失效）
+	SetInvalidationBus(bus backend.InvalidationBus) error
+	// SetInvalidationRecovery 设置失效总线重连后对进程内缓存的恢复动作（默认清空）
+	SetInvalidationRecovery(action backend.RecoveryAction, prefixes ...string)
+	// Health 探测各缓存后端，返回每个缓存的状态、延迟和最近一次错误
+	Health(ctx context.Context) *HealthReport
+	// SetTenantConfig 启用多租户：按 ctx 解析租户并给 key 加前缀（nil 表示关闭）
+	SetTenantConfig(cfg *TenantConfig) error
+	// FlushTenant 使租户在所有缓存中的数据失效
+	FlushTenant(ctx context.Context, tenant string) error
+}
+
+// ErrCacheNotFound 缓存不存在
+var ErrCacheNotFound = errors.New("cache not found")
+
+// ErrBackendNotFound 缓存选择的后端未注册
+var ErrBackendNotFound = errors.New("backend not registered")
+
+// CacheInfo 缓存实例信息
+type CacheInfo struct {
+	Name        string       // 缓存名称
+	Backend     string       // 创建时选择的后端名称（注册的实例为空）
+	BackendType string       // 后端实现类型（如 *backend.MemoryBackend）
+	Registered  bool         // 通过 RegisterCache 注册（否则由 GetCache 按配置创建）
+	Config      *CacheConfig // 缓存配置（注册的实例没有配置时为 nil）
+	Stats       *CacheStats  // 统计信息
 }
 
 // cacheManagerImpl 实现
 type cacheManagerImpl struct {
 	mu               sync.RWMutex
 	caches           map[string]CacheBackend
+	registered       map[string]bool         // 通过 RegisterCache 注册的缓存
+	backendNames     map[string]string       // 按配置创建的缓存所选的后端
+	attached         map[string]*atomic.Bool // 当前实例的淘汰回调是否生效（实例被替换或移除后置为 false）
 	configs          map[string]*CacheConfig
 	backendFactories map[string]BackendFactory
+	defaultBackend   string
+	backendRules     []backendRule
 	evaluator        *spel.SpELEvaluator
 	defaultConfig    *CacheConfig
 	protection       *CacheProtection
 	protectionConfig *ProtectionConfig
+	instanceID       string
+	bus              backend.InvalidationBus
+	unsubscribeBus   func()
+	recovery         backend.RecoveryAction // 总线重连后的恢复动作
+	recoveryPrefixes []string
+	health           healthTracker
+	tenantConfig     *TenantConfig
+	tenants          tenantState
 }
 
 // NewCacheManager 创建缓存管理器
 func NewCacheManager() CacheManager {
 	m := &cacheManagerImpl{
 		caches:           make(map[string]CacheBackend),
+		registered:       make(map[string]bool),
+		backendNames:     make(map[string]string),
+		attached:         make(map[string]*atomic.Bool),
 		configs:          make(map[string]*CacheConfig),
 		backendFactories: make(map[string]BackendFactory),
+		defaultBackend:   "memory",
 		evaluator:        spel.NewSpELEvaluator(),
 		defaultConfig:    DefaultCacheConfig("default"),
 		protectionConfig: DefaultProtectionConfig(),
+		instanceID:       backend.NewInstanceID(),
 	}
 	m.protection = NewCacheProtection(m.protectionConfig)
 	for n, f := range backend.BackendRegistry {
@@ -107,19 +183,189 @@ func (m *cacheManagerImpl) GetCache(name string) (CacheBackend, error) {
 		m.configs[name] = cfg
 	}
 
-	factory := m.backendFactories["memory"]
+	backendName := m.resolveBackendLocked(name, cfg)
+	factory := m.backendFactories[backendName]
 	if factory == nil {
-		return nil, fmt.Errorf("no memory backend")
+		return nil, fmt.Errorf("%w: %s (cache %s)", ErrBackendNotFound, backendName, name)
 	}
 
 	c, err := factory(cfg)
 	if err != nil {
+		return nil, fmt.Errorf("failed to create cache %s with backend %s: %w", name, backendName, err)
+	}
+	if err := m.checkTenantCacheLocked(name, c); err != nil {
+		c.Close()
 		return nil, err
 	}
-	m.caches[name] = c
+	m.attachLocked(name, c)
+	m.backendNames[name] = backendName
 	return c, nil
 }
 
+// backendRule 缓存名通配规则
+type backendRule struct {
+	pattern string
+	backend string
+}
+
+// resolveBackendLocked 选择缓存后端：配置指定 > 通配规则 > 默认后端
+func (m *cacheManagerImpl) resolveBackendLocked(name string, cfg *CacheConfig) string {
+	if cfg.Backend != "" {
+		return cfg.Backend
+	}
+	for _, rule := range m.backendRules {
+		if ok, _ := path.Match(rule.pattern, name); ok {
+			return rule.backend
+		}
+	}
+	return m.defaultBackend
+}
+
+// SetDefaultBackend 设置默认后端（需先通过 RegisterBackend 注册）
+func (m *cacheManagerImpl) SetDefaultBackend(name string) error {
+	if name == "" {
+		return errors.New("default backend name is empty")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.backendFactories[name] == nil {
+		return fmt.Errorf("set default backend: %w: %s", ErrBackendNotFound, name)
+	}
+	m.defaultBackend = name
+	return nil
+}
+
+// AddBackendRule 添加缓存名通配规则（path.Match 语法），只影响之后创建的缓存；后端需先通过 RegisterBackend 注册
+func (m *cacheManagerImpl) AddBackendRule(pattern, backendName string) error {
+	if pattern == "" || backendName == "" {
+		return fmt.Errorf("invalid backend rule %q -> %q: pattern and backend are required", pattern, backendName)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid backend rule pattern %q: %w", pattern, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.backendFactories[backendName] == nil {
+		return fmt.Errorf("backend rule %q: %w: %s", pattern, ErrBackendNotFound, backendName)
+	}
+	m.backendRules = append(m.backendRules, backendRule{pattern: pattern, backend: backendName})
+	return nil
+}
+
+// attachLocked 保存缓存实例并注册淘汰回调（调用方持有写锁）
+// 回调只对本实例生效：实例被替换或移除后，旧实例的回调不再影响同名的新实例。
+func (m *cacheManagerImpl) attachLocked(name string, c CacheBackend) {
+	active := new(atomic.Bool)
+	active.Store(true)
+	if notifier, ok := c.(backend.RemovalNotifier); ok {
+		// 条目被淘汰或删除后不再刷新
+		refresher := m.protection.refresher
+		notifier.OnRemove(func(key string) {
+			if !active.Load() {
+				return
+			}
+			refresher.Cancel(name, key)
+			m.tenants.remove(name, key)
+		})
+	}
+	m.caches[name] = c
+	m.attached[name] = active
+}
+
+// detachLocked 停用当前实例的淘汰回调，取消其刷新任务并清除租户跟踪（调用方持有写锁）
+// 在挂上新实例之前完成，新实例的刷新任务和配额跟踪不会被误清。
+func (m *cacheManagerImpl) detachLocked(name string) {
+	if active := m.attached[name]; active != nil {
+		active.Store(false)
+		delete(m.attached, name)
+	}
+	m.protection.refresher.CancelPrefix(name, "")
+	m.tenants.dropCache(name)
+}
+
+// RegisterCache 注册缓存实例
+//
+// 替换在写锁内完成，并发的 GetCache 要么拿到旧实例、要么拿到新实例；
+// 被替换的实例在锁外关闭，调用方不应长期持有 GetCache 返回的实例。
+func (m *cacheManagerImpl) RegisterCache(name string, cache CacheBackend) error {
+	if name == "" || cache == nil {
+		return fmt.Errorf("invalid")
+	}
+	m.mu.Lock()
+	if err := m.checkTenantCacheLocked(name, cache); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	old := m.caches[name]
+	if old != cache {
+		if old != nil {
+			m.detachLocked(name)
+		}
+		m.attachLocked(name, cache)
+	}
+	m.registered[name] = true
+	delete(m.backendNames, name)
+	m.mu.Unlock()
+
+	if old != nil && old != cache {
+		m.closeRetired(name, old)
+	}
+	return nil
+}
+
+// RemoveCache 移除并关闭缓存实例（配置保留，之后 GetCache 会按配置重新创建）
+func (m *cacheManagerImpl) RemoveCache(name string) error {
+	m.mu.Lock()
+	old, ok := m.caches[name]
+	if ok {
+		m.detachLocked(name)
+	}
+	delete(m.caches, name)
+	delete(m.registered, name)
+	delete(m.backendNames, name)
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCacheNotFound, name)
+	}
+	m.closeRetired(name, old)
+	return nil
+}
+
+// closeRetired 关闭被替换或移除的实例
+func (m *cacheManagerImpl) closeRetired(name string, c CacheBackend) {
+	if err := c.Close(); err != nil {
+		log.Printf("[WARN] Failed to close cache %s: %v", name, err)
+	}
+}
+
+// ListCaches 返回已创建或注册的缓存名称
+func (m *cacheManagerImpl) ListCaches() []string {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.caches))
+	for name := range m.caches {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// CacheInfo 返回缓存实例信息
+func (m *cacheManagerImpl) CacheInfo(name string) (*CacheInfo, error) {
+	m.mu.RLock()
+	c, ok := m.caches[name]
+	info := &CacheInfo{Name: name, Backend: m.backendNames[name], Registered: m.registered[name], Config: m.configs[name]}
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCacheNotFound, name)
+	}
+	info.BackendType = fmt.Sprintf("%T", c)
+	info.Stats = c.Stats()
+	return info, nil
+}
+
 // RegisterBackend 注册后端
 func (m *cacheManagerImpl) RegisterBackend(name string, factory BackendFactory) error {
 	if name == "" || factory == nil {
@@ -142,87 +388,255 @@ func (m *cacheManagerImpl) RegisterCacheConfig(name string, cfg *CacheConfig) er
 	return nil
 }
 
+// Invoker 调用原始方法，返回方法的主返回值（第一个返回值）和错误
+// ctx 为本次回源使用的上下文（后台重验证、刷新时与调用方的 ctx 不同）。
+type Invoker func(ctx context.Context) (interface{}, error)
+
 // Execute 执行缓存操作
-func (m *cacheManagerImpl) Execute(ctx context.Context, meta *MethodMeta, args []reflect.Value) (interface{}, error) {
+//
+// invoke 为原始方法调用，为 nil 时按返回 nil 处理。缓存不可用、key 求值失败或 condition 不满足时直接调用原始方法；
+// 原始方法的错误原样返回，缓存读写失败只记录日志。
+func (m *cacheManagerImpl) Execute(ctx context.Context, meta *MethodMeta, args []reflect.Value, invoke Invoker) (interface{}, error) {
+	if invoke == nil {
+		invoke = func(context.Context) (interface{}, error) { return nil, nil }
+	}
 	switch meta.CacheType {
 	case "cacheable":
-		return m.execCacheable(ctx, meta, args)
+		return m.execCacheable(ctx, meta, args, invoke)
 	case "cacheput":
-		return m.execCachePut(ctx, meta, args)
+		return m.execCachePut(ctx, meta, args, invoke)
 	case "cacheevict":
-		return m.execCacheEvict(ctx, meta, args)
+		return m.execCacheEvict(ctx, meta, args, invoke)
 	}
 	return nil, fmt.Errorf("unknown type: %s", meta.CacheType)
 }
 
-func (m *cacheManagerImpl) execCacheable(ctx context.Context, meta *MethodMeta, args []reflect.Value) (interface{}, error) {
+func (m *cacheManagerImpl) execCacheable(ctx context.Context, meta *MethodMeta, args []reflect.Value, invoke Invoker) (interface{}, error) {
 	cache, err := m.GetCache(meta.CacheName)
 	if err != nil {
-		return nil, err
+		log.Printf("[WARN] Execute: cache %s unavailable, invoking directly: %v", meta.CacheName, err)
+		return invoke(ctx)
 	}
 
-	evalCtx := m.buildCtx(meta, args, nil)
+	evalCtx := m.buildCtx(ctx, meta, args, nil)
+	if !m.conditionMet(meta, evalCtx) {
+		return invoke(ctx)
+	}
 	key, err := m.evaluator.EvaluateToString(meta.KeyExpr, evalCtx)
 	if err != nil {
-		return nil, err
+		log.Printf("[WARN] Execute: failed to evaluate key %q for %s: %v", meta.KeyExpr, meta.CacheName, err)
+		return invoke(ctx)
 	}
-
-	// 使用保护机制获取缓存
-	protection := m.GetProtection()
-	if protection == nil {
-		// 无保护机制，直接获取
-		v, found, _ := cache.Get(ctx, key)
-		if found {
-			return v, nil
-		}
-		return nil, nil
+	// 过滤器按未加租户前缀的注解 key 判断
+	filterKey := key
+	tenant, key, err := m.scopeKey(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] Execute: %v, bypassing cache %s", err, meta.CacheName)
+		return invoke(ctx)
 	}
 
-	// 使用受保护的获取操作
 	cacheGet := func() (interface{}, bool, error) {
 		return cache.Get(ctx, key)
 	}
-
+	// 后台重验证和刷新在调用返回后写入，不能使用调用方可能已取消的 ctx
+	storeCtx := context.WithoutCancel(ctx)
 	cacheSet := func(value interface{}, ttl time.Duration) error {
-		// 应用雪崩保护的 TTL
-		protectedTTL := protection.ApplyAvalancheProtection(ttl)
-		wrappedValue := protection.WrapForStorage(value)
-		return cache.Set(ctx, key, wrappedValue, protectedTTL)
+		if _, isErr := unwrapErrorEntry(value); !isErr && meta.Unless != "" {
+			result := UnwrapNilMarker(UnwrapStale(value))
+			if m.evaluateTruthy(meta.Unless, m.buildCtx(storeCtx, meta, args, result)) {
+				return nil
+			}
+		}
+		if err := cache.Set(storeCtx, key, value, ttl); err != nil {
+			log.Printf("[WARN] Execute: failed to cache %s:%s: %v", meta.CacheName, key, err)
+			return err
+		}
+		m.enforceQuota(storeCtx, meta.CacheName, cache, tenant, key)
+		return nil
 	}
 
-	// 注意：这里需要传入实际的执行函数，暂时返回 nil
-	// 实际使用时，应该由调用方提供 cacheMissFn
-	cacheMissFn := func() (interface{}, error) {
-		// 这里应该执行原始方法，但在拦截器中需要特殊处理
-		// 暂时返回 nil，表示需要调用原始方法
-		return nil, nil
+	opts := append([]ProtectedGetOption{WithTTL(m.resolveTTL(meta, evalCtx))}, StaleOptions(meta.StaleExpr, meta.StaleIfErrorExpr)...)
+	opts = append(opts, RefreshOptions(meta.RefreshAfterExpr)...)
+	opts = append(opts, WithFilterKey(filterKey))
+	protection := m.GetProtection().ForCache(meta.CacheName)
+	return protection.protectedGet(ctx, key, cacheGet, func(loadCtx context.Context) (interface{}, error) {
+		// 后台刷新使用新的 ctx，补上租户
+		if tenant != "" && TenantFromContext(loadCtx) == "" {
+			loadCtx = WithTenant(loadCtx, tenant)
+		}
+		return invoke(loadCtx)
+	}, cacheSet, opts)
+}
+
+func (m *cacheManagerImpl) execCachePut(ctx context.Context, meta *MethodMeta, args []reflect.Value, invoke Invoker) (interface{}, error) {
+	result, err := invoke(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	cache, err := m.GetCache(meta.CacheName)
+	if err != nil {
+		log.Printf("[WARN] Execute: cache %s unavailable, skipping put: %v", meta.CacheName, err)
+		return result, nil
+	}
+	evalCtx := m.buildCtx(ctx, meta, args, result)
+	if !m.conditionMet(meta, evalCtx) || (meta.Unless != "" && m.evaluateTruthy(meta.Unless, evalCtx)) {
+		return result, nil
+	}
+	key, err := m.evaluator.EvaluateToString(meta.KeyExpr, evalCtx)
+	if err != nil {
+		log.Printf("[WARN] Execute: failed to evaluate key %q for %s: %v", meta.KeyExpr, meta.CacheName, err)
+		return result, nil
+	}
+	filterKey := key
+	tenant, key, err := m.scopeKey(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] Execute: %v, skipping cache %s", err, meta.CacheName)
+		return result, nil
 	}
 
-	result, err := protection.ProtectedGet(ctx, key, cacheGet, cacheMissFn, cacheSet)
-	return result, err
+	if err := cache.Set(ctx, key, result, m.resolveTTL(meta, evalCtx)); err != nil {
+		log.Printf("[WARN] Execute: failed to cache %s:%s: %v", meta.CacheName, key, err)
+		return result, nil
+	}
+	m.enforceQuota(ctx, meta.CacheName, cache, tenant, key)
+	m.GetProtection().ForCache(meta.CacheName).MarkExists(ctx, filterKey)
+	return result, nil
 }
 
-func (m *cacheManagerImpl) execCachePut(ctx context.Context, meta *MethodMeta, args []reflect.Value) (interface{}, error) {
-	return nil, nil
+func (m *cacheManagerImpl) execCacheEvict(ctx context.Context, meta *MethodMeta, args []reflect.Value, invoke Invoker) (interface{}, error) {
+	if meta.Before {
+		m.evict(ctx, meta, m.buildCtx(ctx, meta, args, nil))
+	}
+
+	result, err := invoke(ctx)
+	if err != nil {
+		// 方法失败时不清除（before 模式已经清除）
+		return result, err
+	}
+
+	if !meta.Before {
+		m.evict(ctx, meta, m.buildCtx(ctx, meta, args, result))
+	}
+	return result, nil
 }
 
-func (m *cacheManagerImpl) execCacheEvict(ctx context.Context, meta *MethodMeta, args []reflect.Value) (interface{}, error) {
-	cache, err := m.GetCache(meta.CacheName)
+// evict 按注解清除缓存（设置了失效总线时同时通知其他实例）
+func (m *cacheManagerImpl) evict(ctx context.Context, meta *MethodMeta, evalCtx *spel.EvaluationContext) {
+	if !m.conditionMet(meta, evalCtx) {
+		return
+	}
+	key, err := m.evaluator.EvaluateToString(meta.KeyExpr, evalCtx)
 	if err != nil {
-		return nil, err
+		log.Printf("[WARN] Execute: failed to evaluate key %q for %s: %v", meta.KeyExpr, meta.CacheName, err)
+		return
+	}
+	if err := m.Invalidate(ctx, meta.CacheName, key); err != nil {
+		log.Printf("[WARN] Execute: failed to evict %s:%s: %v", meta.CacheName, key, err)
+	}
+}
+
+// conditionMet 判断 condition 表达式（未设置时为真，求值失败时为假）
+func (m *cacheManagerImpl) conditionMet(meta *MethodMeta, evalCtx *spel.EvaluationContext) bool {
+	return meta.Condition == "" || m.evaluateTruthy(meta.Condition, evalCtx)
+}
+
+// evaluateTruthy 求值表达式并判断结果是否为真（求值失败时为假）
+func (m *cacheManagerImpl) evaluateTruthy(expr string, evalCtx *spel.EvaluationContext) bool {
+	value, err := m.evaluator.Evaluate(expr, evalCtx)
+	return err == nil && isTruthy(value)
+}
+
+// isTruthy 判断表达式结果是否为真
+func isTruthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case int8:
+		return v != 0
+	case int16:
+		return v != 0
+	case int32:
+		return v != 0
+	case int64:
+		return v != 0
+	case uint:
+		return v != 0
+	case uint8:
+		return v != 0
+	case uint16:
+		return v != 0
+	case uint32:
+		return v != 0
+	case uint64:
+		return v != 0
+	case float32:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		return v != "" && strings.ToLower(v) != "false"
+	default:
+		return true
+	}
+}
+
+// resolveTTL 解析注解中的 TTL（秒数表达式或 duration 字符串），未指定时使用缓存配置的 DefaultTTL
+func (m *cacheManagerImpl) resolveTTL(meta *MethodMeta, evalCtx *spel.EvaluationContext) time.Duration {
+	if meta.TTLExpr != "" {
+		if seconds, err := m.evaluator.EvaluateToInt(meta.TTLExpr, evalCtx); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if d, err := time.ParseDuration(meta.TTLExpr); err == nil {
+			return d
+		}
+		log.Printf("[WARN] Invalid TTL expression %q, using cache default", meta.TTLExpr)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if cfg := m.configs[meta.CacheName]; cfg != nil {
+		return cfg.DefaultTTL
 	}
-	evalCtx := m.buildCtx(meta, args, nil)
-	key, _ := m.evaluator.EvaluateToString(meta.KeyExpr, evalCtx)
-	cache.Delete(ctx, key)
-	return nil, nil
+	return m.defaultConfig.DefaultTTL
 }
 
-func (m *cacheManagerImpl) buildCtx(meta *MethodMeta, args []reflect.Value, result interface{}) *spel.EvaluationContext {
+// buildCtx 构建 SpEL 上下文：#0/#p0 按位置，ArgNames 按参数名；未提供参数名时 #id、#user 映射到前两个参数
+// 启用多租户时 #tenant 为 callCtx 中的租户。
+func (m *cacheManagerImpl) buildCtx(callCtx context.Context, meta *MethodMeta, args []reflect.Value, result interface{}) *spel.EvaluationContext {
 	ctx := spel.NewEvaluationContext()
+	// 租户非法时 #tenant 为空，缓存操作会在加前缀时被跳过
+	ctx.Tenant, _ = m.resolveTenant(callCtx)
+	ctx.Target = meta.Target
+	if meta.Target != nil {
+		ctx.TargetType = reflect.TypeOf(meta.Target)
+	}
+	ctx.Method = meta.MethodName
+	values := make([]interface{}, len(args))
 	for i, a := range args {
-		v := a.Interface()
-		ctx.SetArgByIndex(i, v)
-		ctx.SetArg(fmt.Sprintf("p%d", i), v)
+		if a.IsValid() && a.CanInterface() {
+			values[i] = a.Interface()
+		}
+		ctx.SetArgByIndex(i, values[i])
+		ctx.SetArg(fmt.Sprintf("p%d", i), values[i])
+	}
+	if len(meta.ArgNames) == 0 {
+		if len(values) >= 1 {
+			ctx.SetArg("id", values[0])
+		}
+		if len(values) >= 2 {
+			ctx.SetArg("user", values[1])
+		}
+	}
+	for i, name := range meta.ArgNames {
+		if i < len(values) && name != "" && name != "_" {
+			ctx.SetArg(name, values[i])
+		}
 	}
 	if result != nil {
 		ctx.SetResult(result)
@@ -236,13 +650,22 @@ func (m *cacheManagerImpl) GetEvaluator() *spel.SpELEvaluator {
 	return m.evaluator
 }
 
-// Close 关闭
+// Close 关闭（失效总线由调用方负责关闭，这里只取消订阅）
 func (m *cacheManagerImpl) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.unsubscribeBus != nil {
+		m.unsubscribeBus()
+		m.unsubscribeBus = nil
+	}
+	m.bus = nil
+	m.protection.refresher.Close()
 	for n, c := range m.caches {
 		c.Close()
 		delete(m.caches, n)
+		delete(m.registered, n)
+		delete(m.backendNames, n)
+		delete(m.attached, n)
 	}
 	m.evaluator.ClearCache()
 	return nil
@@ -260,8 +683,25 @@ func (m *cacheManagerImpl) SetProtectionConfig(config *ProtectionConfig) error {
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	var oldBulkheads map[string]*BulkheadConfig
+	if m.protectionConfig != nil {
+		oldBulkheads = m.protectionConfig.Bulkheads
+	}
 	m.protectionConfig = config
-	m.protection = NewCacheProtection(config)
+	protection := NewCacheProtection(config)
+	if m.protection != nil {
+		// 保留已有统计、事件回调、缓存默认选项、刷新任务、过滤器、错误缓存规则、进行中的回源和并发限制
+		protection.stats = m.protection.stats
+		protection.cacheOptions = m.protection.cacheOptions
+		protection.refresher = m.protection.refresher
+		protection.filters = m.protection.filters
+		protection.negativeRules = m.protection.negativeRules
+		protection.singleFlyer = m.protection.singleFlyer
+		protection.flights = m.protection.flights
+		protection.bulkheads = m.protection.bulkheads
+		reconfigureBulkheads(protection.bulkheads, oldBulkheads, config.Bulkheads)
+	}
+	m.protection = protection
 	return nil
 }
 
@@ -270,13 +710,139 @@ func (m *cacheManagerImpl) GetProtectionConfig() *ProtectionConfig {
 	return m.protectionConfig
 }
 
-// Invalidate 使缓存失效
+// Invalidate 使缓存失效（启用多租户时 key 按 ctx 中的租户加前缀）
 func (m *cacheManagerImpl) Invalidate(ctx context.Context, cache string, key string) error {
 	cacheBackend, err := m.GetCache(cache)
 	if err != nil {
 		return err
 	}
-	return cacheBackend.Delete(ctx, key)
+	_, key, err = m.scopeKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := cacheBackend.Delete(ctx, key); err != nil {
+		return err
+	}
+	m.GetProtection().refresher.Cancel(cache, key)
+
+	m.mu.RLock()
+	bus := m.bus
+	m.mu.RUnlock()
+	if bus == nil {
+		return nil
+	}
+	message := &backend.InvalidationMessage{Origin: m.instanceID, CacheName: cache, Keys: []string{key}}
+	message.InjectTrace(ctx)
+	return bus.Publish(ctx, message)
+}
+
+// SetInvalidationBus 设置跨实例失效总线
+func (m *cacheManagerImpl) SetInvalidationBus(bus backend.InvalidationBus) error {
+	var unsubscribe func()
+	if bus != nil {
+		var err error
+		unsubscribe, err = bus.Subscribe(m.handleInvalidation)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to invalidation bus: %w", err)
+		}
+		if notifier, ok := bus.(backend.ReconnectNotifier); ok {
+			notifier.OnReconnect(func(downtime time.Duration) {
+				m.recoverInvalidation(bus, downtime)
+			})
+		}
+	}
+
+	m.mu.Lock()
+	old := m.unsubscribeBus
+	m.bus = bus
+	m.unsubscribeBus = unsubscribe
+	m.mu.Unlock()
+
+	if old != nil {
+		old()
+	}
+	return nil
+}
+
+// SetInvalidationRecovery 设置失效总线重连后对进程内缓存的恢复动作
+// 断开期间的失效消息可能已丢失；共享后端（如 Redis）本身就是数据源，不受影响，不做处理。
+func (m *cacheManagerImpl) SetInvalidationRecovery(action backend.RecoveryAction, prefixes ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recovery = action
+	m.recoveryPrefixes = append([]string(nil), prefixes...)
+}
+
+// recoverInvalidation 总线重连后对进程内缓存执行恢复动作
+func (m *cacheManagerImpl) recoverInvalidation(bus backend.InvalidationBus, downtime time.Duration) {
+	m.mu.RLock()
+	if m.bus != bus {
+		// 总线已被替换
+		m.mu.RUnlock()
+		return
+	}
+	action, prefixes := m.recovery, m.recoveryPrefixes
+	caches := make(map[string]CacheBackend)
+	for name, c := range m.caches {
+		if isProcessLocal(c) {
+			caches[name] = c
+		}
+	}
+	m.mu.RUnlock()
+	if action == backend.RecoveryNone {
+		return
+	}
+
+	ctx := context.Background()
+	refresher := m.GetProtection().refresher
+	for name, c := range caches {
+		if err := action.Apply(ctx, c, prefixes...); err != nil {
+			log.Printf("[WARN] Invalidation bus recovery for %s failed: %v", name, err)
+			continue
+		}
+		if action == backend.RecoveryFlushPrefix {
+			for _, prefix := range prefixes {
+				refresher.CancelPrefix(name, prefix)
+			}
+		} else {
+			refresher.CancelPrefix(name, "")
+		}
+	}
+	log.Printf("[WARN] Invalidation bus reconnected after %v, recovery=%s applied to %d local caches", downtime, action, len(caches))
+}
+
+// handleInvalidation 处理其他实例发来的失效消息（只作用于本地已创建的缓存）
+func (m *cacheManagerImpl) handleInvalidation(ctx context.Context, message *backend.InvalidationMessage) {
+	if message.Origin == m.instanceID {
+		return
+	}
+	m.mu.RLock()
+	cache, ok := m.caches[message.CacheName]
+	m.mu.RUnlock()
+	if !ok {
+		return
+	}
+	refresher := m.GetProtection().refresher
+
+	for _, key := range message.AllKeys() {
+		_ = cache.Delete(ctx, key)
+		refresher.Cancel(message.CacheName, key)
+	}
+	for _, prefix := range message.Prefixes {
+		refresher.CancelPrefix(message.CacheName, prefix)
+		if pd, ok := cache.(backend.PrefixDeleter); ok {
+			_, _ = pd.DeletePrefix(ctx, prefix)
+		} else if c, ok := cache.(backend.Clearer); ok {
+			_ = c.Clear(ctx)
+		}
+	}
+	if len(message.Tags) > 0 {
+		// 后端不记录标签，按标签失效时清空整个缓存
+		refresher.CancelPrefix(message.CacheName, "")
+		if c, ok := cache.(backend.Clearer); ok {
+			_ = c.Clear(ctx)
+		}
+	}
 }
 
 var _ CacheManager = (*cacheManagerImpl)(nil)
@@ -2,10 +2,15 @@ package core
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/coderiser/go-cache/pkg/backend"
+	"github.com/coderiser/go-cache/pkg/spel"
 )
 
 func TestCacheManager(t *testing.T) {
@@ -123,29 +128,107 @@ func TestCacheManager(t *testing.T) {
 		meta := &MethodMeta{
 			CacheType: "cacheable",
 			CacheName: "test",
-			KeyExpr:   "'test-key'",
+			KeyExpr:   "'user:' + id",
+			TTLExpr:   "60",
+			ArgNames:  []string{"id"},
 		}
+		args := []reflect.Value{reflect.ValueOf("42")}
 
-		// First call - cache miss
-		result, err := manager.Execute(ctx, meta, nil)
+		calls := 0
+		invoke := func(ctx context.Context) (interface{}, error) {
+			calls++
+			return "loaded", nil
+		}
+
+		// First call - cache miss, invokes original
+		result, err := manager.Execute(ctx, meta, args, invoke)
 		if err != nil {
 			t.Fatalf("Execute failed: %v", err)
 		}
-		if result != nil {
-			t.Errorf("Expected nil result on cache miss, got %v", result)
+		if result != "loaded" {
+			t.Errorf("Expected 'loaded' on cache miss, got %v", result)
 		}
 
-		// Set value in cache
-		cache, _ := manager.GetCache("test")
-		cache.Set(ctx, "test-key", "cached-value", 5*time.Minute)
-
 		// Second call - cache hit
-		result, err = manager.Execute(ctx, meta, nil)
+		result, err = manager.Execute(ctx, meta, args, invoke)
 		if err != nil {
 			t.Fatalf("Execute failed: %v", err)
 		}
-		if result != "cached-value" {
-			t.Errorf("Expected 'cached-value', got %v", result)
+		if result != "loaded" || calls != 1 {
+			t.Errorf("Expected cached 'loaded' with 1 call, got %v (%d calls)", result, calls)
+		}
+
+		cache, _ := manager.GetCache("test")
+		if v, found, _ := cache.Get(ctx, "user:42"); !found || v != "loaded" {
+			t.Errorf("Expected value cached under 'user:42', got %v (found=%v)", v, found)
+		}
+	})
+
+	t.Run("Execute - Cacheable condition and unless", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		ctx := context.Background()
+		cache, _ := manager.GetCache("test")
+		invoke := func(ctx context.Context) (interface{}, error) { return "", nil }
+
+		skipped := &MethodMeta{CacheType: "cacheable", CacheName: "test", KeyExpr: "'c'", Condition: "p0 > 10"}
+		manager.Execute(ctx, skipped, []reflect.Value{reflect.ValueOf(1)}, invoke)
+		if _, found, _ := cache.Get(ctx, "c"); found {
+			t.Error("Expected condition=false to bypass cache")
+		}
+
+		unless := &MethodMeta{CacheType: "cacheable", CacheName: "test", KeyExpr: "'u'", Unless: "result == ''"}
+		manager.Execute(ctx, unless, nil, invoke)
+		if _, found, _ := cache.Get(ctx, "u"); found {
+			t.Error("Expected unless=true to skip caching")
+		}
+	})
+
+	t.Run("Execute - Cacheable error", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		ctx := context.Background()
+		meta := &MethodMeta{CacheType: "cacheable", CacheName: "test", KeyExpr: "'err-key'"}
+		loadErr := errors.New("downstream failed")
+
+		_, err := manager.Execute(ctx, meta, nil, func(ctx context.Context) (interface{}, error) {
+			return nil, loadErr
+		})
+		if !errors.Is(err, loadErr) {
+			t.Errorf("Expected loader error, got %v", err)
+		}
+		cache, _ := manager.GetCache("test")
+		if _, found, _ := cache.Get(ctx, "err-key"); found {
+			t.Error("Expected failed load not to be cached")
+		}
+	})
+
+	t.Run("Execute - CachePut", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		ctx := context.Background()
+		meta := &MethodMeta{
+			CacheType: "cacheput",
+			CacheName: "put-test",
+			KeyExpr:   "'user:' + result",
+			Unless:    "result == 'skip'",
+		}
+
+		result, err := manager.Execute(ctx, meta, nil, func(ctx context.Context) (interface{}, error) { return "7", nil })
+		if err != nil || result != "7" {
+			t.Fatalf("Expected '7', got %v (%v)", result, err)
+		}
+		cache, _ := manager.GetCache("put-test")
+		if v, found, _ := cache.Get(ctx, "user:7"); !found || v != "7" {
+			t.Errorf("Expected result cached under 'user:7', got %v (found=%v)", v, found)
+		}
+
+		manager.Execute(ctx, meta, nil, func(ctx context.Context) (interface{}, error) { return "skip", nil })
+		if _, found, _ := cache.Get(ctx, "user:skip"); found {
+			t.Error("Expected unless=true to skip put")
 		}
 	})
 
@@ -168,7 +251,7 @@ func TestCacheManager(t *testing.T) {
 			KeyExpr:   "'evict-key'",
 		}
 
-		_, err := manager.Execute(ctx, meta, nil)
+		_, err := manager.Execute(ctx, meta, nil, nil)
 		if err != nil {
 			t.Fatalf("Execute failed: %v", err)
 		}
@@ -179,6 +262,31 @@ func TestCacheManager(t *testing.T) {
 		}
 	})
 
+	t.Run("Execute - CacheEvict keeps entry when method fails", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		ctx := context.Background()
+		cache, _ := manager.GetCache("evict-test")
+		cache.Set(ctx, "after", "value", 5*time.Minute)
+		cache.Set(ctx, "before", "value", 5*time.Minute)
+		failing := func(ctx context.Context) (interface{}, error) { return nil, errors.New("update failed") }
+
+		after := &MethodMeta{CacheType: "cacheevict", CacheName: "evict-test", KeyExpr: "'after'"}
+		if _, err := manager.Execute(ctx, after, nil, failing); err == nil {
+			t.Error("Expected method error to be returned")
+		}
+		if _, found, _ := cache.Get(ctx, "after"); !found {
+			t.Error("Expected entry to be kept when method fails")
+		}
+
+		before := &MethodMeta{CacheType: "cacheevict", CacheName: "evict-test", KeyExpr: "'before'", Before: true}
+		manager.Execute(ctx, before, nil, failing)
+		if _, found, _ := cache.Get(ctx, "before"); found {
+			t.Error("Expected before=true to evict even when method fails")
+		}
+	})
+
 	t.Run("Execute - unknown type", func(t *testing.T) {
 		manager := NewCacheManager()
 		defer manager.Close()
@@ -188,7 +296,7 @@ func TestCacheManager(t *testing.T) {
 			CacheType: "unknown",
 		}
 
-		_, err := manager.Execute(ctx, meta, nil)
+		_, err := manager.Execute(ctx, meta, nil, nil)
 		if err == nil {
 			t.Error("Expected error for unknown cache type")
 		}
@@ -221,6 +329,76 @@ func TestCacheManager(t *testing.T) {
 			t.Fatal("Expected non-nil evaluator")
 		}
 	})
+	t.Run("resolveTTL", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		impl := manager.(*cacheManagerImpl)
+		defaultTTL := DefaultCacheConfig("ttl-test").DefaultTTL
+
+		// Empty TTL
+		ctx := spel.NewEvaluationContext()
+		meta := &MethodMeta{CacheName: "ttl-test"}
+		if ttl := impl.resolveTTL(meta, ctx); ttl != defaultTTL {
+			t.Errorf("Expected cache default TTL %v, got %v", defaultTTL, ttl)
+		}
+
+		// Valid TTL expression
+		ctx.SetArg("ttl", int64(60))
+		meta.TTLExpr = "ttl"
+		if ttl := impl.resolveTTL(meta, ctx); ttl != 60*time.Second {
+			t.Errorf("Expected 60s TTL, got %v", ttl)
+		}
+
+		// Invalid TTL expression (should use default)
+		meta.TTLExpr = "invalid_expr"
+		if ttl := impl.resolveTTL(meta, ctx); ttl != defaultTTL {
+			t.Errorf("Expected cache default TTL for invalid expr, got %v", ttl)
+		}
+
+		// Duration string
+		meta.TTLExpr = "90s"
+		if ttl := impl.resolveTTL(meta, ctx); ttl != 90*time.Second {
+			t.Errorf("Expected 90s TTL, got %v", ttl)
+		}
+	})
+
+	t.Run("isTruthy", func(t *testing.T) {
+		tests := []struct {
+			value    interface{}
+			expected bool
+		}{
+			{nil, false},
+			{false, false},
+			{true, true},
+			{int(0), false},
+			{int(1), true},
+			{int(-1), true},
+			{uint8(0), false},
+			{uint8(1), true},
+			{uint16(0), false},
+			{uint16(1), true},
+			{uint32(0), false},
+			{uint32(1), true},
+			{uint64(0), false},
+			{uint64(1), true},
+			{float32(0), false},
+			{float32(1.5), true},
+			{float64(0), false},
+			{float64(1.5), true},
+			{"", false},
+			{"false", false},
+			{"FALSE", false},
+			{"true", true},
+			{"anything", true},
+		}
+
+		for _, test := range tests {
+			if result := isTruthy(test.value); result != test.expected {
+				t.Errorf("isTruthy(%v) = %v, expected %v", test.value, result, test.expected)
+			}
+		}
+	})
 }
 
 func TestCacheManagerConcurrency(t *testing.T) {
@@ -342,6 +520,384 @@ func BenchmarkCacheManager(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = manager.Execute(ctx, meta, nil)
+		_, _ = manager.Execute(ctx, meta, nil, nil)
 	}
 }
+
+func TestCacheManager_InvalidationBus(t *testing.T) {
+	bus := backend.NewInProcessBus()
+	defer bus.Close()
+
+	a := NewCacheManager()
+	defer a.Close()
+	b := NewCacheManager()
+	defer b.Close()
+	if err := a.SetInvalidationBus(bus); err != nil {
+		t.Fatalf("SetInvalidationBus failed: %v", err)
+	}
+	if err := b.SetInvalidationBus(bus); err != nil {
+		t.Fatalf("SetInvalidationBus failed: %v", err)
+	}
+
+	ctx := context.Background()
+	cacheA, _ := a.GetCache("users")
+	cacheB, _ := b.GetCache("users")
+	cacheA.Set(ctx, "user:1", "alice", time.Minute)
+	cacheB.Set(ctx, "user:1", "alice", time.Minute)
+	cacheB.Set(ctx, "user:2", "bob", time.Minute)
+
+	if err := a.Invalidate(ctx, "users", "user:1"); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if _, found, _ := cacheA.Get(ctx, "user:1"); found {
+		t.Error("Expected local entry to be invalidated")
+	}
+	if _, found, _ := cacheB.Get(ctx, "user:1"); found {
+		t.Error("Expected remote entry to be invalidated via bus")
+	}
+	if _, found, _ := cacheB.Get(ctx, "user:2"); !found {
+		t.Error("Expected unrelated entry to survive")
+	}
+
+	// 按前缀失效
+	bus.Publish(ctx, &backend.InvalidationMessage{Origin: "other", CacheName: "users", Prefixes: []string{"user:"}})
+	if _, found, _ := cacheB.Get(ctx, "user:2"); found {
+		t.Error("Expected prefix invalidation to remove user:2")
+	}
+
+	// 取消总线后只在本地失效
+	b.SetInvalidationBus(nil)
+	cacheB.Set(ctx, "user:3", "carol", time.Minute)
+	a.Invalidate(ctx, "users", "user:3")
+	if _, found, _ := cacheB.Get(ctx, "user:3"); !found {
+		t.Error("Expected entry to survive after bus is removed")
+	}
+}
+
+// reconnectingBus 可以手动触发重连回调的进程内总线
+type reconnectingBus struct {
+	*backend.InProcessBus
+	mu          sync.Mutex
+	onReconnect []func(downtime time.Duration)
+}
+
+func (b *reconnectingBus) OnDisconnect(fn func(err error)) {}
+
+func (b *reconnectingBus) OnReconnect(fn func(downtime time.Duration)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onReconnect = append(b.onReconnect, fn)
+}
+
+func (b *reconnectingBus) reconnect() {
+	b.mu.Lock()
+	fns := append([]func(time.Duration){}, b.onReconnect...)
+	b.mu.Unlock()
+	for _, fn := range fns {
+		fn(time.Second)
+	}
+}
+
+func TestCacheManager_InvalidationRecovery(t *testing.T) {
+	ctx := context.Background()
+	bus := &reconnectingBus{InProcessBus: backend.NewInProcessBus()}
+	defer bus.Close()
+
+	manager := NewCacheManager()
+	defer manager.Close()
+	manager.SetInvalidationBus(bus)
+
+	users, _ := manager.GetCache("users")
+	memory, _ := backend.NewMemoryBackend(DefaultCacheConfig("remote"))
+	remote := struct{ CacheBackend }{memory}
+	manager.RegisterCache("remote", remote)
+
+	// 默认清空进程内缓存，共享后端不受影响
+	users.Set(ctx, "user:1", "alice", time.Minute)
+	remote.Set(ctx, "key", "v", time.Minute)
+	bus.reconnect()
+	if _, found, _ := users.Get(ctx, "user:1"); found {
+		t.Error("Expected local cache to be cleared after reconnect")
+	}
+	if _, found, _ := remote.Get(ctx, "key"); !found {
+		t.Error("Expected shared cache to be kept")
+	}
+
+	// 按前缀恢复
+	manager.SetInvalidationRecovery(backend.RecoveryFlushPrefix, "session:")
+	users.Set(ctx, "user:1", "alice", time.Minute)
+	users.Set(ctx, "session:1", "s", time.Minute)
+	bus.reconnect()
+	if _, found, _ := users.Get(ctx, "session:1"); found {
+		t.Error("Expected prefixed key to be removed")
+	}
+	if _, found, _ := users.Get(ctx, "user:1"); !found {
+		t.Error("Expected other keys to be kept")
+	}
+
+	// 总线被替换后旧总线的重连不再生效
+	manager.SetInvalidationRecovery(backend.RecoveryFlushAll)
+	manager.SetInvalidationBus(backend.NewInProcessBus())
+	bus.reconnect()
+	if _, found, _ := users.Get(ctx, "user:1"); !found {
+		t.Error("Expected reconnect of replaced bus to be ignored")
+	}
+}
+
+// closeCountingCache 记录 Close 调用次数的缓存
+type closeCountingCache struct {
+	CacheBackend
+	closed int32
+}
+
+func (c *closeCountingCache) Close() error {
+	atomic.AddInt32(&c.closed, 1)
+	return c.CacheBackend.Close()
+}
+
+// keepOpenCache 被替换后仍可使用的内存缓存（Close 不关闭）
+type keepOpenCache struct {
+	*backend.MemoryBackend
+}
+
+func (c *keepOpenCache) Close() error { return nil }
+
+func newCloseCountingCache(t *testing.T, name string) *closeCountingCache {
+	t.Helper()
+	c, err := backend.NewMemoryBackend(DefaultCacheConfig(name))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	return &closeCountingCache{CacheBackend: c}
+}
+
+func TestCacheManager_CacheLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RegisterCache and replace", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		first := newCloseCountingCache(t, "users")
+		if err := manager.RegisterCache("users", first); err != nil {
+			t.Fatalf("RegisterCache failed: %v", err)
+		}
+		if c, _ := manager.GetCache("users"); c != first {
+			t.Errorf("Expected registered instance, got %T", c)
+		}
+
+		second := newCloseCountingCache(t, "users")
+		manager.RegisterCache("users", second)
+		if c, _ := manager.GetCache("users"); c != second {
+			t.Error("Expected replacement instance")
+		}
+		if atomic.LoadInt32(&first.closed) != 1 || atomic.LoadInt32(&second.closed) != 0 {
+			t.Errorf("Expected only the replaced instance to be closed, got %d/%d", first.closed, second.closed)
+		}
+
+		if err := manager.RegisterCache("", second); err == nil {
+			t.Error("Expected error for empty name")
+		}
+	})
+
+	t.Run("ListCaches and CacheInfo", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		manager.RegisterCacheConfig("orders", DefaultCacheConfig("orders"))
+		orders, _ := manager.GetCache("orders")
+		orders.Set(ctx, "order:1", "o1", time.Minute)
+		orders.Get(ctx, "order:1")
+		manager.RegisterCache("users", newCloseCountingCache(t, "users"))
+
+		if names := manager.ListCaches(); !reflect.DeepEqual(names, []string{"orders", "users"}) {
+			t.Errorf("Expected [orders users], got %v", names)
+		}
+
+		info, err := manager.CacheInfo("orders")
+		if err != nil {
+			t.Fatalf("CacheInfo failed: %v", err)
+		}
+		if info.BackendType != "*backend.MemoryBackend" || info.Registered || info.Config == nil || info.Stats.Hits != 1 {
+			t.Errorf("Unexpected info for created cache: %+v", info)
+		}
+		if info, _ := manager.CacheInfo("users"); !info.Registered || info.BackendType != "*core.closeCountingCache" {
+			t.Errorf("Unexpected info for registered cache: %+v", info)
+		}
+		if _, err := manager.CacheInfo("missing"); !errors.Is(err, ErrCacheNotFound) {
+			t.Errorf("Expected ErrCacheNotFound, got %v", err)
+		}
+	})
+
+	t.Run("RemoveCache", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		users := newCloseCountingCache(t, "users")
+		manager.RegisterCache("users", users)
+		if err := manager.RemoveCache("users"); err != nil {
+			t.Fatalf("RemoveCache failed: %v", err)
+		}
+		if atomic.LoadInt32(&users.closed) != 1 {
+			t.Error("Expected removed instance to be closed")
+		}
+		if len(manager.ListCaches()) != 0 {
+			t.Errorf("Expected no caches, got %v", manager.ListCaches())
+		}
+		if err := manager.RemoveCache("users"); !errors.Is(err, ErrCacheNotFound) {
+			t.Errorf("Expected ErrCacheNotFound, got %v", err)
+		}
+		// 移除后 GetCache 按配置重新创建
+		if c, err := manager.GetCache("users"); err != nil || c == users {
+			t.Errorf("Expected a new instance, got %v (%v)", c, err)
+		}
+	})
+
+	t.Run("Replace keeps the new instance's tracking", func(t *testing.T) {
+		m := NewCacheManager().(*cacheManagerImpl)
+		defer m.Close()
+
+		mem, _ := backend.NewMemoryBackend(DefaultCacheConfig("users"))
+		first := &keepOpenCache{mem}
+		defer mem.Close()
+		m.RegisterCache("users", first)
+		first.Set(ctx, "k", "v1", time.Minute)
+		m.tenants.admit("users", "acme", "old", 10)
+
+		second, _ := backend.NewMemoryBackend(DefaultCacheConfig("users"))
+		m.RegisterCache("users", second)
+		if n := m.tenants.usage("users", "acme"); n != 0 {
+			t.Errorf("Expected tracking of replaced instance to be dropped, got %d", n)
+		}
+
+		second.Set(ctx, "k", "v2", time.Minute)
+		m.tenants.admit("users", "acme", "k", 10)
+		// 旧实例的淘汰回调不能影响新实例
+		first.Delete(ctx, "k")
+		if n := m.tenants.usage("users", "acme"); n != 1 {
+			t.Errorf("Expected old instance's removal to be ignored, got usage %d", n)
+		}
+		second.Delete(ctx, "k")
+		if n := m.tenants.usage("users", "acme"); n != 0 {
+			t.Errorf("Expected new instance's removal to stop tracking, got usage %d", n)
+		}
+	})
+
+	t.Run("Replace under concurrent GetCache", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					if c, err := manager.GetCache("hot"); err != nil || c == nil {
+						t.Errorf("GetCache failed: %v", err)
+						return
+					}
+					manager.ListCaches()
+					manager.CacheInfo("hot")
+				}
+			}()
+		}
+		for i := 0; i < 50; i++ {
+			manager.RegisterCache("hot", newCloseCountingCache(t, "hot"))
+		}
+		close(stop)
+		wg.Wait()
+	})
+}
+
+func TestCacheManager_BackendSelection(t *testing.T) {
+	// recordingFactory 记录创建的缓存名，实际使用内存后端
+	recordingFactory := func(created *[]string) BackendFactory {
+		return func(cfg *CacheConfig) (CacheBackend, error) {
+			*created = append(*created, cfg.Name)
+			return backend.NewMemoryBackend(cfg)
+		}
+	}
+
+	t.Run("config, rule and default", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		var remote, local []string
+		manager.RegisterBackend("remote", recordingFactory(&remote))
+		manager.RegisterBackend("local", recordingFactory(&local))
+		manager.AddBackendRule("session:*", "remote")
+		manager.SetDefaultBackend("local")
+
+		explicit := DefaultCacheConfig("session:pinned")
+		explicit.Backend = "memory"
+		manager.RegisterCacheConfig("session:pinned", explicit)
+
+		for _, name := range []string{"session:web", "users", "session:pinned"} {
+			if _, err := manager.GetCache(name); err != nil {
+				t.Fatalf("GetCache(%s) failed: %v", name, err)
+			}
+		}
+		if !reflect.DeepEqual(remote, []string{"session:web"}) || !reflect.DeepEqual(local, []string{"users"}) {
+			t.Errorf("Unexpected backend selection: remote=%v local=%v", remote, local)
+		}
+
+		for name, want := range map[string]string{"session:web": "remote", "users": "local", "session:pinned": "memory"} {
+			if info, _ := manager.CacheInfo(name); info.Backend != want {
+				t.Errorf("Expected %s on %s, got %s", name, want, info.Backend)
+			}
+		}
+	})
+
+	t.Run("first matching rule wins", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		var first, second []string
+		manager.RegisterBackend("first", recordingFactory(&first))
+		manager.RegisterBackend("second", recordingFactory(&second))
+		manager.AddBackendRule("session:admin", "first")
+		manager.AddBackendRule("session:*", "second")
+
+		manager.GetCache("session:admin")
+		manager.GetCache("session:user")
+		if !reflect.DeepEqual(first, []string{"session:admin"}) || !reflect.DeepEqual(second, []string{"session:user"}) {
+			t.Errorf("Unexpected rule order: first=%v second=%v", first, second)
+		}
+	})
+
+	t.Run("unknown backend", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		// 规则和默认后端只接受已注册的后端
+		if err := manager.AddBackendRule("session:*", "missing"); !errors.Is(err, ErrBackendNotFound) {
+			t.Errorf("Expected ErrBackendNotFound from AddBackendRule, got %v", err)
+		}
+		if err := manager.SetDefaultBackend("missing"); !errors.Is(err, ErrBackendNotFound) {
+			t.Errorf("Expected ErrBackendNotFound from SetDefaultBackend, got %v", err)
+		}
+		if err := manager.SetDefaultBackend(""); err == nil {
+			t.Error("Expected error for empty default backend")
+		}
+
+		cfg := DefaultCacheConfig("session:web")
+		cfg.Backend = "missing"
+		manager.RegisterCacheConfig("session:web", cfg)
+		if _, err := manager.GetCache("session:web"); !errors.Is(err, ErrBackendNotFound) {
+			t.Errorf("Expected ErrBackendNotFound, got %v", err)
+		}
+		if _, err := manager.CacheInfo("session:web"); !errors.Is(err, ErrCacheNotFound) {
+			t.Errorf("Expected failed cache not to be created, got %v", err)
+		}
+		if err := manager.AddBackendRule("[", "memory"); err == nil {
+			t.Error("Expected error for malformed pattern")
+		}
+	})
+}
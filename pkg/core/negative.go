@@ -0,0 +1,108 @@
+package core
+
+import (
+	"encoding/gob"
+	"errors"
+	"time"
+)
+
+// NegativeCacheConfig 错误缓存（negative caching）规则
+// 回源返回匹配的错误时，把错误写入缓存一段时间，命中时直接返回同样的错误而不再回源。
+type NegativeCacheConfig struct {
+	Errors []error          // 用 errors.Is 匹配的错误（如 ErrNotFound），命中时返回的错误同样满足 errors.Is
+	Match  func(error) bool // 自定义匹配（如按 HTTP 404 判断），命中时只保留错误信息
+	TTL    time.Duration    // 错误缓存时间（默认 30 秒）
+}
+
+// defaultNegativeTTL 未设置 TTL 时的错误缓存时间
+const defaultNegativeTTL = 30 * time.Second
+
+// CachedError 从错误缓存中重放的错误
+// Error() 与原始错误的信息一致；原始错误匹配 NegativeCacheConfig.Errors 时可以用 errors.Is 判断。
+type CachedError struct {
+	Message string
+	cause   error
+}
+
+func (e *CachedError) Error() string { return e.Message }
+
+// Unwrap 返回匹配到的 NegativeCacheConfig.Errors 中的错误
+func (e *CachedError) Unwrap() error { return e.cause }
+
+// errorEntry 写入后端的错误信封（JSON/msgpack 后端读回来是 map）
+type errorEntry struct {
+	Marker  int    `json:"__go_cache_error" msgpack:"__go_cache_error"` // 固定为 1
+	Message string `json:"message" msgpack:"message"`
+	Code    string `json:"code,omitempty" msgpack:"code,omitempty"` // 匹配到的 Errors 中错误的 Error()，用于重放时恢复 errors.Is
+}
+
+// gob 后端按动态类型解码到 interface{}，需要注册信封类型
+func init() {
+	gob.Register(&errorEntry{})
+}
+
+// unwrapErrorEntry 识别错误信封
+func unwrapErrorEntry(value interface{}) (*errorEntry, bool) {
+	switch v := value.(type) {
+	case *errorEntry:
+		return v, true
+	case errorEntry:
+		return &v, true
+	case map[string]interface{}:
+		if marker, ok := toInt64(v["__go_cache_error"]); !ok || marker != 1 {
+			return nil, false
+		}
+		message, _ := v["message"].(string)
+		code, _ := v["code"].(string)
+		return &errorEntry{Marker: 1, Message: message, Code: code}, true
+	}
+	return nil, false
+}
+
+// SetNegativeCache 设置缓存的错误缓存规则（覆盖 ProtectionConfig.NegativeCache），nil 表示不缓存错误
+func (p *CacheProtection) SetNegativeCache(cacheName string, config *NegativeCacheConfig) {
+	p.negativeRules.Store(cacheName, config)
+}
+
+// negativeRule 返回当前缓存的错误缓存规则
+func (p *CacheProtection) negativeRule() *NegativeCacheConfig {
+	if rule, ok := p.negativeRules.Load(p.cacheName); ok {
+		return rule.(*NegativeCacheConfig)
+	}
+	return p.config.NegativeCache[p.cacheName]
+}
+
+// negativeEntry 错误匹配规则时返回要写入的信封和 TTL（回源并发已满不缓存）
+func (p *CacheProtection) negativeEntry(err error) (*errorEntry, time.Duration, bool) {
+	rule := p.negativeRule()
+	if rule == nil || err == nil || errors.Is(err, ErrLoaderSaturated) {
+		return nil, 0, false
+	}
+	ttl := rule.TTL
+	if ttl <= 0 {
+		ttl = defaultNegativeTTL
+	}
+	for _, target := range rule.Errors {
+		if errors.Is(err, target) {
+			return &errorEntry{Marker: 1, Message: err.Error(), Code: target.Error()}, ttl, true
+		}
+	}
+	if rule.Match != nil && rule.Match(err) {
+		return &errorEntry{Marker: 1, Message: err.Error()}, ttl, true
+	}
+	return nil, 0, false
+}
+
+// replayError 把错误信封还原为 CachedError
+func (p *CacheProtection) replayError(entry *errorEntry) error {
+	cached := &CachedError{Message: entry.Message}
+	if rule := p.negativeRule(); rule != nil && entry.Code != "" {
+		for _, target := range rule.Errors {
+			if target.Error() == entry.Code {
+				cached.cause = target
+				break
+			}
+		}
+	}
+	return cached
+}
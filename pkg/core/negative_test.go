@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/serializer"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+// jsonStore 测试用的单 key 存储，模拟 Redis 等后端的 JSON 序列化
+type jsonStore struct {
+	data  []byte
+	ttl   time.Duration
+	found bool
+}
+
+func (s *jsonStore) get() (interface{}, bool, error) {
+	if !s.found {
+		return nil, false, nil
+	}
+	var value interface{}
+	err := json.Unmarshal(s.data, &value)
+	return value, true, err
+}
+
+func (s *jsonStore) set(v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.data, s.ttl, s.found = data, ttl, true
+	return nil
+}
+
+// serializerRoundTrip 按 RedisClusterBackend 的方式序列化后解码到 interface{}
+func serializerRoundTrip(t *testing.T, name string, v interface{}) interface{} {
+	t.Helper()
+	ser, err := serializer.Get(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ser.Marshal(&v)
+	if err != nil {
+		t.Fatalf("%s: marshal: %v", name, err)
+	}
+	var decoded interface{}
+	if err := ser.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("%s: unmarshal: %v", name, err)
+	}
+	return decoded
+}
+
+// TestNegativeCache 测试缓存匹配的回源错误并在命中时重放
+func TestNegativeCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Replay matched error", func(t *testing.T) {
+		config := DefaultProtectionConfig()
+		config.NegativeCache = map[string]*NegativeCacheConfig{
+			"users": {Errors: []error{errUserNotFound}, TTL: 10 * time.Second},
+		}
+		users := NewCacheProtection(config).ForCache("users")
+		store := &jsonStore{}
+
+		var calls int32
+		loader := func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, errors.Join(errUserNotFound, errors.New("id=42"))
+		}
+
+		for i := 0; i < 3; i++ {
+			_, err := users.ProtectedGet(ctx, "user:42", store.get, loader, store.set)
+			if !errors.Is(err, errUserNotFound) {
+				t.Fatalf("Call %d: expected errUserNotFound, got %v", i, err)
+			}
+			if !strings.Contains(err.Error(), "id=42") {
+				t.Errorf("Call %d: expected original message, got %q", i, err.Error())
+			}
+		}
+		if calls != 1 {
+			t.Errorf("Expected loader to be called once, got %d", calls)
+		}
+		if store.ttl != 10*time.Second {
+			t.Errorf("Expected negative TTL 10s, got %v", store.ttl)
+		}
+		var cached *CachedError
+		_, err := users.ProtectedGet(ctx, "user:42", store.get, loader, store.set)
+		if !errors.As(err, &cached) {
+			t.Errorf("Expected *CachedError on hit, got %T", err)
+		}
+		if stats := users.GetStats(); stats.NegativeHits != 3 || stats.LoaderErrors != 1 {
+			t.Errorf("Expected 3 negative hits and 1 loader error, got %+v", stats)
+		}
+	})
+
+	t.Run("Match predicate", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		protection.SetNegativeCache("users", &NegativeCacheConfig{
+			Match: func(err error) bool { return strings.HasPrefix(err.Error(), "404") },
+		})
+		users := protection.ForCache("users")
+		store := &jsonStore{}
+
+		_, err := users.ProtectedGet(ctx, "k", store.get, func() (interface{}, error) {
+			return nil, errors.New("404 page not found")
+		}, store.set)
+		if err == nil || !store.found || store.ttl != defaultNegativeTTL {
+			t.Fatalf("Expected error to be cached with default TTL, got %v (found=%v ttl=%v)", err, store.found, store.ttl)
+		}
+		_, err = users.ProtectedGet(ctx, "k", store.get, func() (interface{}, error) { return "v", nil }, store.set)
+		if err == nil || err.Error() != "404 page not found" {
+			t.Errorf("Expected replayed error, got %v", err)
+		}
+	})
+
+	t.Run("Unmatched errors are not cached", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		protection.SetNegativeCache("users", &NegativeCacheConfig{Errors: []error{errUserNotFound}})
+		store := &jsonStore{}
+
+		_, err := protection.ForCache("users").ProtectedGet(ctx, "k", store.get, func() (interface{}, error) {
+			return nil, errors.New("connection refused")
+		}, store.set)
+		if err == nil || store.found {
+			t.Errorf("Expected unmatched error not to be cached, got %v (found=%v)", err, store.found)
+		}
+		// 未配置规则的缓存不受影响
+		_, err = protection.ForCache("orders").ProtectedGet(ctx, "k", store.get, func() (interface{}, error) {
+			return nil, errUserNotFound
+		}, store.set)
+		if err == nil || store.found {
+			t.Errorf("Expected cache without rule not to cache errors, got %v (found=%v)", err, store.found)
+		}
+	})
+
+	t.Run("Stale value is kept", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		protection.SetNegativeCache("users", &NegativeCacheConfig{Errors: []error{errUserNotFound}})
+		users := protection.ForCache("users")
+		store := &staleStore{}
+		opts := []ProtectedGetOption{WithTTL(time.Minute), WithJitter(0), WithStaleIfError(time.Hour)}
+
+		if _, err := users.ProtectedGet(ctx, "k", store.get, func() (interface{}, error) { return "v1", nil }, store.set, opts...); err != nil {
+			t.Fatalf("Expected first load to succeed, got %v", err)
+		}
+		store.age(2 * time.Minute)
+		v, err := users.ProtectedGet(ctx, "k", store.get, func() (interface{}, error) { return nil, errUserNotFound }, store.set, opts...)
+		if err != nil || v != "v1" {
+			t.Errorf("Expected stale value on error, got %v (%v)", v, err)
+		}
+		if _, ok := store.value.(*staleEntry); !ok {
+			t.Errorf("Expected stale entry to be kept, got %T", store.value)
+		}
+	})
+}
+
+// TestNegativeCacheGeneric 测试泛型 ProtectedGet 重放错误
+func TestNegativeCacheGeneric(t *testing.T) {
+	cache := newTestCache(t)
+	config := DefaultProtectionConfig()
+	config.NegativeCache = map[string]*NegativeCacheConfig{"users": {Errors: []error{errUserNotFound}}}
+	users := NewCacheProtection(config).ForCache("users")
+
+	var calls int32
+	for i := 0; i < 2; i++ {
+		_, err := ProtectedGet[string](context.Background(), users, cache, "k", func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return "", errUserNotFound
+		})
+		if !errors.Is(err, errUserNotFound) {
+			t.Errorf("Call %d: expected errUserNotFound, got %v", i, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected loader to be called once, got %d", calls)
+	}
+}
+
+// TestErrorEntrySerializers 测试错误信封经过各集群序列化器往返后仍能识别
+func TestErrorEntrySerializers(t *testing.T) {
+	for _, name := range []string{"json", "gob", "msgpack"} {
+		decoded := serializerRoundTrip(t, name, &errorEntry{Marker: 1, Message: "user not found", Code: errUserNotFound.Error()})
+		entry, ok := unwrapErrorEntry(decoded)
+		if !ok || entry.Message != "user not found" || entry.Code != errUserNotFound.Error() {
+			t.Errorf("%s: expected error envelope, got %#v", name, decoded)
+		}
+	}
+}
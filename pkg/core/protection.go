@@ -2,8 +2,13 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand/v2"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coderiser/go-cache/pkg/backend"
@@ -47,11 +52,25 @@ type ProtectionConfig struct {
 	EmptyValueTTL               time.Duration // 空值缓存 TTL（默认 5 分钟）
 
 	// 击穿保护配置
-	EnableBreakdownProtection bool // 是否启用击穿保护（singleflight）
+	EnableBreakdownProtection bool          // 是否启用击穿保护（singleflight）
+	LoaderTimeout             time.Duration // 回源超时（共享回源与调用方 ctx 解耦，0 表示不限制，默认 30 秒）
 
 	// 雪崩保护配置
-	EnableAvalancheProtection bool          // 是否启用雪崩保护
-	TTLJitterFactor           float64       // TTL 随机偏移因子（0.0-0.5，默认 0.1 即 10%）
+	EnableAvalancheProtection bool    // 是否启用雪崩保护
+	TTLJitterFactor           float64 // TTL 随机偏移因子（0.0-0.5，默认 0.1 即 10%）
+
+	// 旧值服务配置（0 表示不启用，可被缓存默认选项和单次调用选项覆盖）
+	StaleWhileRevalidate time.Duration // 软 TTL 过期后返回旧值并后台刷新的窗口
+	StaleIfError         time.Duration // 回源失败时继续返回旧值的宽限期
+
+	// 写后刷新调度配置（nil 使用 DefaultRefreshConfig）
+	Refresh *RefreshConfig
+
+	// 按缓存名称配置的回源并发限制（bulkhead），未配置的缓存不限制
+	Bulkheads map[string]*BulkheadConfig
+
+	// 按缓存名称配置的错误缓存规则，未配置的缓存不缓存错误
+	NegativeCache map[string]*NegativeCacheConfig
 }
 
 // DefaultProtectionConfig 默认保护配置
@@ -60,6 +79,7 @@ func DefaultProtectionConfig() *ProtectionConfig {
 		EnablePenetrationProtection: true,
 		EmptyValueTTL:               5 * time.Minute,
 		EnableBreakdownProtection:   true,
+		LoaderTimeout:               30 * time.Second,
 		EnableAvalancheProtection:   true,
 		TTLJitterFactor:             0.1,
 	}
@@ -67,9 +87,17 @@ func DefaultProtectionConfig() *ProtectionConfig {
 
 // CacheProtection 缓存异常保护器
 type CacheProtection struct {
-	config      *ProtectionConfig
-	singleFlyer *singleflight.Group
-	mu          sync.RWMutex
+	config        *ProtectionConfig
+	singleFlyer   *singleflight.Group
+	mu            sync.RWMutex
+	cacheName     string            // ForCache 返回的视图所属缓存（统计按缓存区分）
+	stats         *protectionStats  // 所有视图共享
+	cacheOptions  *sync.Map         // 缓存名称 → 默认调用选项（所有视图共享）
+	refresher     *RefreshScheduler // 写后刷新调度器（所有视图共享）
+	filters       *sync.Map         // 缓存名称 → 存在性过滤器（所有视图共享）
+	flights       *flightRegistry   // 正在进行的共享回源（所有视图共享）
+	bulkheads     *sync.Map         // 缓存名称 → 回源并发限制（所有视图共享）
+	negativeRules *sync.Map         // 缓存名称 → 错误缓存规则（所有视图共享）
 }
 
 // NewCacheProtection 创建缓存保护器
@@ -78,11 +106,56 @@ func NewCacheProtection(config *ProtectionConfig) *CacheProtection {
 		config = DefaultProtectionConfig()
 	}
 	return &CacheProtection{
-		config:      config,
-		singleFlyer: &singleflight.Group{},
+		config:        config,
+		singleFlyer:   &singleflight.Group{},
+		stats:         newProtectionStats(),
+		cacheOptions:  &sync.Map{},
+		refresher:     NewRefreshScheduler(config.Refresh),
+		filters:       &sync.Map{},
+		flights:       newFlightRegistry(),
+		bulkheads:     &sync.Map{},
+		negativeRules: &sync.Map{},
 	}
 }
 
+// Refresher 返回写后刷新调度器
+func (p *CacheProtection) Refresher() *RefreshScheduler {
+	return p.refresher
+}
+
+// ForCache 返回绑定到指定缓存的保护器视图
+// 视图共享配置、singleflight 和统计，但统计计入该缓存，singleflight key 也按缓存隔离。
+func (p *CacheProtection) ForCache(cacheName string) *CacheProtection {
+	if cacheName == p.cacheName {
+		return p
+	}
+	return &CacheProtection{
+		config:        p.config,
+		singleFlyer:   p.singleFlyer,
+		cacheName:     cacheName,
+		stats:         p.stats,
+		cacheOptions:  p.cacheOptions,
+		refresher:     p.refresher,
+		filters:       p.filters,
+		flights:       p.flights,
+		bulkheads:     p.bulkheads,
+		negativeRules: p.negativeRules,
+	}
+}
+
+// CacheName 视图所属的缓存名称（根保护器为空）
+func (p *CacheProtection) CacheName() string {
+	return p.cacheName
+}
+
+// flightKey singleflight key（按缓存隔离，不同缓存的同名 key 不会合并）
+func (p *CacheProtection) flightKey(key string) string {
+	if p.cacheName == "" {
+		return key
+	}
+	return p.cacheName + "\x00" + key
+}
+
 // GetProtectionConfig 获取保护配置
 func (p *CacheProtection) GetProtectionConfig() *ProtectionConfig {
 	return p.config
@@ -96,6 +169,7 @@ func (p *CacheProtection) ApplyPenetrationProtection(value interface{}) (interfa
 	}
 
 	if IsNilMarker(value) {
+		p.stats.record(p.cacheName, EventNilMarkerHit)
 		return nil, true // 空值标记，视为未命中
 	}
 
@@ -128,18 +202,21 @@ func (p *CacheProtection) GetEmptyValueTTL() time.Duration {
 // fn: 实际的数据获取函数
 // 返回：(结果，错误，是否从 singleflight 获取)
 func (p *CacheProtection) ApplyBreakdownProtection(ctx context.Context, key string, fn func() (interface{}, error)) (interface{}, error, bool) {
+	return p.applyBreakdownProtection(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return callWithTimeout(ctx, p.config.LoaderTimeout, func(context.Context) (interface{}, error) {
+			return fn()
+		})
+	})
+}
+
+// applyBreakdownProtection 击穿保护：启用时合并并发回源，每个调用方按自己的 ctx 停止等待
+func (p *CacheProtection) applyBreakdownProtection(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error, bool) {
 	if !p.config.EnableBreakdownProtection {
 		// 未启用击穿保护，直接执行
-		result, err := fn()
+		result, err := fn(ctx)
 		return result, err, false
 	}
-
-	// 使用 singleflight 合并并发请求
-	v, err, shared := p.singleFlyer.Do(key, func() (interface{}, error) {
-		return fn()
-	})
-
-	return v, err, shared
+	return p.shareLoad(ctx, key, fn)
 }
 
 // ApplyAvalancheProtection 应用雪崩保护（计算带抖动的 TTL）
@@ -147,19 +224,24 @@ func (p *CacheProtection) ApplyAvalancheProtection(baseTTL time.Duration) time.D
 	if !p.config.EnableAvalancheProtection {
 		return baseTTL
 	}
+	return p.jitterTTL(baseTTL, p.config.TTLJitterFactor)
+}
 
-	if p.config.TTLJitterFactor <= 0 {
+// jitterTTL 在 baseTTL 上加入 ±jitterFactor 的随机偏移
+func (p *CacheProtection) jitterTTL(baseTTL time.Duration, jitterFactor float64) time.Duration {
+	if jitterFactor <= 0 {
 		return baseTTL
 	}
-
-	jitterFactor := p.config.TTLJitterFactor
 	if jitterFactor > 0.5 {
 		jitterFactor = 0.5
 	}
 
 	// 计算随机抖动
 	jitter := time.Duration(float64(baseTTL) * jitterFactor)
-	
+	if jitter <= 0 {
+		return baseTTL
+	}
+
 	// 使用安全的随机数生成器生成随机偏移
 	randomOffset := time.Duration(rand.Int64N(int64(jitter*2))) - jitter
 	
@@ -167,6 +249,7 @@ func (p *CacheProtection) ApplyAvalancheProtection(baseTTL time.Duration) time.D
 	if actualTTL < time.Second {
 		actualTTL = time.Second
 	}
+	p.stats.record(p.cacheName, EventTTLJittered)
 
 	return actualTTL
 }
@@ -179,7 +262,7 @@ func (p *CacheProtection) CalculateTTLWithJitter(baseTTL time.Duration, jitterFa
 
 	jitter := time.Duration(float64(baseTTL) * jitterFactor)
 	randomOffset := time.Duration(rand.Int64N(int64(jitter*2))) - jitter
-	
+
 	actualTTL := baseTTL + randomOffset
 	if actualTTL < time.Second {
 		actualTTL = time.Second
@@ -190,33 +273,123 @@ func (p *CacheProtection) CalculateTTLWithJitter(baseTTL time.Duration, jitterFa
 
 // SingleFlightDo 直接使用 singleflight（高级用法）
 func (p *CacheProtection) SingleFlightDo(key string, fn func() (interface{}, error)) (interface{}, error, bool) {
-	return p.singleFlyer.Do(key, fn)
+	return p.singleFlyer.Do(p.flightKey(key), fn)
 }
 
-// CancelSingleFlight 取消正在进行的 singleflight 请求（通过删除 key）
-// 注意：这不会取消正在执行的函数，只会移除 singleflight 的缓存
+// CancelSingleFlight 取消 key 上正在进行的共享回源
+// 所有等待者立即返回 ErrSingleFlightCancelled，回源函数的 ctx 被取消，之后的请求重新回源。
 func (p *CacheProtection) CancelSingleFlight(key string) {
-	p.singleFlyer.Forget(key)
+	flightKey := p.flightKey(key)
+	p.flights.cancel(flightKey)
+	p.singleFlyer.Forget(flightKey)
+}
+
+// ErrLoaderTimeout 回源函数超过 LoaderTimeout / WithLoaderTimeout 指定的时间
+var ErrLoaderTimeout = errors.New("cache loader timed out")
+
+// ProtectedGetOption ProtectedGet 单次调用选项
+type ProtectedGetOption func(*protectedGetOptions)
+
+type protectedGetOptions struct {
+	ttl           time.Duration // 0 表示使用后端的 DefaultTTL
+	emptyTTL      time.Duration // 0 表示使用 ProtectionConfig.EmptyValueTTL
+	loaderTimeout time.Duration // 默认 ProtectionConfig.LoaderTimeout，0 表示不限制
+	jitter        *float64      // nil 表示使用 ProtectionConfig.TTLJitterFactor
+
+	staleWhileRevalidate time.Duration // 软 TTL 过期后返回旧值并后台刷新的窗口
+	staleIfError         time.Duration // 回源失败时继续返回旧值的宽限期
+	refreshAfter         time.Duration // 写入后多久在后台刷新
+	priority             int           // 回源排队优先级
+	filterKey            string        // 过滤器使用的 key（为空时使用缓存 key）
+}
+
+// WithTTL 设置回源结果的缓存 TTL（不设置时由后端使用 DefaultTTL）
+func WithTTL(ttl time.Duration) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.ttl = ttl }
+}
+
+// WithEmptyTTL 设置空值标记的缓存 TTL
+func WithEmptyTTL(ttl time.Duration) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.emptyTTL = ttl }
+}
+
+// WithLoaderTimeout 设置回源超时，超时返回 ErrLoaderTimeout
+// 超时后回源函数仍在后台运行，通过传给它的 ctx 感知取消。
+func WithLoaderTimeout(timeout time.Duration) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.loaderTimeout = timeout }
+}
+
+// WithJitter 覆盖本次调用的 TTL 抖动因子（0 表示不加抖动）
+func WithJitter(factor float64) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.jitter = &factor }
 }
 
 // ProtectedGet 受保护的缓存获取操作
-// 整合穿透保护和击穿保护
+// 整合穿透保护、击穿保护和雪崩保护。cacheSet 收到的是已经处理过的值（nil 已替换为空值标记）和最终 TTL，
+// 直接写入后端即可，不要再次包装。
 func (p *CacheProtection) ProtectedGet(
 	ctx context.Context,
 	key string,
 	cacheGet func() (interface{}, bool, error),
 	cacheMissFn func() (interface{}, error),
 	cacheSet func(interface{}, time.Duration) error,
+	opts ...ProtectedGetOption,
 ) (interface{}, error) {
+	loader := func(context.Context) (interface{}, error) {
+		return cacheMissFn()
+	}
+	return p.protectedGet(ctx, key, cacheGet, loader, cacheSet, opts)
+}
+
+func (p *CacheProtection) protectedGet(
+	ctx context.Context,
+	key string,
+	cacheGet func() (interface{}, bool, error),
+	loader func(context.Context) (interface{}, error),
+	cacheSet func(interface{}, time.Duration) error,
+	opts []ProtectedGetOption,
+) (interface{}, error) {
+	options := p.resolveOptions(opts)
+
 	// 1. 尝试从缓存获取
 	value, found, err := cacheGet()
 	if err != nil {
 		// 缓存获取失败，直接执行原始函数
-		result, execErr := cacheMissFn()
+		result, execErr := p.loadLimited(ctx, loader, options)
+		if execErr != nil && !errors.Is(execErr, ErrLoaderSaturated) {
+			p.stats.record(p.cacheName, EventLoaderError)
+		}
 		return result, execErr
 	}
 
+	var stale *staleEntry
 	if found {
+		if entry, ok := unwrapStaleEntry(value); ok {
+			now := time.Now()
+			switch {
+			case entry.fresh(now):
+				value = entry.Value
+			case entry.revalidatable(now) && options.staleWhileRevalidate > 0:
+				// 软过期：立即返回旧值，后台刷新
+				p.revalidate(ctx, key, loader, cacheSet, options)
+				p.stats.record(p.cacheName, EventStaleServed)
+				value = entry.Value
+			default:
+				// 超过重验证窗口：同步回源，失败时在宽限期内返回旧值
+				stale, found = entry, false
+			}
+		}
+	}
+
+	if found {
+		if entry, ok := unwrapErrorEntry(value); ok {
+			// 错误缓存命中：重放同样的错误
+			p.stats.record(p.cacheName, EventNegativeHit)
+			return nil, p.replayError(entry)
+		}
+		if options.refreshAfter > 0 && p.refresher != nil {
+			p.refresher.Touch(p.cacheName, key)
+		}
 		// 2. 应用穿透保护检查
 		unwrapped, isEmpty := p.ApplyPenetrationProtection(value)
 		if isEmpty {
@@ -226,45 +399,354 @@ func (p *CacheProtection) ProtectedGet(
 		return unwrapped, nil
 	}
 
-	// 3. 缓存未命中，应用击穿保护
-	// 3. 缓存未命中，应用击穿保护
-	var result interface{}
-	var execErr error
+	// 3. 过滤器判定不存在的 key 不回源
+	if stale == nil && !p.mightExist(ctx, options.filterKeyFor(key)) {
+		p.stats.record(p.cacheName, EventFilterRejected)
+		return nil, nil
+	}
 
-	result, execErr, _ = p.ApplyBreakdownProtection(ctx, key, func() (interface{}, error) {
-		// 执行原始函数
-		missResult, missErr := cacheMissFn()
-		if missErr != nil {
-			return missResult, missErr
+	// 4. 缓存未命中，应用击穿保护
+	result, execErr, _ := p.applyBreakdownProtection(ctx, key, func(ctx context.Context) (interface{}, error) {
+		result, err := p.loadAndStore(ctx, key, loader, cacheSet, options)
+		// 没有旧值时按规则缓存错误（有旧值时保留旧值用于 stale-if-error）
+		if err != nil && stale == nil {
+			if entry, ttl, ok := p.negativeEntry(err); ok {
+				_ = cacheSet(entry, ttl)
+			}
 		}
+		return result, err
+	})
+
+	// 回源失败时在宽限期内返回旧值；回源并发已满时只要有旧值就返回
+	if execErr != nil && stale != nil &&
+		(errors.Is(execErr, ErrLoaderSaturated) || stale.servableOnError(time.Now(), options.staleIfError)) {
+		p.stats.record(p.cacheName, EventStaleServed)
+		return p.UnwrapFromStorage(stale.Value), nil
+	}
+	return result, execErr
+}
 
-		// 4. 写入缓存（应用穿透和雪崩保护）
-		var ttl time.Duration
-		if missResult == nil {
-			// 空值使用较短的 TTL
-			ttl = p.GetEmptyValueTTL()
-		} else {
-			// 正常值使用带抖动的 TTL
-			ttl = p.ApplyAvalancheProtection(30 * time.Minute) // 默认 30 分钟，可配置
+// resolveOptions 合并 ProtectionConfig、缓存默认选项和本次调用的选项
+func (p *CacheProtection) resolveOptions(opts []ProtectedGetOption) *protectedGetOptions {
+	options := &protectedGetOptions{
+		loaderTimeout:        p.config.LoaderTimeout,
+		staleWhileRevalidate: p.config.StaleWhileRevalidate,
+		staleIfError:         p.config.StaleIfError,
+	}
+	if cacheOpts, ok := p.cacheOptions.Load(p.cacheName); ok {
+		for _, opt := range cacheOpts.([]ProtectedGetOption) {
+			opt(options)
 		}
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
 
-		wrappedValue := p.WrapForStorage(missResult)
-		_ = cacheSet(wrappedValue, ttl)
+// loadAndStore 回源并写入缓存（应用穿透和雪崩保护）
+func (p *CacheProtection) loadAndStore(
+	ctx context.Context,
+	key string,
+	loader func(context.Context) (interface{}, error),
+	cacheSet func(interface{}, time.Duration) error,
+	options *protectedGetOptions,
+) (interface{}, error) {
+	result, err := p.loadLimited(ctx, loader, options)
+	if err != nil {
+		if !errors.Is(err, ErrLoaderSaturated) {
+			p.stats.record(p.cacheName, EventLoaderError)
+		}
+		return result, err
+	}
 
-		return missResult, nil
-	})
+	stored, ttl := p.WrapForStorage(result), p.storageTTL(result, options)
+	if options.staleEnabled() {
+		now := time.Now()
+		stored = &staleEntry{
+			Marker:     1,
+			Value:      stored,
+			FreshUntil: now.Add(ttl).UnixMilli(),
+			StaleUntil: now.Add(ttl + options.staleWhileRevalidate).UnixMilli(),
+		}
+		ttl += options.staleWhileRevalidate + options.staleIfError
+	}
+	if result != nil {
+		p.MarkExists(ctx, options.filterKeyFor(key))
+	}
+	if err := cacheSet(stored, ttl); err == nil {
+		p.scheduleRefresh(key, loader, cacheSet, options)
+	}
 
-	return result, execErr
+	return result, nil
+}
+
+// storageTTL 计算回源结果的缓存 TTL
+func (p *CacheProtection) storageTTL(value interface{}, options *protectedGetOptions) time.Duration {
+	if value == nil && p.config.EnablePenetrationProtection {
+		// 空值使用较短的 TTL
+		if options.emptyTTL > 0 {
+			return options.emptyTTL
+		}
+		return p.GetEmptyValueTTL()
+	}
+
+	if options.ttl <= 0 || !p.config.EnableAvalancheProtection {
+		return options.ttl
+	}
+	factor := p.config.TTLJitterFactor
+	if options.jitter != nil {
+		factor = *options.jitter
+	}
+	return p.jitterTTL(options.ttl, factor)
+}
+
+// load 执行回源函数（设置了超时时在超时后返回 ErrLoaderTimeout）
+func (p *CacheProtection) load(ctx context.Context, loader func(context.Context) (interface{}, error), options *protectedGetOptions) (interface{}, error) {
+	return callWithTimeout(ctx, options.loaderTimeout, loader)
+}
+
+// ProtectedGet 类型安全的受保护读取：直接读写 cache
+// 缓存中的值类型不是 T 时视为未命中并重新回源；空值标记返回 T 的零值。
+func ProtectedGet[T any](
+	ctx context.Context,
+	p *CacheProtection,
+	cache CacheBackend,
+	key string,
+	loader func(ctx context.Context) (T, error),
+	opts ...ProtectedGetOption,
+) (T, error) {
+	var zero T
+
+	cacheGet := func() (interface{}, bool, error) {
+		value, found, err := cache.Get(ctx, key)
+		if err != nil || !found {
+			return value, found, err
+		}
+		inner := UnwrapStale(value)
+		if _, isErr := unwrapErrorEntry(inner); isErr || IsNilMarker(inner) {
+			return value, true, nil
+		}
+		if _, ok := inner.(T); !ok {
+			return nil, false, nil
+		}
+		return value, true, nil
+	}
+	cacheSet := func(value interface{}, ttl time.Duration) error {
+		return cache.Set(ctx, key, value, ttl)
+	}
+	load := func(ctx context.Context) (interface{}, error) {
+		value, err := loader(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+
+	result, err := p.protectedGet(ctx, key, cacheGet, load, cacheSet, opts)
+	if err != nil || result == nil {
+		return zero, err
+	}
+	typed, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("protected get %q: unexpected value type %T", key, result)
+	}
+	return typed, nil
+}
+
+// ConvertValue 把 Execute 返回的值转换为 T
+// JSON/msgpack 后端读回来的结构体是 map、数字是 float64 等，类型不是 T 时按 JSON 重新解码；无法解码时返回错误。
+func ConvertValue[T any](value interface{}) (T, error) {
+	var zero T
+	if value == nil {
+		return zero, nil
+	}
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return zero, fmt.Errorf("convert %T to %v: %w", value, reflect.TypeOf((*T)(nil)).Elem(), err)
+	}
+	var typed T
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return zero, fmt.Errorf("convert %T to %v: %w", value, reflect.TypeOf((*T)(nil)).Elem(), err)
+	}
+	return typed, nil
 }
 
 // ProtectionStats 保护机制统计
 type ProtectionStats struct {
-	PenetrationBlocked int64 // 穿透拦截次数
-	BreakdownMerged    int64 // 击穿合并次数
-	AvalancheJittered  int64 // 雪崩抖动次数
+	PenetrationBlocked int64 // 穿透拦截次数（命中空值标记）
+	BreakdownMerged    int64 // 击穿合并次数（singleflight 共享结果的调用）
+	AvalancheJittered  int64 // 雪崩抖动次数（TTL 加入随机偏移）
+	LoaderErrors       int64 // 回源函数返回错误的次数
+	StaleServed        int64 // 返回旧值的次数（重验证窗口内或回源失败时）
+	Revalidations      int64 // 后台重验证次数
+	FilterRejected     int64 // 被存在性过滤器拦截、没有回源的次数
+	LoaderSaturated    int64 // 回源并发已满被拒绝的次数
+	NegativeHits       int64 // 命中错误缓存、直接返回错误的次数
+}
+
+// ProtectionEvent 保护机制事件
+type ProtectionEvent string
+
+const (
+	EventNilMarkerHit       ProtectionEvent = "nil_marker_hit"      // 命中空值标记
+	EventSingleflightShared ProtectionEvent = "singleflight_shared" // singleflight 共享结果
+	EventTTLJittered        ProtectionEvent = "ttl_jittered"        // TTL 加入随机偏移
+	EventLoaderError        ProtectionEvent = "loader_error"        // 回源失败
+	EventStaleServed        ProtectionEvent = "stale_served"        // 返回旧值
+	EventRevalidation       ProtectionEvent = "revalidation"        // 后台重验证
+	EventFilterRejected     ProtectionEvent = "filter_rejected"     // 存在性过滤器拦截
+	EventLoaderSaturated    ProtectionEvent = "loader_saturated"    // 回源并发已满被拒绝
+	EventNegativeHit        ProtectionEvent = "negative_hit"        // 命中错误缓存
+)
+
+// protectionCounters 单个缓存的计数器
+type protectionCounters struct {
+	penetrationBlocked int64
+	breakdownMerged    int64
+	avalancheJittered  int64
+	loaderErrors       int64
+	staleServed        int64
+	revalidations      int64
+	filterRejected     int64
+	loaderSaturated    int64
+	negativeHits       int64
+}
+
+func (c *protectionCounters) snapshot() *ProtectionStats {
+	return &ProtectionStats{
+		PenetrationBlocked: atomic.LoadInt64(&c.penetrationBlocked),
+		BreakdownMerged:    atomic.LoadInt64(&c.breakdownMerged),
+		AvalancheJittered:  atomic.LoadInt64(&c.avalancheJittered),
+		LoaderErrors:       atomic.LoadInt64(&c.loaderErrors),
+		StaleServed:        atomic.LoadInt64(&c.staleServed),
+		Revalidations:      atomic.LoadInt64(&c.revalidations),
+		FilterRejected:     atomic.LoadInt64(&c.filterRejected),
+		LoaderSaturated:    atomic.LoadInt64(&c.loaderSaturated),
+		NegativeHits:       atomic.LoadInt64(&c.negativeHits),
+	}
+}
+
+// protectionStats 按缓存统计保护事件（根保护器及其所有视图共享）
+type protectionStats struct {
+	mu        sync.RWMutex
+	caches    map[string]*protectionCounters
+	hooks     []func(cacheName string, event ProtectionEvent)
+	waitHooks []func(cacheName string, wait time.Duration)
+}
+
+func newProtectionStats() *protectionStats {
+	return &protectionStats{caches: make(map[string]*protectionCounters)}
+}
+
+func (s *protectionStats) counters(cacheName string) *protectionCounters {
+	s.mu.RLock()
+	c, ok := s.caches[cacheName]
+	s.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok = s.caches[cacheName]; !ok {
+		c = &protectionCounters{}
+		s.caches[cacheName] = c
+	}
+	return c
 }
 
-// GetStats 获取保护统计（当前实现返回空统计，可扩展）
+func (s *protectionStats) record(cacheName string, event ProtectionEvent) {
+	c := s.counters(cacheName)
+	switch event {
+	case EventNilMarkerHit:
+		atomic.AddInt64(&c.penetrationBlocked, 1)
+	case EventSingleflightShared:
+		atomic.AddInt64(&c.breakdownMerged, 1)
+	case EventTTLJittered:
+		atomic.AddInt64(&c.avalancheJittered, 1)
+	case EventLoaderError:
+		atomic.AddInt64(&c.loaderErrors, 1)
+	case EventStaleServed:
+		atomic.AddInt64(&c.staleServed, 1)
+	case EventRevalidation:
+		atomic.AddInt64(&c.revalidations, 1)
+	case EventFilterRejected:
+		atomic.AddInt64(&c.filterRejected, 1)
+	case EventLoaderSaturated:
+		atomic.AddInt64(&c.loaderSaturated, 1)
+	case EventNegativeHit:
+		atomic.AddInt64(&c.negativeHits, 1)
+	}
+
+	s.mu.RLock()
+	hooks := s.hooks
+	s.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(cacheName, event)
+	}
+}
+
+// recordQueueWait 通知回源排队时间
+func (s *protectionStats) recordQueueWait(cacheName string, wait time.Duration) {
+	s.mu.RLock()
+	hooks := s.waitHooks
+	s.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(cacheName, wait)
+	}
+}
+
+// GetStats 获取保护统计
+// 根保护器返回所有缓存的合计，ForCache 视图只返回该缓存的统计。
 func (p *CacheProtection) GetStats() *ProtectionStats {
-	return &ProtectionStats{}
+	if p.cacheName != "" {
+		return p.stats.counters(p.cacheName).snapshot()
+	}
+
+	total := &ProtectionStats{}
+	for _, s := range p.GetCacheStats() {
+		total.PenetrationBlocked += s.PenetrationBlocked
+		total.BreakdownMerged += s.BreakdownMerged
+		total.AvalancheJittered += s.AvalancheJittered
+		total.LoaderErrors += s.LoaderErrors
+		total.StaleServed += s.StaleServed
+		total.Revalidations += s.Revalidations
+		total.FilterRejected += s.FilterRejected
+		total.LoaderSaturated += s.LoaderSaturated
+		total.NegativeHits += s.NegativeHits
+	}
+	return total
+}
+
+// GetCacheStats 获取每个缓存的保护统计（未通过 ForCache 绑定缓存的调用计入空名称）
+func (p *CacheProtection) GetCacheStats() map[string]*ProtectionStats {
+	p.stats.mu.RLock()
+	defer p.stats.mu.RUnlock()
+	result := make(map[string]*ProtectionStats, len(p.stats.caches))
+	for name, c := range p.stats.caches {
+		result[name] = c.snapshot()
+	}
+	return result
+}
+
+// OnEvent 注册保护事件回调（用于导出指标），回调在调用方协程中同步执行
+func (p *CacheProtection) OnEvent(fn func(cacheName string, event ProtectionEvent)) {
+	p.stats.mu.Lock()
+	defer p.stats.mu.Unlock()
+	hooks := make([]func(string, ProtectionEvent), len(p.stats.hooks), len(p.stats.hooks)+1)
+	copy(hooks, p.stats.hooks)
+	p.stats.hooks = append(hooks, fn)
+}
+
+// OnQueueWait 注册回源排队时间回调（只有配置了 bulkhead 的缓存才会调用），回调在调用方协程中同步执行
+func (p *CacheProtection) OnQueueWait(fn func(cacheName string, wait time.Duration)) {
+	p.stats.mu.Lock()
+	defer p.stats.mu.Unlock()
+	hooks := make([]func(string, time.Duration), len(p.stats.waitHooks), len(p.stats.waitHooks)+1)
+	copy(hooks, p.stats.waitHooks)
+	p.stats.waitHooks = append(hooks, fn)
 }
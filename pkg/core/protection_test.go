@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -486,9 +487,156 @@ func TestProtectionStats(t *testing.T) {
 	stats := protection.GetStats()
 
 	if stats == nil {
-		t.Error("Expected non-nil stats")
+		t.Fatal("Expected non-nil stats")
+	}
+
+	ctx := context.Background()
+	users := protection.ForCache("users")
+	orders := protection.ForCache("orders")
+	miss := func() (interface{}, bool, error) { return nil, false, nil }
+	noopSet := func(interface{}, time.Duration) error { return nil }
+
+	// 空值标记命中
+	users.ProtectedGet(ctx, "user:1", func() (interface{}, bool, error) { return backend.NilMarker, true, nil },
+		func() (interface{}, error) { return "unused", nil }, noopSet)
+	// 回源失败
+	orders.ProtectedGet(ctx, "order:1", miss, func() (interface{}, error) { return nil, errors.New("db down") }, noopSet)
+	// 回源成功，TTL 加入抖动
+	orders.ProtectedGet(ctx, "order:2", miss, func() (interface{}, error) { return "book", nil }, noopSet, WithTTL(time.Minute))
+
+	// 并发回源共享结果
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			users.ProtectedGet(ctx, "user:2", miss, func() (interface{}, error) {
+				<-release
+				return "bob", nil
+			}, noopSet)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	userStats := users.GetStats()
+	if userStats.PenetrationBlocked != 1 {
+		t.Errorf("Expected 1 nil marker hit for users, got %d", userStats.PenetrationBlocked)
+	}
+	if userStats.BreakdownMerged == 0 {
+		t.Error("Expected shared singleflight calls for users")
+	}
+	orderStats := orders.GetStats()
+	if orderStats.LoaderErrors != 1 {
+		t.Errorf("Expected 1 loader error for orders, got %d", orderStats.LoaderErrors)
+	}
+	if orderStats.AvalancheJittered != 1 {
+		t.Errorf("Expected 1 jittered TTL for orders, got %d", orderStats.AvalancheJittered)
+	}
+
+	// 根保护器返回合计
+	total := protection.GetStats()
+	if total.LoaderErrors != 1 || total.PenetrationBlocked != 1 {
+		t.Errorf("Unexpected totals: %+v", total)
+	}
+	if len(protection.GetCacheStats()) != 2 {
+		t.Errorf("Expected stats for 2 caches, got %v", protection.GetCacheStats())
 	}
-	// 当前实现返回空统计，未来可扩展
+}
+
+// TestProtectedGetOptions 测试 ProtectedGet 单次调用选项
+func TestProtectedGetOptions(t *testing.T) {
+	ctx := context.Background()
+	miss := func() (interface{}, bool, error) { return nil, false, nil }
+
+	t.Run("TTL and jitter", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		var stored interface{}
+		var storedTTL time.Duration
+		cacheSet := func(v interface{}, ttl time.Duration) error {
+			stored, storedTTL = v, ttl
+			return nil
+		}
+
+		protection.ProtectedGet(ctx, "k1", miss, func() (interface{}, error) { return "v", nil }, cacheSet,
+			WithTTL(10*time.Minute), WithJitter(0))
+		if storedTTL != 10*time.Minute {
+			t.Errorf("Expected exact 10m TTL without jitter, got %v", storedTTL)
+		}
+		if stored != "v" {
+			t.Errorf("Expected raw value to be stored, got %v", stored)
+		}
+
+		// 未指定 TTL 时传 0，由后端使用 DefaultTTL
+		protection.ProtectedGet(ctx, "k2", miss, func() (interface{}, error) { return "v", nil }, cacheSet)
+		if storedTTL != 0 {
+			t.Errorf("Expected 0 TTL (backend default), got %v", storedTTL)
+		}
+
+		// 空值只包装一次，使用 WithEmptyTTL
+		protection.ProtectedGet(ctx, "k3", miss, func() (interface{}, error) { return nil, nil }, cacheSet,
+			WithEmptyTTL(time.Minute))
+		if stored != backend.NilMarker || storedTTL != time.Minute {
+			t.Errorf("Expected nil marker with 1m TTL, got %v / %v", stored, storedTTL)
+		}
+	})
+
+	t.Run("Loader timeout", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		cancelled := make(chan struct{})
+		_, err := ProtectedGet(ctx, protection, newTestCache(t), "slow", func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return "", ctx.Err()
+		}, WithLoaderTimeout(20*time.Millisecond))
+		if !errors.Is(err, ErrLoaderTimeout) {
+			t.Fatalf("Expected ErrLoaderTimeout, got %v", err)
+		}
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Error("Expected loader context to be cancelled")
+		}
+	})
+
+	t.Run("Typed", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		cache := newTestCache(t)
+
+		calls := 0
+		loader := func(ctx context.Context) (int, error) {
+			calls++
+			return 42, nil
+		}
+		for i := 0; i < 2; i++ {
+			v, err := ProtectedGet(ctx, protection, cache, "answer", loader, WithTTL(time.Minute))
+			if err != nil || v != 42 {
+				t.Fatalf("Expected 42, got %v (%v)", v, err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("Expected loader to run once, got %d", calls)
+		}
+
+		// 类型不匹配视为未命中，重新回源
+		cache.Set(ctx, "answer", "not an int", time.Minute)
+		v, _ := ProtectedGet(ctx, protection, cache, "answer", loader)
+		if v != 42 || calls != 2 {
+			t.Errorf("Expected reload on type mismatch, got %v after %d calls", v, calls)
+		}
+	})
+}
+
+func newTestCache(t *testing.T) CacheBackend {
+	t.Helper()
+	cache, err := backend.NewMemoryBackend(DefaultCacheConfig("protection-test"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
 }
 
 // BenchmarkBreakdownProtection 性能测试 - 击穿保护
@@ -551,3 +699,29 @@ func BenchmarkPenetrationProtection(b *testing.B) {
 		}
 	})
 }
+
+// TestConvertValue 测试把后端解码出的值转换为方法返回类型
+func TestConvertValue(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	u, err := ConvertValue[*user](map[string]interface{}{"id": float64(7), "name": "alice"})
+	if err != nil || u == nil || u.ID != 7 || u.Name != "alice" {
+		t.Errorf("Expected decoded user, got %+v (%v)", u, err)
+	}
+	n, err := ConvertValue[int](float64(3))
+	if err != nil || n != 3 {
+		t.Errorf("Expected 3, got %v (%v)", n, err)
+	}
+	if s, err := ConvertValue[string]("v"); err != nil || s != "v" {
+		t.Errorf("Expected value as is, got %v (%v)", s, err)
+	}
+	if v, err := ConvertValue[*user](nil); err != nil || v != nil {
+		t.Errorf("Expected zero value for nil, got %v (%v)", v, err)
+	}
+	if _, err := ConvertValue[int]("not a number"); err == nil {
+		t.Error("Expected error on type mismatch")
+	}
+}
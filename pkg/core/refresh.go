@@ -0,0 +1,297 @@
+package core
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RefreshConfig 写后刷新（refresh-after-write）调度配置
+type RefreshConfig struct {
+	MaxConcurrent int           // 同时执行的重载数上限
+	MaxKeys       int           // 最多跟踪的 key 数，超出时放弃最久未写入的 key
+	Timeout       time.Duration // 单次重载超时（0 表示不限制）
+}
+
+// DefaultRefreshConfig 默认写后刷新配置
+func DefaultRefreshConfig() *RefreshConfig {
+	return &RefreshConfig{
+		MaxConcurrent: 4,
+		MaxKeys:       10000,
+		Timeout:       30 * time.Second,
+	}
+}
+
+// RefreshStats 写后刷新统计
+type RefreshStats struct {
+	Tracked   int   // 当前跟踪的 key 数
+	Reloads   int64 // 成功的重载次数
+	Failures  int64 // 失败的重载次数
+	Idle      int64 // 写入后没有再被读取、因此停止刷新的 key 数
+	Cancelled int64 // 条目被移除或超出 MaxKeys 而取消的 key 数
+}
+
+// RefreshScheduler 写后刷新调度器
+// 每个 key 写入 refreshAfter 之后在后台重新回源并写回缓存，读取方不会因为过期而阻塞。
+// 只刷新写入后被读取过的 key；条目被移除时（Cancel）取消定时器和正在执行的重载。
+type RefreshScheduler struct {
+	config *RefreshConfig
+	sem    chan struct{} // 重载并发限制
+
+	mu      sync.Mutex
+	entries map[refreshKey]*refreshEntry
+	order   *list.List // 按写入时间排序，front 为最近写入
+	closed  bool
+
+	reloads   int64
+	failures  int64
+	idle      int64
+	cancelled int64
+}
+
+type refreshKey struct {
+	cache string
+	key   string
+}
+
+// refreshEntry 一个待刷新的 key，保存回源闭包（包含方法和参数）
+type refreshEntry struct {
+	id       refreshKey
+	reload   func(ctx context.Context) error
+	timer    *time.Timer
+	gen      uint64             // 每次写入递增，过期的定时器据此忽略
+	accessed bool               // 上次写入后是否被读取过
+	running  context.CancelFunc // 正在执行的重载
+	elem     *list.Element
+}
+
+// NewRefreshScheduler 创建写后刷新调度器
+func NewRefreshScheduler(config *RefreshConfig) *RefreshScheduler {
+	if config == nil {
+		config = DefaultRefreshConfig()
+	}
+	c := *config
+	defaults := DefaultRefreshConfig()
+	if c.MaxConcurrent <= 0 {
+		c.MaxConcurrent = defaults.MaxConcurrent
+	}
+	if c.MaxKeys <= 0 {
+		c.MaxKeys = defaults.MaxKeys
+	}
+	return &RefreshScheduler{
+		config:  &c,
+		sem:     make(chan struct{}, c.MaxConcurrent),
+		entries: make(map[refreshKey]*refreshEntry),
+		order:   list.New(),
+	}
+}
+
+// Schedule 在 after 之后重载 key（key 已在跟踪时替换回源闭包并重新计时）
+func (s *RefreshScheduler) Schedule(cacheName, key string, after time.Duration, reload func(ctx context.Context) error) {
+	if after <= 0 || reload == nil {
+		return
+	}
+	id := refreshKey{cache: cacheName, key: key}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	e, ok := s.entries[id]
+	if ok {
+		e.timer.Stop()
+		s.order.MoveToFront(e.elem)
+	} else {
+		e = &refreshEntry{id: id}
+		e.elem = s.order.PushFront(e)
+		s.entries[id] = e
+		for len(s.entries) > s.config.MaxKeys {
+			s.removeLocked(s.order.Back().Value.(*refreshEntry))
+			atomic.AddInt64(&s.cancelled, 1)
+		}
+	}
+
+	e.reload = reload
+	e.accessed = false
+	e.gen++
+	gen := e.gen
+	e.timer = time.AfterFunc(after, func() { s.fire(e, gen) })
+}
+
+// Touch 记录一次读取，只有被读取过的 key 才会在到期时刷新
+func (s *RefreshScheduler) Touch(cacheName, key string) {
+	s.mu.Lock()
+	if e, ok := s.entries[refreshKey{cache: cacheName, key: key}]; ok {
+		e.accessed = true
+	}
+	s.mu.Unlock()
+}
+
+// Cancel 停止刷新 key（条目被淘汰或删除时调用），正在执行的重载会被取消且不会写回
+func (s *RefreshScheduler) Cancel(cacheName, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[refreshKey{cache: cacheName, key: key}]; ok {
+		s.removeLocked(e)
+		atomic.AddInt64(&s.cancelled, 1)
+	}
+}
+
+// CancelPrefix 停止刷新缓存中所有以 prefix 开头的 key（prefix 为空时取消整个缓存）
+func (s *RefreshScheduler) CancelPrefix(cacheName, prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, e := range s.entries {
+		if id.cache == cacheName && strings.HasPrefix(id.key, prefix) {
+			s.removeLocked(e)
+			atomic.AddInt64(&s.cancelled, 1)
+		}
+	}
+}
+
+// Stats 返回刷新统计
+func (s *RefreshScheduler) Stats() *RefreshStats {
+	s.mu.Lock()
+	tracked := len(s.entries)
+	s.mu.Unlock()
+	return &RefreshStats{
+		Tracked:   tracked,
+		Reloads:   atomic.LoadInt64(&s.reloads),
+		Failures:  atomic.LoadInt64(&s.failures),
+		Idle:      atomic.LoadInt64(&s.idle),
+		Cancelled: atomic.LoadInt64(&s.cancelled),
+	}
+}
+
+// Close 停止所有定时器并取消正在执行的重载
+func (s *RefreshScheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, e := range s.entries {
+		s.removeLocked(e)
+	}
+}
+
+// fire 定时器到期：执行重载（受并发限制）
+func (s *RefreshScheduler) fire(e *refreshEntry, gen uint64) {
+	s.mu.Lock()
+	if s.closed || s.entries[e.id] != e || e.gen != gen {
+		s.mu.Unlock()
+		return
+	}
+	if !e.accessed {
+		// 写入后没人读，不值得刷新，让条目自然过期
+		s.removeLocked(e)
+		s.mu.Unlock()
+		atomic.AddInt64(&s.idle, 1)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if s.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), s.config.Timeout)
+	}
+	e.running = cancel
+	reload := e.reload
+	s.mu.Unlock()
+	defer cancel()
+
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		// 排队期间被取消
+		return
+	}
+	err := reload(ctx)
+	<-s.sem
+
+	if err != nil {
+		atomic.AddInt64(&s.failures, 1)
+	} else {
+		atomic.AddInt64(&s.reloads, 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries[e.id] != e {
+		return
+	}
+	e.running = nil
+	if e.gen != gen {
+		// 重载成功写回后已经重新计时
+		return
+	}
+	if err != nil {
+		// 失败时保留旧值，仍被读取的 key 下个周期再试
+		e.accessed = false
+		e.gen++
+		next := e.gen
+		e.timer = time.AfterFunc(s.retryAfter(), func() { s.fire(e, next) })
+		return
+	}
+	s.removeLocked(e)
+}
+
+// retryAfter 重载失败后的重试间隔
+func (s *RefreshScheduler) retryAfter() time.Duration {
+	if s.config.Timeout > 0 {
+		return s.config.Timeout
+	}
+	return time.Minute
+}
+
+// removeLocked 移除 key 并取消定时器和正在执行的重载（调用方持有 mu）
+func (s *RefreshScheduler) removeLocked(e *refreshEntry) {
+	delete(s.entries, e.id)
+	s.order.Remove(e.elem)
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	if e.running != nil {
+		e.running()
+	}
+}
+
+// WithRefreshAfter 写入 after 之后在后台重新回源（需要 CacheProtection 挂载了刷新调度器）
+func WithRefreshAfter(after time.Duration) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.refreshAfter = after }
+}
+
+// scheduleRefresh 写入成功后安排下一次刷新
+func (p *CacheProtection) scheduleRefresh(
+	key string,
+	loader func(context.Context) (interface{}, error),
+	cacheSet func(interface{}, time.Duration) error,
+	options *protectedGetOptions,
+) {
+	if options.refreshAfter <= 0 || p.refresher == nil {
+		return
+	}
+	p.refresher.Schedule(p.cacheName, key, options.refreshAfter, func(ctx context.Context) error {
+		// 重载被取消（条目已被移除）后不再写回
+		set := func(value interface{}, ttl time.Duration) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return cacheSet(value, ttl)
+		}
+		_, err, _ := p.singleFlyer.Do(p.flightKey(key), func() (interface{}, error) {
+			return p.loadAndStore(ctx, key, loader, set, options)
+		})
+		return err
+	})
+}
+
+// RefreshOptions 把注解中的 refresh_after（duration 字符串）转换为调用选项，无法解析时忽略
+func RefreshOptions(refreshAfter string) []ProtectedGetOption {
+	if d, err := time.ParseDuration(refreshAfter); err == nil && d > 0 {
+		return []ProtectedGetOption{WithRefreshAfter(d)}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitFor 轮询直到条件满足或超时
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestRefreshScheduler 测试写后刷新调度
+func TestRefreshScheduler(t *testing.T) {
+	t.Run("Reload accessed keys only", func(t *testing.T) {
+		s := NewRefreshScheduler(nil)
+		defer s.Close()
+
+		var hot, cold int32
+		s.Schedule("c", "hot", 10*time.Millisecond, func(ctx context.Context) error {
+			atomic.AddInt32(&hot, 1)
+			return nil
+		})
+		s.Schedule("c", "cold", 10*time.Millisecond, func(ctx context.Context) error {
+			atomic.AddInt32(&cold, 1)
+			return nil
+		})
+		s.Touch("c", "hot")
+
+		waitFor(t, func() bool { return s.Stats().Tracked == 0 })
+		if atomic.LoadInt32(&hot) != 1 || atomic.LoadInt32(&cold) != 0 {
+			t.Errorf("Expected only the accessed key to reload, got hot=%d cold=%d", hot, cold)
+		}
+		if stats := s.Stats(); stats.Reloads != 1 || stats.Idle != 1 {
+			t.Errorf("Unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("Cancel aborts running reload", func(t *testing.T) {
+		s := NewRefreshScheduler(nil)
+		defer s.Close()
+
+		started, aborted := make(chan struct{}), make(chan struct{})
+		s.Schedule("c", "k", time.Millisecond, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(aborted)
+			return ctx.Err()
+		})
+		s.Touch("c", "k")
+		<-started
+
+		s.Cancel("c", "k")
+		select {
+		case <-aborted:
+		case <-time.After(time.Second):
+			t.Fatal("Expected reload context to be cancelled")
+		}
+		if stats := s.Stats(); stats.Tracked != 0 || stats.Cancelled != 1 {
+			t.Errorf("Unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("Concurrency limit", func(t *testing.T) {
+		s := NewRefreshScheduler(&RefreshConfig{MaxConcurrent: 2})
+		defer s.Close()
+
+		var running, peak int32
+		release := make(chan struct{})
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			s.Schedule("c", key, time.Millisecond, func(ctx context.Context) error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				<-release
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+			s.Touch("c", key)
+		}
+
+		waitFor(t, func() bool { return atomic.LoadInt32(&running) == 2 })
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		waitFor(t, func() bool { return s.Stats().Reloads == 5 })
+		if p := atomic.LoadInt32(&peak); p != 2 {
+			t.Errorf("Expected at most 2 concurrent reloads, got %d", p)
+		}
+	})
+
+	t.Run("Max keys", func(t *testing.T) {
+		s := NewRefreshScheduler(&RefreshConfig{MaxKeys: 2})
+		defer s.Close()
+
+		noop := func(ctx context.Context) error { return nil }
+		for _, key := range []string{"a", "b", "c"} {
+			s.Schedule("c", key, time.Hour, noop)
+		}
+		if stats := s.Stats(); stats.Tracked != 2 || stats.Cancelled != 1 {
+			t.Errorf("Expected oldest key to be dropped, got %+v", stats)
+		}
+	})
+}
+
+// TestRefreshAfterWrite 测试 ProtectedGet 的写后刷新以及条目被淘汰后取消刷新
+func TestRefreshAfterWrite(t *testing.T) {
+	ctx := context.Background()
+	manager := NewCacheManager()
+	defer manager.Close()
+
+	cache, err := manager.GetCache("products")
+	if err != nil {
+		t.Fatalf("GetCache failed: %v", err)
+	}
+	protection := manager.GetProtection().ForCache("products")
+
+	var version int32
+	loader := func(ctx context.Context) (int32, error) {
+		return atomic.AddInt32(&version, 1), nil
+	}
+	get := func() int32 {
+		v, err := ProtectedGet(ctx, protection, cache, "p1", loader,
+			WithTTL(time.Minute), WithRefreshAfter(20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("ProtectedGet failed: %v", err)
+		}
+		return v
+	}
+
+	if v := get(); v != 1 {
+		t.Fatalf("Expected first load, got %d", v)
+	}
+	// 读取一次，到期后在后台刷新，读取方不阻塞
+	if v := get(); v != 1 {
+		t.Fatalf("Expected cached value, got %d", v)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&version) == 2 })
+	waitFor(t, func() bool { return get() == 2 })
+
+	// 条目被删除后不再刷新
+	refresher := manager.GetProtection().Refresher()
+	if refresher.Stats().Tracked != 1 {
+		t.Fatalf("Expected key to be tracked, got %+v", refresher.Stats())
+	}
+	cache.Delete(ctx, "p1")
+	if stats := refresher.Stats(); stats.Tracked != 0 {
+		t.Errorf("Expected refresh to be cancelled on delete, got %+v", stats)
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSingleFlightCancelled 等待中的共享回源被 CancelSingleFlight 取消
+var ErrSingleFlightCancelled = errors.New("singleflight cancelled")
+
+// flight 一次共享回源
+type flight struct {
+	aborted chan struct{}      // CancelSingleFlight 时关闭，唤醒所有等待者
+	cancel  context.CancelFunc // 取消共享回源的 ctx
+	once    sync.Once
+}
+
+func (f *flight) abort() {
+	f.once.Do(func() {
+		close(f.aborted)
+		if f.cancel != nil {
+			f.cancel()
+		}
+	})
+}
+
+// flightRegistry 正在进行的共享回源（所有视图共享）
+type flightRegistry struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+func newFlightRegistry() *flightRegistry {
+	return &flightRegistry{flights: make(map[string]*flight)}
+}
+
+// join 获取 key 对应的 flight，没有时创建
+func (r *flightRegistry) join(key string) *flight {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.flights[key]
+	if !ok {
+		f = &flight{aborted: make(chan struct{})}
+		r.flights[key] = f
+	}
+	return f
+}
+
+// setCancel 记录共享回源的 cancel（flight 已被取消时立即取消）
+func (r *flightRegistry) setCancel(f *flight, cancel context.CancelFunc) {
+	r.mu.Lock()
+	f.cancel = cancel
+	r.mu.Unlock()
+	select {
+	case <-f.aborted:
+		cancel()
+	default:
+	}
+}
+
+// done 共享回源结束
+func (r *flightRegistry) done(key string, f *flight) {
+	r.mu.Lock()
+	if r.flights[key] == f {
+		delete(r.flights, key)
+	}
+	r.mu.Unlock()
+}
+
+// cancel 取消 key 上的共享回源并唤醒等待者
+func (r *flightRegistry) cancel(key string) {
+	r.mu.Lock()
+	f, ok := r.flights[key]
+	delete(r.flights, key)
+	r.mu.Unlock()
+	if ok {
+		f.abort()
+	}
+}
+
+// shareLoad 合并同一个 key 的并发回源
+// 共享回源使用与调用方解耦的 ctx（保留 ctx 中的值），某个调用方取消只让它自己停止等待；
+// fn 自己负责超时（见 callWithTimeout）。返回：(结果，错误，是否与其他调用方共享)
+func (p *CacheProtection) shareLoad(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error, bool) {
+	flightKey := p.flightKey(key)
+	f := p.flights.join(flightKey)
+
+	ch := p.singleFlyer.DoChan(flightKey, func() (interface{}, error) {
+		loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		defer cancel()
+		p.flights.setCancel(f, cancel)
+		defer p.flights.done(flightKey, f)
+		return fn(loadCtx)
+	})
+
+	select {
+	case r := <-ch:
+		if r.Shared {
+			p.stats.record(p.cacheName, EventSingleflightShared)
+		}
+		return r.Val, r.Err, r.Shared
+	case <-ctx.Done():
+		return nil, ctx.Err(), false
+	case <-f.aborted:
+		return nil, ErrSingleFlightCancelled, false
+	}
+}
+
+// callWithTimeout 在 timeout 内执行 loader，超时返回 ErrLoaderTimeout 并取消 loader 的 ctx
+// 不响应 ctx 的 loader 会在后台继续运行，但不再阻塞调用方。
+func callWithTimeout(ctx context.Context, timeout time.Duration, loader func(context.Context) (interface{}, error)) (interface{}, error) {
+	if timeout <= 0 {
+		return loader(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type loadResult struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan loadResult, 1)
+	go func() {
+		value, err := loader(ctx)
+		done <- loadResult{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %v", ErrLoaderTimeout, timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
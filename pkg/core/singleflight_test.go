@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSingleFlightContext 测试共享回源时每个调用方按自己的 ctx 停止等待
+func TestSingleFlightContext(t *testing.T) {
+	t.Run("Cancelled caller does not affect others", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		release := make(chan struct{})
+		var calls int32
+		fn := func() (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return "data", nil
+		}
+
+		cancelled, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		var cancelledErr, otherErr error
+		var otherValue interface{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, cancelledErr, _ = protection.ApplyBreakdownProtection(cancelled, "k", fn)
+		}()
+		go func() {
+			defer wg.Done()
+			otherValue, otherErr, _ = protection.ApplyBreakdownProtection(context.Background(), "k", fn)
+		}()
+
+		waitFor(t, func() bool { return atomic.LoadInt32(&calls) == 1 })
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if !errors.Is(cancelledErr, context.Canceled) {
+			t.Errorf("Expected cancelled caller to get context.Canceled, got %v", cancelledErr)
+		}
+		if otherErr != nil || otherValue != "data" {
+			t.Errorf("Expected other caller to get shared result, got %v (%v)", otherValue, otherErr)
+		}
+		if calls != 1 {
+			t.Errorf("Expected one shared load, got %d", calls)
+		}
+	})
+
+	t.Run("Detached loader context", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		loaderErr := make(chan error, 1)
+
+		go protection.protectedGet(ctx, "k", func() (interface{}, bool, error) { return nil, false, nil },
+			func(loadCtx context.Context) (interface{}, error) {
+				cancel()
+				time.Sleep(20 * time.Millisecond)
+				loaderErr <- loadCtx.Err()
+				return "v", nil
+			}, func(interface{}, time.Duration) error { return nil }, nil)
+
+		if err := <-loaderErr; err != nil {
+			t.Errorf("Expected shared loader to outlive the caller's ctx, got %v", err)
+		}
+	})
+
+	t.Run("Hung loader times out", func(t *testing.T) {
+		config := DefaultProtectionConfig()
+		config.LoaderTimeout = 20 * time.Millisecond
+		protection := NewCacheProtection(config)
+
+		hang := make(chan struct{})
+		defer close(hang)
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err, _ := protection.ApplyBreakdownProtection(context.Background(), "hung", func() (interface{}, error) {
+					<-hang
+					return nil, nil
+				})
+				if !errors.Is(err, ErrLoaderTimeout) {
+					t.Errorf("Expected ErrLoaderTimeout, got %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("CancelSingleFlight aborts waiters", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		started := make(chan struct{})
+		loaderCancelled := make(chan struct{})
+		var startOnce, cancelOnce sync.Once
+
+		var wg sync.WaitGroup
+		errs := make([]error, 3)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i], _ = protection.ForCache("users").applyBreakdownProtection(context.Background(), "k",
+					func(ctx context.Context) (interface{}, error) {
+						startOnce.Do(func() { close(started) })
+						<-ctx.Done()
+						cancelOnce.Do(func() { close(loaderCancelled) })
+						return nil, ctx.Err()
+					})
+			}(i)
+		}
+
+		<-started
+		time.Sleep(10 * time.Millisecond)
+		protection.ForCache("users").CancelSingleFlight("k")
+		wg.Wait()
+
+		for i, err := range errs {
+			if !errors.Is(err, ErrSingleFlightCancelled) && !errors.Is(err, context.Canceled) {
+				t.Errorf("Waiter %d: expected ErrSingleFlightCancelled, got %v", i, err)
+			}
+		}
+		select {
+		case <-loaderCancelled:
+		case <-time.After(time.Second):
+			t.Error("Expected shared loader ctx to be cancelled")
+		}
+	})
+}
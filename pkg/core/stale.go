@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"encoding/gob"
+	"time"
+)
+
+// staleEntry 启用 stale-while-revalidate / stale-if-error 时写入后端的信封
+// 后端 TTL 为硬过期时间（软 TTL + 重验证窗口 + 出错宽限期），信封内记录软过期和重验证截止时间。
+type staleEntry struct {
+	Marker     int         `json:"__go_cache_stale" msgpack:"__go_cache_stale"` // 固定为 1，用于识别经过序列化往返后的信封
+	Value      interface{} `json:"value" msgpack:"value"`
+	FreshUntil int64       `json:"fresh_until" msgpack:"fresh_until"` // 软过期时间（UnixMilli）
+	StaleUntil int64       `json:"stale_until" msgpack:"stale_until"` // 后台重验证截止时间（UnixMilli）
+}
+
+func init() {
+	gob.Register(&staleEntry{})
+}
+
+// fresh 是否仍在软 TTL 内
+func (e *staleEntry) fresh(now time.Time) bool {
+	return now.UnixMilli() < e.FreshUntil
+}
+
+// revalidatable 是否处于 stale-while-revalidate 窗口
+func (e *staleEntry) revalidatable(now time.Time) bool {
+	return now.UnixMilli() < e.StaleUntil
+}
+
+// servableOnError 回源失败时是否仍可以返回旧值
+func (e *staleEntry) servableOnError(now time.Time, grace time.Duration) bool {
+	return grace > 0 && now.UnixMilli() < e.StaleUntil+grace.Milliseconds()
+}
+
+// unwrapStaleEntry 识别信封（内存后端保存的是指针，JSON/msgpack 后端读回来是 map）
+func unwrapStaleEntry(value interface{}) (*staleEntry, bool) {
+	switch v := value.(type) {
+	case *staleEntry:
+		return v, true
+	case staleEntry:
+		return &v, true
+	case map[string]interface{}:
+		if marker, ok := toInt64(v["__go_cache_stale"]); !ok || marker != 1 {
+			return nil, false
+		}
+		fresh, _ := toInt64(v["fresh_until"])
+		stale, _ := toInt64(v["stale_until"])
+		return &staleEntry{Marker: 1, Value: v["value"], FreshUntil: fresh, StaleUntil: stale}, true
+	}
+	return nil, false
+}
+
+// toInt64 把反序列化得到的数字转成 int64（JSON 是 float64，msgpack 按大小解码成各种整数类型）
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return int64(n), true
+	case float32:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// UnwrapStale 返回信封中的值（不是信封时原样返回）
+// 启用了 stale 选项的缓存在后端保存的是信封，绕过 ProtectedGet 直接读取时用它解包。
+func UnwrapStale(value interface{}) interface{} {
+	if entry, ok := unwrapStaleEntry(value); ok {
+		return entry.Value
+	}
+	return value
+}
+
+// WithStaleWhileRevalidate 软 TTL 过期后的 window 内直接返回旧值，并在后台刷新（singleflight 去重）
+// 需要同时设置 WithTTL（软 TTL），否则不生效。
+func WithStaleWhileRevalidate(window time.Duration) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.staleWhileRevalidate = window }
+}
+
+// WithStaleIfError 回源失败时，在重验证窗口结束后的 grace 内继续返回旧值
+// 需要同时设置 WithTTL（软 TTL），否则不生效。
+func WithStaleIfError(grace time.Duration) ProtectedGetOption {
+	return func(o *protectedGetOptions) { o.staleIfError = grace }
+}
+
+// SetCacheOptions 设置某个缓存的默认调用选项（优先级：ProtectionConfig < 缓存默认 < 单次调用）
+func (p *CacheProtection) SetCacheOptions(cacheName string, opts ...ProtectedGetOption) {
+	if len(opts) == 0 {
+		p.cacheOptions.Delete(cacheName)
+		return
+	}
+	p.cacheOptions.Store(cacheName, append([]ProtectedGetOption(nil), opts...))
+}
+
+// staleEnabled 本次调用是否写入信封
+func (o *protectedGetOptions) staleEnabled() bool {
+	return o.ttl > 0 && (o.staleWhileRevalidate > 0 || o.staleIfError > 0)
+}
+
+// revalidate 在后台刷新软过期的条目，同一个 key 同时只有一个刷新
+func (p *CacheProtection) revalidate(
+	ctx context.Context,
+	key string,
+	loader func(context.Context) (interface{}, error),
+	cacheSet func(interface{}, time.Duration) error,
+	options *protectedGetOptions,
+) {
+	// 请求结束后刷新仍要继续
+	ctx = context.WithoutCancel(ctx)
+	p.singleFlyer.DoChan(p.flightKey(key), func() (interface{}, error) {
+		p.stats.record(p.cacheName, EventRevalidation)
+		return p.loadAndStore(ctx, key, loader, cacheSet, options)
+	})
+}
+
+// StaleOptions 把注解中的 stale / stale_if_error（duration 字符串）转换为调用选项
+// 为空或无法解析的值被忽略（使用缓存默认选项）。
+func StaleOptions(stale, staleIfError string) []ProtectedGetOption {
+	var opts []ProtectedGetOption
+	if d, err := time.ParseDuration(stale); err == nil && d > 0 {
+		opts = append(opts, WithStaleWhileRevalidate(d))
+	}
+	if d, err := time.ParseDuration(staleIfError); err == nil && d > 0 {
+		opts = append(opts, WithStaleIfError(d))
+	}
+	return opts
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// staleStore 测试用的单 key 存储，可以直接修改信封中的过期时间
+type staleStore struct {
+	mu    sync.Mutex
+	value interface{}
+	ttl   time.Duration
+	found bool
+}
+
+func (s *staleStore) get() (interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value, s.found, nil
+}
+
+func (s *staleStore) set(v interface{}, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.value, s.ttl, s.found = v, ttl, true
+	return nil
+}
+
+// age 把信封的时间往前推，模拟已经过去了 d
+func (s *staleStore) age(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.value.(*staleEntry)
+	entry.FreshUntil -= d.Milliseconds()
+	entry.StaleUntil -= d.Milliseconds()
+}
+
+// TestStaleWhileRevalidate 测试软过期后返回旧值并在后台刷新
+func TestStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	protection := NewCacheProtection(nil)
+	store := &staleStore{}
+	opts := []ProtectedGetOption{WithTTL(time.Minute), WithJitter(0), WithStaleWhileRevalidate(5 * time.Minute)}
+
+	var calls int32
+	refreshed := make(chan struct{}, 10)
+	loader := func() (interface{}, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n > 1 {
+			refreshed <- struct{}{}
+		}
+		return int(n), nil
+	}
+
+	v, err := protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...)
+	if err != nil || v != 1 {
+		t.Fatalf("Expected first load to return 1, got %v (%v)", v, err)
+	}
+	if store.ttl != 6*time.Minute {
+		t.Errorf("Expected hard TTL of soft TTL + window (6m), got %v", store.ttl)
+	}
+
+	// 软 TTL 内：直接命中
+	v, _ = protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...)
+	if v != 1 || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("Expected fresh hit, got %v after %d calls", v, calls)
+	}
+
+	// 软过期：返回旧值，后台刷新
+	store.age(2 * time.Minute)
+	v, err = protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...)
+	if err != nil || v != 1 {
+		t.Fatalf("Expected stale value 1, got %v (%v)", v, err)
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected background revalidation")
+	}
+
+	// 刷新后写回新值
+	time.Sleep(10 * time.Millisecond)
+	v, _ = protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...)
+	if v != 2 {
+		t.Errorf("Expected refreshed value 2, got %v", v)
+	}
+
+	stats := protection.GetStats()
+	if stats.StaleServed != 1 || stats.Revalidations != 1 {
+		t.Errorf("Expected 1 stale served and 1 revalidation, got %+v", stats)
+	}
+}
+
+// TestStaleRevalidateDedup 测试并发读取软过期条目时只刷新一次
+func TestStaleRevalidateDedup(t *testing.T) {
+	ctx := context.Background()
+	protection := NewCacheProtection(nil)
+	store := &staleStore{}
+	opts := []ProtectedGetOption{WithTTL(time.Minute), WithStaleWhileRevalidate(time.Minute)}
+
+	started, release := make(chan struct{}, 10), make(chan struct{})
+	var calls int32
+	loader := func() (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		return "v", nil
+	}
+	protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...)
+	store.age(90 * time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...); v != "v" || err != nil {
+				t.Errorf("Expected stale value, got %v (%v)", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Expected background revalidation")
+	}
+	close(release)
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected exactly one background refresh, got %d loader calls", n)
+	}
+}
+
+// TestStaleIfError 测试回源失败时在宽限期内返回旧值
+func TestStaleIfError(t *testing.T) {
+	ctx := context.Background()
+	protection := NewCacheProtection(nil)
+	store := &staleStore{}
+	opts := []ProtectedGetOption{WithTTL(time.Minute), WithStaleIfError(10 * time.Minute)}
+
+	loadErr := errors.New("backend down")
+	fail := false
+	loader := func() (interface{}, error) {
+		if fail {
+			return nil, loadErr
+		}
+		return "old", nil
+	}
+	protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...)
+	fail = true
+
+	// 没有重验证窗口：软过期后同步回源，失败时返回旧值
+	store.age(5 * time.Minute)
+	v, err := protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...)
+	if err != nil || v != "old" {
+		t.Fatalf("Expected stale value on loader error, got %v (%v)", v, err)
+	}
+
+	// 超过宽限期：返回错误
+	store.age(10 * time.Minute)
+	if _, err := protection.ProtectedGet(ctx, "k", store.get, loader, store.set, opts...); !errors.Is(err, loadErr) {
+		t.Errorf("Expected loader error after grace period, got %v", err)
+	}
+}
+
+// TestStaleOptionsResolution 测试缓存默认选项、注解解析和 JSON 往返
+func TestStaleOptionsResolution(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Per cache defaults", func(t *testing.T) {
+		protection := NewCacheProtection(nil)
+		protection.SetCacheOptions("users", WithStaleWhileRevalidate(time.Minute))
+
+		store := &staleStore{}
+		protection.ForCache("users").ProtectedGet(ctx, "k", store.get, func() (interface{}, error) { return "v", nil },
+			store.set, WithTTL(time.Minute), WithJitter(0))
+		if _, ok := store.value.(*staleEntry); !ok || store.ttl != 2*time.Minute {
+			t.Errorf("Expected envelope with 2m hard TTL, got %T / %v", store.value, store.ttl)
+		}
+
+		// 其他缓存不受影响
+		store = &staleStore{}
+		protection.ForCache("orders").ProtectedGet(ctx, "k", store.get, func() (interface{}, error) { return "v", nil },
+			store.set, WithTTL(time.Minute), WithJitter(0))
+		if store.value != "v" {
+			t.Errorf("Expected raw value for cache without stale options, got %v", store.value)
+		}
+	})
+
+	t.Run("Annotation", func(t *testing.T) {
+		options := &protectedGetOptions{}
+		for _, opt := range StaleOptions("5m", "invalid") {
+			opt(options)
+		}
+		if options.staleWhileRevalidate != 5*time.Minute || options.staleIfError != 0 {
+			t.Errorf("Unexpected options: %+v", options)
+		}
+	})
+
+	t.Run("JSON round trip", func(t *testing.T) {
+		data, _ := json.Marshal(&staleEntry{Marker: 1, Value: "v", FreshUntil: 1, StaleUntil: 2})
+		var decoded interface{}
+		json.Unmarshal(data, &decoded)
+
+		entry, ok := unwrapStaleEntry(decoded)
+		if !ok || entry.Value != "v" || entry.FreshUntil != 1 || entry.StaleUntil != 2 {
+			t.Errorf("Expected envelope to survive JSON round trip, got %+v", entry)
+		}
+		if UnwrapStale(map[string]interface{}{"value": "x"}) == "x" {
+			t.Error("Expected plain maps not to be treated as envelopes")
+		}
+	})
+}
+
+// TestStaleEntrySerializers 测试 stale 信封经过各集群序列化器往返后仍能识别
+func TestStaleEntrySerializers(t *testing.T) {
+	freshUntil := time.Now().UnixMilli()
+	for _, name := range []string{"json", "gob", "msgpack"} {
+		decoded := serializerRoundTrip(t, name, &staleEntry{Marker: 1, Value: "v", FreshUntil: freshUntil, StaleUntil: freshUntil + 1})
+		entry, ok := unwrapStaleEntry(decoded)
+		if !ok || entry.Value != "v" || entry.FreshUntil != freshUntil || entry.StaleUntil != freshUntil+1 {
+			t.Errorf("%s: expected stale envelope, got %#v", name, decoded)
+		}
+	}
+	if _, ok := unwrapStaleEntry(map[string]interface{}{"__go_cache_stale": int8(1), "value": "v"}); !ok {
+		t.Error("Expected integer markers to be accepted")
+	}
+}
@@ -0,0 +1,362 @@
+package core
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coderiser/go-cache/pkg/backend"
+)
+
+// ErrTenancyDisabled 未启用多租户
+var ErrTenancyDisabled = errors.New("multi-tenancy is not enabled")
+
+// ErrInvalidTenant 租户为空或包含 key 分隔符 ':'
+var ErrInvalidTenant = errors.New("invalid tenant")
+
+// ErrSharedCacheGeneration 代数模式只支持进程内缓存（代数只保存在本实例内存中）
+var ErrSharedCacheGeneration = errors.New("tenant generation flush mode requires process-local caches")
+
+// TenantResolver 从 ctx 解析租户，返回空字符串表示不区分租户（key 不加前缀）
+type TenantResolver func(ctx context.Context) string
+
+// TenantFlushMode 租户清空方式
+type TenantFlushMode string
+
+const (
+	// TenantFlushPrefix 按前缀删除租户的 key（后端需实现 PrefixDeleter）
+	TenantFlushPrefix TenantFlushMode = "prefix"
+	// TenantFlushGeneration 递增租户代数，O(1) 使租户的 key 不可达，旧 key 随 TTL 或淘汰释放
+	// 代数只保存在本实例内存中，重启或多实例共享后端时会不一致，因此只允许进程内缓存（内存后端）。
+	TenantFlushGeneration TenantFlushMode = "generation"
+)
+
+// TenantConfig 多租户配置
+type TenantConfig struct {
+	// Resolver 租户解析；WithTenant 设置的租户优先，为 nil 时只使用 WithTenant
+	Resolver TenantResolver
+	// FlushMode FlushTenant 的清空方式（默认按前缀）
+	FlushMode TenantFlushMode
+	// Quota 每个租户在每个缓存中的条目上限（0 表示不限制），超出时淘汰该租户最早写入的条目
+	// 只对实现了 RemovalNotifier 的后端（内存后端）生效，其他后端无法感知过期，计数会失真。
+	Quota int
+	// Quotas 按租户覆盖 Quota
+	Quotas map[string]int
+}
+
+// DefaultTenantConfig 默认多租户配置
+func DefaultTenantConfig() *TenantConfig {
+	return &TenantConfig{FlushMode: TenantFlushPrefix}
+}
+
+// quotaFor 返回租户的条目上限
+func (c *TenantConfig) quotaFor(tenant string) int {
+	if q, ok := c.Quotas[tenant]; ok {
+		return q
+	}
+	return c.Quota
+}
+
+type tenantContextKey struct{}
+
+// WithTenant 返回携带租户的 ctx
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext 返回 WithTenant 设置的租户
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// tenantState 多租户运行时状态：代数和配额跟踪
+//
+// 与配置分开保存，重新配置时保留；不依赖管理器的锁，可以在后端的移除回调（后端持锁）中调用。
+type tenantState struct {
+	mu          sync.Mutex
+	generations map[string]uint64
+	caches      map[string]*tenantEntries // 缓存名 → 受配额限制的租户写入的 key
+}
+
+// tenantEntries 单个缓存中各租户写入的 key
+type tenantEntries struct {
+	elems   map[string]*list.Element // key → 元素（Value 为 tenantEntry）
+	tenants map[string]*list.List    // 租户 → 按写入顺序排列的元素
+}
+
+type tenantEntry struct {
+	tenant, key string
+}
+
+// generation 返回租户当前代数
+func (s *tenantState) generation(tenant string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generations[tenant]
+}
+
+// bump 递增租户代数
+func (s *tenantState) bump(tenant string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generations == nil {
+		s.generations = make(map[string]uint64)
+	}
+	s.generations[tenant]++
+	return s.generations[tenant]
+}
+
+// admit 记录租户写入的 key，返回超出配额需要淘汰的 key（最早写入的在前）
+func (s *tenantState) admit(cacheName, tenant, key string, quota int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.caches == nil {
+		s.caches = make(map[string]*tenantEntries)
+	}
+	entries := s.caches[cacheName]
+	if entries == nil {
+		entries = &tenantEntries{elems: make(map[string]*list.Element), tenants: make(map[string]*list.List)}
+		s.caches[cacheName] = entries
+	}
+	order := entries.tenants[tenant]
+	if order == nil {
+		order = list.New()
+		entries.tenants[tenant] = order
+	}
+	if e, ok := entries.elems[key]; ok {
+		// 覆盖写入视为最新
+		order.MoveToBack(e)
+		return nil
+	}
+	entries.elems[key] = order.PushBack(tenantEntry{tenant: tenant, key: key})
+
+	var evicted []string
+	for order.Len() > quota {
+		oldest := order.Remove(order.Front()).(tenantEntry)
+		delete(entries.elems, oldest.key)
+		evicted = append(evicted, oldest.key)
+	}
+	return evicted
+}
+
+// remove 条目被后端移除（过期、淘汰、删除）后停止跟踪
+func (s *tenantState) remove(cacheName, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.caches[cacheName]
+	if entries == nil {
+		return
+	}
+	e, ok := entries.elems[key]
+	if !ok {
+		return
+	}
+	delete(entries.elems, key)
+	entry := e.Value.(tenantEntry)
+	order := entries.tenants[entry.tenant]
+	order.Remove(e)
+	if order.Len() == 0 {
+		delete(entries.tenants, entry.tenant)
+	}
+}
+
+// usage 返回租户在缓存中被跟踪的条目数
+func (s *tenantState) usage(cacheName, tenant string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entries := s.caches[cacheName]; entries != nil {
+		if order := entries.tenants[tenant]; order != nil {
+			return order.Len()
+		}
+	}
+	return 0
+}
+
+// dropCache 缓存被替换或移除后清除跟踪
+func (s *tenantState) dropCache(cacheName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.caches, cacheName)
+}
+
+// SetTenantConfig 启用多租户（nil 表示关闭）；代数模式下已有非进程内缓存时返回 ErrSharedCacheGeneration
+//
+// 启用后 Execute 和 Invalidate 按 ctx 解析租户，key 自动加上 "租户:" 前缀（代数模式为 "租户:代数:"），
+// singleflight 和刷新也按加前缀后的 key 区分租户，存在性过滤器则按未加前缀的注解 key 检查和加入
+// （与 bootstrap 灌入的业务 key 一致，各租户共享）；GetCache 返回的后端不做转换。
+func (m *cacheManagerImpl) SetTenantConfig(cfg *TenantConfig) error {
+	if cfg != nil {
+		if cfg.FlushMode == "" {
+			cfg.FlushMode = TenantFlushPrefix
+		}
+		if cfg.FlushMode != TenantFlushPrefix && cfg.FlushMode != TenantFlushGeneration {
+			return fmt.Errorf("unknown tenant flush mode: %s", cfg.FlushMode)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cfg != nil && cfg.FlushMode == TenantFlushGeneration {
+		for name, c := range m.caches {
+			if !isProcessLocal(c) {
+				return fmt.Errorf("%w: cache %s", ErrSharedCacheGeneration, name)
+			}
+		}
+	}
+	m.tenantConfig = cfg
+	return nil
+}
+
+// isProcessLocal 缓存数据只存在于本进程（内存后端）
+func isProcessLocal(c CacheBackend) bool {
+	_, ok := c.(*backend.MemoryBackend)
+	return ok
+}
+
+// checkTenantCacheLocked 代数模式下拒绝非进程内缓存（调用方持有锁）
+func (m *cacheManagerImpl) checkTenantCacheLocked(name string, c CacheBackend) error {
+	if m.tenantConfig == nil || m.tenantConfig.FlushMode != TenantFlushGeneration || isProcessLocal(c) {
+		return nil
+	}
+	return fmt.Errorf("%w: cache %s", ErrSharedCacheGeneration, name)
+}
+
+// tenancy 返回当前多租户配置（未启用时为 nil）
+func (m *cacheManagerImpl) tenancy() *TenantConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tenantConfig
+}
+
+// validateTenant 租户不能为空，也不能包含 ':'（否则 "acme" 与 "acme:eu"、代数前缀之间会互相冲突）
+func validateTenant(tenant string) error {
+	if tenant == "" {
+		return fmt.Errorf("%w: tenant cannot be empty", ErrInvalidTenant)
+	}
+	if strings.Contains(tenant, ":") {
+		return fmt.Errorf("%w: %q contains ':'", ErrInvalidTenant, tenant)
+	}
+	return nil
+}
+
+// resolveTenant 解析 ctx 中的租户：WithTenant 优先，其次为 Resolver；没有租户时返回空字符串
+func (m *cacheManagerImpl) resolveTenant(ctx context.Context) (string, error) {
+	cfg := m.tenancy()
+	if cfg == nil {
+		return "", nil
+	}
+	tenant := TenantFromContext(ctx)
+	if tenant == "" && cfg.Resolver != nil {
+		tenant = cfg.Resolver(ctx)
+	}
+	if tenant == "" {
+		return "", nil
+	}
+	if err := validateTenant(tenant); err != nil {
+		return "", err
+	}
+	return tenant, nil
+}
+
+// tenantPrefix 返回租户 key 前缀
+func (m *cacheManagerImpl) tenantPrefix(cfg *TenantConfig, tenant string) string {
+	if cfg.FlushMode == TenantFlushGeneration {
+		return tenant + ":" + strconv.FormatUint(m.tenants.generation(tenant), 10) + ":"
+	}
+	return tenant + ":"
+}
+
+// scopeKey 按 ctx 中的租户给 key 加前缀，返回租户和实际 key；租户非法时返回 ErrInvalidTenant
+func (m *cacheManagerImpl) scopeKey(ctx context.Context, key string) (string, string, error) {
+	tenant, err := m.resolveTenant(ctx)
+	if err != nil || tenant == "" {
+		return "", key, err
+	}
+	return tenant, m.tenantPrefix(m.tenancy(), tenant) + key, nil
+}
+
+// enforceQuota 记录租户写入，淘汰超出配额的最早条目
+func (m *cacheManagerImpl) enforceQuota(ctx context.Context, cacheName string, cache CacheBackend, tenant, key string) {
+	cfg := m.tenancy()
+	if cfg == nil || tenant == "" {
+		return
+	}
+	quota := cfg.quotaFor(tenant)
+	if quota <= 0 {
+		return
+	}
+	if _, ok := cache.(backend.RemovalNotifier); !ok {
+		return
+	}
+	for _, evicted := range m.tenants.admit(cacheName, tenant, key, quota) {
+		if err := cache.Delete(ctx, evicted); err != nil {
+			log.Printf("[WARN] Tenant %s over quota in %s, failed to evict %s: %v", tenant, cacheName, evicted, err)
+		}
+		m.GetProtection().refresher.Cancel(cacheName, evicted)
+	}
+}
+
+// FlushTenant 使租户在所有缓存中的数据失效
+//
+// 前缀模式在各缓存中删除 "租户:" 前缀的 key，不支持前缀删除的缓存返回错误；代数模式只递增本实例的租户代数
+// （代数模式下只有进程内缓存，其他实例在收到广播后按前缀删除自己的数据）。
+// 设置了失效总线时两种模式都会广播前缀失效，其他实例按前缀删除。
+func (m *cacheManagerImpl) FlushTenant(ctx context.Context, tenant string) error {
+	cfg := m.tenancy()
+	if cfg == nil {
+		return ErrTenancyDisabled
+	}
+	if err := validateTenant(tenant); err != nil {
+		return err
+	}
+	prefix := tenant + ":"
+
+	m.mu.RLock()
+	caches := make(map[string]CacheBackend, len(m.caches))
+	for name, c := range m.caches {
+		caches[name] = c
+	}
+	bus := m.bus
+	m.mu.RUnlock()
+
+	if cfg.FlushMode == TenantFlushGeneration {
+		m.tenants.bump(tenant)
+	}
+
+	refresher := m.GetProtection().refresher
+	var firstErr error
+	for name, c := range caches {
+		refresher.CancelPrefix(name, prefix)
+		if cfg.FlushMode == TenantFlushPrefix {
+			pd, ok := c.(backend.PrefixDeleter)
+			if !ok {
+				err := fmt.Errorf("cache %s does not support prefix deletion", name)
+				log.Printf("[WARN] FlushTenant %s: %v", tenant, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if _, err := pd.DeletePrefix(ctx, prefix); err != nil {
+				log.Printf("[WARN] FlushTenant %s: failed to flush %s: %v", tenant, name, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+		}
+		if bus != nil {
+			message := &backend.InvalidationMessage{Origin: m.instanceID, CacheName: name, Prefixes: []string{prefix}}
+			message.InjectTrace(ctx)
+			if err := bus.Publish(ctx, message); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
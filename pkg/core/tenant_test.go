@@ -0,0 +1,346 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coderiser/go-cache/pkg/backend"
+)
+
+// tenantLoader 记录每个租户回源次数的原始方法
+type tenantLoader struct {
+	calls map[string]int
+}
+
+func (l *tenantLoader) invoke(ctx context.Context) (interface{}, error) {
+	tenant := TenantFromContext(ctx)
+	l.calls[tenant]++
+	return fmt.Sprintf("%s-%d", tenant, l.calls[tenant]), nil
+}
+
+func userMeta() *MethodMeta {
+	return &MethodMeta{CacheType: "cacheable", CacheName: "users", KeyExpr: "'user:' + id", ArgNames: []string{"id"}}
+}
+
+func TestCacheManager_TenantScoping(t *testing.T) {
+	manager := NewCacheManager()
+	defer manager.Close()
+	manager.SetTenantConfig(DefaultTenantConfig())
+
+	acme := WithTenant(context.Background(), "acme")
+	globex := WithTenant(context.Background(), "globex")
+	args := []reflect.Value{reflect.ValueOf("1")}
+	loader := &tenantLoader{calls: make(map[string]int)}
+
+	// 相同的注解 key 按租户隔离
+	for i := 0; i < 2; i++ {
+		if v, _ := manager.Execute(acme, userMeta(), args, loader.invoke); v != "acme-1" {
+			t.Errorf("Expected acme-1, got %v", v)
+		}
+		if v, _ := manager.Execute(globex, userMeta(), args, loader.invoke); v != "globex-1" {
+			t.Errorf("Expected globex-1, got %v", v)
+		}
+	}
+
+	cache, _ := manager.GetCache("users")
+	if v, found, _ := cache.Get(context.Background(), "acme:user:1"); !found || v != "acme-1" {
+		t.Errorf("Expected tenant-prefixed key in backend, got %v (found=%v)", v, found)
+	}
+
+	// 失效只作用于当前租户
+	manager.Invalidate(acme, "users", "user:1")
+	if _, found, _ := cache.Get(context.Background(), "acme:user:1"); found {
+		t.Error("Expected acme entry to be invalidated")
+	}
+	if _, found, _ := cache.Get(context.Background(), "globex:user:1"); !found {
+		t.Error("Expected globex entry to be kept")
+	}
+
+	// 没有租户时不加前缀
+	manager.Execute(context.Background(), userMeta(), args, loader.invoke)
+	if _, found, _ := cache.Get(context.Background(), "user:1"); !found {
+		t.Error("Expected unscoped key without tenant")
+	}
+}
+
+func TestCacheManager_TenantResolver(t *testing.T) {
+	type orgKey struct{}
+	manager := NewCacheManager()
+	defer manager.Close()
+	manager.SetTenantConfig(&TenantConfig{Resolver: func(ctx context.Context) string {
+		org, _ := ctx.Value(orgKey{}).(string)
+		return org
+	}})
+
+	ctx := context.WithValue(context.Background(), orgKey{}, "initech")
+	meta := &MethodMeta{CacheType: "cacheable", CacheName: "users", KeyExpr: "#tenant + '/' + #id", Condition: "#tenant != 'blocked'", ArgNames: []string{"id"}}
+	invoke := func(ctx context.Context) (interface{}, error) { return "v", nil }
+
+	manager.Execute(ctx, meta, []reflect.Value{reflect.ValueOf("7")}, invoke)
+	cache, _ := manager.GetCache("users")
+	if _, found, _ := cache.Get(ctx, "initech:initech/7"); !found {
+		t.Error("Expected resolver tenant to scope key and be available as #tenant")
+	}
+
+	// WithTenant 优先于 Resolver
+	manager.Execute(WithTenant(ctx, "blocked"), meta, []reflect.Value{reflect.ValueOf("7")}, invoke)
+	if _, found, _ := cache.Get(ctx, "blocked:blocked/7"); found {
+		t.Error("Expected condition on #tenant to bypass cache")
+	}
+}
+
+func TestCacheManager_TenantValidation(t *testing.T) {
+	ctx := context.Background()
+	manager := NewCacheManager()
+	defer manager.Close()
+	manager.SetTenantConfig(&TenantConfig{FlushMode: TenantFlushGeneration})
+
+	args := []reflect.Value{reflect.ValueOf("1")}
+	loader := &tenantLoader{calls: make(map[string]int)}
+	manager.Execute(WithTenant(ctx, "acme"), userMeta(), args, loader.invoke)
+	manager.FlushTenant(ctx, "acme")
+
+	// "acme:1" 与代数为 1 的 "acme" 前缀相同，"acme:eu" 会被 "acme" 的前缀删除命中：都不走缓存
+	for _, tenant := range []string{"acme:1", "acme:eu"} {
+		for i := 1; i <= 2; i++ {
+			if v, _ := manager.Execute(WithTenant(ctx, tenant), userMeta(), args, loader.invoke); v != fmt.Sprintf("%s-%d", tenant, i) {
+				t.Errorf("Expected invalid tenant %s to bypass cache, got %v", tenant, v)
+			}
+		}
+		if err := manager.Invalidate(WithTenant(ctx, tenant), "users", "user:1"); !errors.Is(err, ErrInvalidTenant) {
+			t.Errorf("Expected ErrInvalidTenant from Invalidate, got %v", err)
+		}
+		if err := manager.FlushTenant(ctx, tenant); !errors.Is(err, ErrInvalidTenant) {
+			t.Errorf("Expected ErrInvalidTenant from FlushTenant, got %v", err)
+		}
+	}
+	if err := manager.FlushTenant(ctx, ""); !errors.Is(err, ErrInvalidTenant) {
+		t.Errorf("Expected ErrInvalidTenant for empty tenant, got %v", err)
+	}
+
+	// 合法租户不受影响
+	if v, _ := manager.Execute(WithTenant(ctx, "acme"), userMeta(), args, loader.invoke); v != "acme-2" {
+		t.Errorf("Expected acme to reload after flush, got %v", v)
+	}
+	if v, _ := manager.Execute(WithTenant(ctx, "acme"), userMeta(), args, loader.invoke); v != "acme-2" {
+		t.Errorf("Expected acme to stay cached, got %v", v)
+	}
+}
+
+func TestCacheManager_TenantGenerationLocalOnly(t *testing.T) {
+	manager := NewCacheManager()
+	defer manager.Close()
+
+	// 包装后的后端不视为进程内缓存
+	shared := func(config *CacheConfig) (CacheBackend, error) {
+		memory, err := backend.NewMemoryBackend(config)
+		return struct{ CacheBackend }{memory}, err
+	}
+	manager.RegisterBackend("shared", shared)
+	remote, _ := shared(DefaultCacheConfig("remote"))
+	manager.RegisterCache("remote", remote)
+
+	if err := manager.SetTenantConfig(&TenantConfig{FlushMode: TenantFlushGeneration}); !errors.Is(err, ErrSharedCacheGeneration) {
+		t.Errorf("Expected ErrSharedCacheGeneration with shared cache, got %v", err)
+	}
+	if err := manager.SetTenantConfig(DefaultTenantConfig()); err != nil {
+		t.Fatalf("Expected prefix mode to accept shared cache, got %v", err)
+	}
+
+	manager.RemoveCache("remote")
+	if err := manager.SetTenantConfig(&TenantConfig{FlushMode: TenantFlushGeneration}); err != nil {
+		t.Fatalf("Expected generation mode with memory caches, got %v", err)
+	}
+	if err := manager.RegisterCache("remote", remote); !errors.Is(err, ErrSharedCacheGeneration) {
+		t.Errorf("Expected RegisterCache to reject shared cache, got %v", err)
+	}
+	config := DefaultCacheConfig("orders")
+	config.Backend = "shared"
+	manager.RegisterCacheConfig("orders", config)
+	if _, err := manager.GetCache("orders"); !errors.Is(err, ErrSharedCacheGeneration) {
+		t.Errorf("Expected GetCache to reject shared cache, got %v", err)
+	}
+	if _, err := manager.GetCache("users"); err != nil {
+		t.Errorf("Expected memory cache to be created, got %v", err)
+	}
+}
+
+func TestCacheManager_TenantQuota(t *testing.T) {
+	manager := NewCacheManager()
+	defer manager.Close()
+	manager.SetTenantConfig(&TenantConfig{Quota: 2, Quotas: map[string]int{"vip": 3}})
+
+	ctx := context.Background()
+	put := func(tenant, id string) {
+		meta := &MethodMeta{CacheType: "cacheput", CacheName: "users", KeyExpr: "'user:' + id", ArgNames: []string{"id"}}
+		manager.Execute(WithTenant(ctx, tenant), meta, []reflect.Value{reflect.ValueOf(id)}, func(context.Context) (interface{}, error) {
+			return id, nil
+		})
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		put("acme", id)
+		put("vip", id)
+	}
+
+	cache, _ := manager.GetCache("users")
+	// acme 超出配额，最早写入的被淘汰
+	if _, found, _ := cache.Get(ctx, "acme:user:1"); found {
+		t.Error("Expected oldest acme entry to be evicted")
+	}
+	for _, key := range []string{"acme:user:2", "acme:user:3", "vip:user:1", "vip:user:3"} {
+		if _, found, _ := cache.Get(ctx, key); !found {
+			t.Errorf("Expected %s to be kept", key)
+		}
+	}
+
+	impl := manager.(*cacheManagerImpl)
+	if n := impl.tenants.usage("users", "acme"); n != 2 {
+		t.Errorf("Expected acme usage 2, got %d", n)
+	}
+	// 删除后不再计入配额
+	manager.Invalidate(WithTenant(ctx, "acme"), "users", "user:2")
+	if n := impl.tenants.usage("users", "acme"); n != 1 {
+		t.Errorf("Expected acme usage 1 after delete, got %d", n)
+	}
+}
+
+func TestCacheManager_FlushTenant(t *testing.T) {
+	ctx := context.Background()
+	args := []reflect.Value{reflect.ValueOf("1")}
+
+	for _, mode := range []TenantFlushMode{TenantFlushPrefix, TenantFlushGeneration} {
+		t.Run(string(mode), func(t *testing.T) {
+			manager := NewCacheManager()
+			defer manager.Close()
+			manager.SetTenantConfig(&TenantConfig{FlushMode: mode})
+
+			loader := &tenantLoader{calls: make(map[string]int)}
+			acme, globex := WithTenant(ctx, "acme"), WithTenant(ctx, "globex")
+			manager.Execute(acme, userMeta(), args, loader.invoke)
+			manager.Execute(globex, userMeta(), args, loader.invoke)
+
+			if err := manager.FlushTenant(ctx, "acme"); err != nil {
+				t.Fatalf("FlushTenant failed: %v", err)
+			}
+			if v, _ := manager.Execute(acme, userMeta(), args, loader.invoke); v != "acme-2" {
+				t.Errorf("Expected acme to reload after flush, got %v", v)
+			}
+			if v, _ := manager.Execute(globex, userMeta(), args, loader.invoke); v != "globex-1" {
+				t.Errorf("Expected globex to stay cached, got %v", v)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		manager := NewCacheManager()
+		defer manager.Close()
+
+		if err := manager.FlushTenant(ctx, "acme"); !errors.Is(err, ErrTenancyDisabled) {
+			t.Errorf("Expected ErrTenancyDisabled, got %v", err)
+		}
+		if err := manager.SetTenantConfig(&TenantConfig{FlushMode: "bogus"}); err == nil {
+			t.Error("Expected error for unknown flush mode")
+		}
+
+		// 不支持前缀删除的缓存
+		manager.SetTenantConfig(DefaultTenantConfig())
+		memory, _ := backend.NewMemoryBackend(DefaultCacheConfig("plain"))
+		manager.RegisterCache("plain", struct{ CacheBackend }{memory})
+		if err := manager.FlushTenant(ctx, "acme"); err == nil {
+			t.Error("Expected error for cache without prefix deletion")
+		}
+	})
+}
+
+func TestCacheManager_TenantFilter(t *testing.T) {
+	ctx := context.Background()
+
+	for _, mode := range []TenantFlushMode{TenantFlushPrefix, TenantFlushGeneration} {
+		t.Run(string(mode), func(t *testing.T) {
+			manager := NewCacheManager()
+			defer manager.Close()
+			manager.SetTenantConfig(&TenantConfig{FlushMode: mode})
+			// 过滤器按业务 key 灌入，不带租户前缀
+			manager.GetProtection().SetFilter(ctx, "users", backend.NewScalableBloomFilter(nil), func(ctx context.Context, add func(string) error) error {
+				return add("user:1")
+			})
+
+			loader := &tenantLoader{calls: make(map[string]int)}
+			acme := WithTenant(ctx, "acme")
+			if v, _ := manager.Execute(acme, userMeta(), []reflect.Value{reflect.ValueOf("1")}, loader.invoke); v != "acme-1" {
+				t.Errorf("Expected bootstrapped key to load for tenant, got %v", v)
+			}
+			if v, _ := manager.Execute(acme, userMeta(), []reflect.Value{reflect.ValueOf("404")}, loader.invoke); v != nil || loader.calls["acme"] != 1 {
+				t.Errorf("Expected unknown key to be rejected, got %v after %d loads", v, loader.calls["acme"])
+			}
+
+			// @cacheput 按业务 key 加入过滤器，其他租户也能回源
+			put := &MethodMeta{CacheType: "cacheput", CacheName: "users", KeyExpr: "'user:' + id", ArgNames: []string{"id"}}
+			manager.Execute(acme, put, []reflect.Value{reflect.ValueOf("2")}, loader.invoke)
+			if v, _ := manager.Execute(WithTenant(ctx, "globex"), userMeta(), []reflect.Value{reflect.ValueOf("2")}, loader.invoke); v != "globex-1" {
+				t.Errorf("Expected key added by tenant put to load, got %v", v)
+			}
+
+			// 清空租户后仍能通过过滤器重新回源
+			manager.FlushTenant(ctx, "acme")
+			if v, _ := manager.Execute(acme, userMeta(), []reflect.Value{reflect.ValueOf("1")}, loader.invoke); v != "acme-3" {
+				t.Errorf("Expected reload after flush, got %v", v)
+			}
+		})
+	}
+}
+
+func TestCacheManager_TenantBackgroundReload(t *testing.T) {
+	manager := NewCacheManager()
+	defer manager.Close()
+	manager.SetTenantConfig(DefaultTenantConfig())
+	config := DefaultProtectionConfig()
+	config.EnableAvalancheProtection = false
+	manager.SetProtectionConfig(config)
+
+	tenants := make(chan string, 10)
+	invoke := func(ctx context.Context) (interface{}, error) {
+		tenants <- TenantFromContext(ctx)
+		return "v", nil
+	}
+	meta := userMeta()
+	meta.TTLExpr = "50ms"
+	meta.StaleExpr = "1m"
+	args := []reflect.Value{reflect.ValueOf("1")}
+	acme := WithTenant(context.Background(), "acme")
+
+	manager.Execute(acme, meta, args, invoke)
+	<-tenants
+	time.Sleep(80 * time.Millisecond)
+
+	// 软过期后返回旧值并在后台重验证，重验证的 ctx 仍带租户
+	manager.Execute(acme, meta, args, invoke)
+	select {
+	case tenant := <-tenants:
+		if tenant != "acme" {
+			t.Errorf("Expected background reload for acme, got %q", tenant)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected background revalidation")
+	}
+
+	// 写后刷新在新的 ctx 中执行，租户同样被带上
+	refresh := userMeta()
+	refresh.KeyExpr = "'refresh:' + id"
+	refresh.RefreshAfterExpr = "50ms"
+	manager.Execute(acme, refresh, args, invoke)
+	<-tenants
+	manager.Execute(acme, refresh, args, invoke)
+	select {
+	case tenant := <-tenants:
+		if tenant != "acme" {
+			t.Errorf("Expected scheduled refresh for acme, got %q", tenant)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected scheduled refresh")
+	}
+}
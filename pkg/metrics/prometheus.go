@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/coderiser/go-cache/pkg/backend"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -24,6 +25,17 @@ type PrometheusExporter struct {
 	evictions *prometheus.CounterVec
 	sets      *prometheus.CounterVec
 	deletes   *prometheus.CounterVec
+	size      *prometheus.GaugeVec
+	memory    *prometheus.GaugeVec
+
+	writeBehindDepth   *prometheus.GaugeVec
+	writeBehindDropped *prometheus.CounterVec
+
+	circuitState       *prometheus.GaugeVec
+	circuitTransitions *prometheus.CounterVec
+
+	protectionEvents *prometheus.CounterVec
+	loaderQueueWait  *prometheus.HistogramVec
 }
 
 // NewPrometheusExporter 创建 Prometheus 导出器
@@ -83,6 +95,71 @@ func NewPrometheusExporterWithRegistry(reg prometheus.Registerer) *PrometheusExp
 			},
 			[]string{"cache", "backend"},
 		),
+		size: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:      "go_cache_size",
+				Help:      "Current number of entries in the cache namespace",
+				Namespace: "go_cache",
+			},
+			[]string{"cache", "backend"},
+		),
+		memory: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:      "go_cache_memory_bytes",
+				Help:      "Estimated memory used by the cache namespace in bytes",
+				Namespace: "go_cache",
+			},
+			[]string{"cache", "backend"},
+		),
+		writeBehindDepth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:      "go_cache_write_behind_queue_depth",
+				Help:      "Current number of keys waiting in the write-behind queue",
+				Namespace: "go_cache",
+			},
+			[]string{"cache", "backend"},
+		),
+		writeBehindDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:      "go_cache_write_behind_dropped_total",
+				Help:      "Total number of writes dropped because the write-behind queue was full",
+				Namespace: "go_cache",
+			},
+			[]string{"cache", "backend"},
+		),
+		circuitState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name:      "go_cache_circuit_state",
+				Help:      "Circuit breaker state (0=closed, 1=open, 2=half-open)",
+				Namespace: "go_cache",
+			},
+			[]string{"cache", "backend"},
+		),
+		circuitTransitions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:      "go_cache_circuit_transitions_total",
+				Help:      "Total number of circuit breaker state transitions",
+				Namespace: "go_cache",
+			},
+			[]string{"cache", "backend", "state"},
+		),
+		protectionEvents: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:      "go_cache_protection_events_total",
+				Help:      "Total number of cache protection events by event type (see core.ProtectionEvent)",
+				Namespace: "go_cache",
+			},
+			[]string{"cache", "event"},
+		),
+		loaderQueueWait: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:      "go_cache_loader_queue_wait_seconds",
+				Help:      "Time loaders spent waiting for a per-cache bulkhead slot",
+				Namespace: "go_cache",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"cache"},
+		),
 	}
 
 	// 注册所有指标
@@ -92,6 +169,14 @@ func NewPrometheusExporterWithRegistry(reg prometheus.Registerer) *PrometheusExp
 	reg.MustRegister(e.evictions)
 	reg.MustRegister(e.sets)
 	reg.MustRegister(e.deletes)
+	reg.MustRegister(e.size)
+	reg.MustRegister(e.memory)
+	reg.MustRegister(e.writeBehindDepth)
+	reg.MustRegister(e.writeBehindDropped)
+	reg.MustRegister(e.circuitState)
+	reg.MustRegister(e.circuitTransitions)
+	reg.MustRegister(e.protectionEvents)
+	reg.MustRegister(e.loaderQueueWait)
 
 	return e
 }
@@ -138,6 +223,74 @@ func (e *PrometheusExporter) RecordDelete(cacheName, backend string) {
 	e.deletes.WithLabelValues(cacheName, backend).Inc()
 }
 
+// RecordSize 记录缓存条目数
+func (e *PrometheusExporter) RecordSize(cacheName, backend string, size int64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.size.WithLabelValues(cacheName, backend).Set(float64(size))
+}
+
+// RecordMemory 记录缓存内存占用估算（字节）
+func (e *PrometheusExporter) RecordMemory(cacheName, backend string, bytes int64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.memory.WithLabelValues(cacheName, backend).Set(float64(bytes))
+}
+
+// RecordWriteBehindDepth 记录异步写回队列深度
+func (e *PrometheusExporter) RecordWriteBehindDepth(cacheName, backend string, depth int64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.writeBehindDepth.WithLabelValues(cacheName, backend).Set(float64(depth))
+}
+
+// RecordWriteBehindDropped 记录异步写回丢弃的写入数
+func (e *PrometheusExporter) RecordWriteBehindDropped(cacheName, backend string, count int64) {
+	if count <= 0 {
+		return
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.writeBehindDropped.WithLabelValues(cacheName, backend).Add(float64(count))
+}
+
+// RecordCircuitState 记录熔断器状态（0=closed, 1=open, 2=half-open）
+func (e *PrometheusExporter) RecordCircuitState(cacheName, backend string, state int) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.circuitState.WithLabelValues(cacheName, backend).Set(float64(state))
+}
+
+// RecordCircuitTransition 记录熔断器状态变更
+func (e *PrometheusExporter) RecordCircuitTransition(cacheName, backend, state string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.circuitTransitions.WithLabelValues(cacheName, backend, state).Inc()
+}
+
+// ObserveCircuitBreaker 将熔断器状态和状态变更导出为指标
+func (e *PrometheusExporter) ObserveCircuitBreaker(cb *backend.CircuitBreaker, cacheName, backendName string) {
+	e.RecordCircuitState(cacheName, backendName, int(cb.State()))
+	cb.OnStateChange(func(event backend.CircuitStateChange) {
+		e.RecordCircuitState(cacheName, backendName, int(event.To))
+		e.RecordCircuitTransition(cacheName, backendName, event.To.String())
+	})
+}
+
+// RecordProtectionEvent 记录缓存保护事件
+func (e *PrometheusExporter) RecordProtectionEvent(cacheName, event string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.protectionEvents.WithLabelValues(cacheName, event).Inc()
+}
+
+// RecordLoaderQueueWait 记录回源排队时间
+func (e *PrometheusExporter) RecordLoaderQueueWait(cacheName string, wait time.Duration) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.loaderQueueWait.WithLabelValues(cacheName).Observe(wait.Seconds())
+}
+
 // ServeHTTP HTTP 处理函数，暴露 /metrics 端点
 func (e *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	promhttp.Handler().ServeHTTP(w, r)
@@ -163,6 +316,22 @@ func (e *PrometheusExporter) GetCollector(name string) prometheus.Collector {
 		return e.sets
 	case "deletes":
 		return e.deletes
+	case "size":
+		return e.size
+	case "memory":
+		return e.memory
+	case "write_behind_depth":
+		return e.writeBehindDepth
+	case "write_behind_dropped":
+		return e.writeBehindDropped
+	case "circuit_state":
+		return e.circuitState
+	case "circuit_transitions":
+		return e.circuitTransitions
+	case "protection_events":
+		return e.protectionEvents
+	case "loader_queue_wait":
+		return e.loaderQueueWait
 	default:
 		return nil
 	}
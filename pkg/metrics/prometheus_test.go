@@ -2,10 +2,12 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/coderiser/go-cache/pkg/backend"
+	"github.com/coderiser/go-cache/pkg/core"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 )
@@ -120,3 +122,126 @@ func TestMetricsWrapper(t *testing.T) {
 		t.Errorf("Expected 10 hits, got %f", hitsCount)
 	}
 }
+
+func TestMetricsWrapper_SizeGauges(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	exporter := NewPrometheusExporterWithRegistry(reg)
+	memBackend, err := backend.NewMemoryBackend(backend.DefaultCacheConfig("orders"))
+	if err != nil {
+		t.Fatalf("Failed to create memory backend: %v", err)
+	}
+	defer memBackend.Close()
+	wrapped := NewMetricsCacheBackend(memBackend, exporter, "orders", "memory")
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		wrapped.Set(ctx, "key"+string(rune('0'+i)), i, time.Minute)
+	}
+
+	stats := wrapped.Stats()
+	if stats.Size != 3 {
+		t.Fatalf("Expected size 3, got %d", stats.Size)
+	}
+
+	size := testutil.ToFloat64(exporter.size.WithLabelValues("orders", "memory"))
+	if size != 3 {
+		t.Errorf("Expected size gauge 3, got %f", size)
+	}
+	memory := testutil.ToFloat64(exporter.memory.WithLabelValues("orders", "memory"))
+	if memory != 0 {
+		t.Errorf("Expected memory gauge 0 for memory backend, got %f", memory)
+	}
+}
+
+// writeBehindStub 模拟启用异步写回的后端
+type writeBehindStub struct {
+	backend.CacheBackend
+	stats backend.WriteBehindStats
+}
+
+func (s *writeBehindStub) Flush(ctx context.Context) error { return nil }
+
+func (s *writeBehindStub) WriteBehindStats() *backend.WriteBehindStats {
+	stats := s.stats
+	return &stats
+}
+
+func TestMetricsWrapper_WriteBehindMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	exporter := NewPrometheusExporterWithRegistry(reg)
+	memBackend, _ := backend.NewMemoryBackend(backend.DefaultCacheConfig("orders"))
+	defer memBackend.Close()
+
+	stub := &writeBehindStub{CacheBackend: memBackend}
+	wrapped := NewMetricsCacheBackend(stub, exporter, "orders", "redis")
+
+	stub.stats = backend.WriteBehindStats{QueueDepth: 7, Dropped: 2}
+	wrapped.Stats()
+	stub.stats = backend.WriteBehindStats{QueueDepth: 3, Dropped: 5}
+	wrapped.Stats()
+
+	if depth := testutil.ToFloat64(exporter.writeBehindDepth.WithLabelValues("orders", "redis")); depth != 3 {
+		t.Errorf("Expected queue depth 3, got %f", depth)
+	}
+	if dropped := testutil.ToFloat64(exporter.writeBehindDropped.WithLabelValues("orders", "redis")); dropped != 5 {
+		t.Errorf("Expected 5 dropped writes, got %f", dropped)
+	}
+}
+
+func TestObserveCircuitBreaker(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	exporter := NewPrometheusExporterWithRegistry(reg)
+
+	config := backend.DefaultCircuitBreakerConfig("orders")
+	config.MinRequests = 1
+	cb := backend.NewCircuitBreaker(config)
+	exporter.ObserveCircuitBreaker(cb, "orders", "redis")
+
+	cb.Execute(context.Background(), func(ctx context.Context) error { return errors.New("boom") })
+
+	if state := testutil.ToFloat64(exporter.circuitState.WithLabelValues("orders", "redis")); state != 1 {
+		t.Errorf("Expected open state gauge 1, got %f", state)
+	}
+	if n := testutil.ToFloat64(exporter.circuitTransitions.WithLabelValues("orders", "redis", "open")); n != 1 {
+		t.Errorf("Expected 1 transition to open, got %f", n)
+	}
+}
+
+func TestObserveProtection(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	exporter := NewPrometheusExporterWithRegistry(reg)
+
+	protection := core.NewCacheProtection(nil)
+	exporter.ObserveProtection(protection)
+
+	users := protection.ForCache("users")
+	ctx := context.Background()
+	users.ProtectedGet(ctx, "user:1",
+		func() (interface{}, bool, error) { return nil, false, nil },
+		func() (interface{}, error) { return nil, errors.New("db down") },
+		func(interface{}, time.Duration) error { return nil },
+	)
+	users.ApplyPenetrationProtection(backend.NilMarker)
+	protection.ApplyAvalancheProtection(time.Minute)
+
+	if n := testutil.ToFloat64(exporter.protectionEvents.WithLabelValues("users", "loader_error")); n != 1 {
+		t.Errorf("Expected 1 loader error, got %f", n)
+	}
+	if n := testutil.ToFloat64(exporter.protectionEvents.WithLabelValues("users", "nil_marker_hit")); n != 1 {
+		t.Errorf("Expected 1 nil marker hit, got %f", n)
+	}
+	if n := testutil.ToFloat64(exporter.protectionEvents.WithLabelValues("default", "ttl_jittered")); n != 1 {
+		t.Errorf("Expected 1 jittered TTL, got %f", n)
+	}
+
+	// 配置了 bulkhead 的缓存记录排队时间
+	protection.SetBulkhead("orders", &core.BulkheadConfig{MaxConcurrent: 1})
+	protection.ForCache("orders").ProtectedGet(ctx, "order:1",
+		func() (interface{}, bool, error) { return nil, false, nil },
+		func() (interface{}, error) { return "order", nil },
+		func(interface{}, time.Duration) error { return nil },
+	)
+	if n := testutil.CollectAndCount(exporter.loaderQueueWait); n != 1 {
+		t.Errorf("Expected queue wait histogram for orders, got %d series", n)
+	}
+}
@@ -2,9 +2,11 @@ package metrics
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/coderiser/go-cache/pkg/backend"
+	"github.com/coderiser/go-cache/pkg/core"
 )
 
 // MetricsCacheBackend 带指标统计的缓存包装器
@@ -13,6 +15,7 @@ type MetricsCacheBackend struct {
 	exporter    *PrometheusExporter
 	cacheName   string
 	backendName string
+	lastDropped int64 // 上次上报时的异步写回丢弃数，用于计算增量
 }
 
 // NewMetricsCacheBackend 创建带指标的缓存包装器
@@ -70,7 +73,51 @@ func (m *MetricsCacheBackend) Close() error {
 	return m.backend.Close()
 }
 
-// Stats 获取统计信息
+// Stats 获取统计信息并更新容量指标
 func (m *MetricsCacheBackend) Stats() *backend.CacheStats {
-	return m.backend.Stats()
+	stats := m.backend.Stats()
+	m.exporter.RecordSize(m.cacheName, m.backendName, stats.Size)
+	m.exporter.RecordMemory(m.cacheName, m.backendName, stats.MemoryBytes)
+	m.recordWriteBehind()
+	return stats
+}
+
+// recordWriteBehind 后端启用异步写回时上报队列深度和丢弃数
+func (m *MetricsCacheBackend) recordWriteBehind() {
+	wb, ok := m.backend.(backend.WriteBehindBackend)
+	if !ok {
+		return
+	}
+	stats := wb.WriteBehindStats()
+	if stats == nil {
+		return
+	}
+	m.exporter.RecordWriteBehindDepth(m.cacheName, m.backendName, stats.QueueDepth)
+	last := atomic.SwapInt64(&m.lastDropped, stats.Dropped)
+	m.exporter.RecordWriteBehindDropped(m.cacheName, m.backendName, stats.Dropped-last)
+}
+
+// Flush 后端启用异步写回时立即写入待写入的操作
+func (m *MetricsCacheBackend) Flush(ctx context.Context) error {
+	if wb, ok := m.backend.(backend.WriteBehindBackend); ok {
+		return wb.Flush(ctx)
+	}
+	return nil
+}
+
+// ObserveProtection 将缓存保护事件和回源排队时间导出为指标（只统计注册之后的事件）
+// 未绑定缓存的调用使用 "default" 作为 cache 标签。
+func (e *PrometheusExporter) ObserveProtection(p *core.CacheProtection) {
+	p.OnEvent(func(cacheName string, event core.ProtectionEvent) {
+		if cacheName == "" {
+			cacheName = "default"
+		}
+		e.RecordProtectionEvent(cacheName, string(event))
+	})
+	p.OnQueueWait(func(cacheName string, wait time.Duration) {
+		if cacheName == "" {
+			cacheName = "default"
+		}
+		e.RecordLoaderQueueWait(cacheName, wait)
+	})
 }
@@ -17,6 +17,7 @@ type AnnotationMeta struct {
 	Unless     string            // 除非表达式
 	Before     bool              // 是否在方法执行前执行
 	Sync       bool              // 是否同步执行
+	Stale      string            // stale-while-revalidate 窗口
 	Attributes map[string]string // 自定义属性
 }
 
@@ -2,15 +2,12 @@ package proxy
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
 	"reflect"
-	"strings"
 	"sync"
-	"time"
 
 	"github.com/coderiser/go-cache/pkg/core"
-	"github.com/coderiser/go-cache/pkg/spel"
 )
 
 // MethodInterceptor 方法拦截器接口
@@ -28,24 +25,43 @@ type CacheAnnotation struct {
 	Unless    string
 	Before    bool
 	Sync      bool
+
+	Stale        string // stale-while-revalidate 窗口（如 "5m"），需要同时设置 TTL
+	StaleIfError string // 回源失败时继续返回旧值的宽限期
+	RefreshAfter string // 写入后多久在后台刷新（如 "1h"）
+}
+
+// MethodMeta 转换为 CacheManager.Execute 使用的方法元数据
+func (a *CacheAnnotation) MethodMeta() *core.MethodMeta {
+	return &core.MethodMeta{
+		CacheType:        a.Type,
+		CacheName:        a.CacheName,
+		KeyExpr:          a.Key,
+		TTLExpr:          a.TTL,
+		Condition:        a.Condition,
+		Unless:           a.Unless,
+		Sync:             a.Sync,
+		Before:           a.Before,
+		RefreshAfterExpr: a.RefreshAfter,
+		StaleExpr:        a.Stale,
+		StaleIfErrorExpr: a.StaleIfError,
+	}
 }
 
 // methodInterceptor 方法拦截器实现
 type methodInterceptor struct {
 	manager     core.CacheManager
-	evaluator   *spel.SpELEvaluator
 	methodCache sync.Map // map[string]*CacheAnnotation
 }
 
 func newMethodInterceptor(manager core.CacheManager) *methodInterceptor {
 	return &methodInterceptor{
-		manager:   manager,
-		evaluator: spel.NewSpELEvaluator(),
+		manager: manager,
 		// methodCache is a sync.Map, no initialization needed
 	}
 }
 
-// Intercept 拦截方法调用
+// Intercept 拦截方法调用，缓存逻辑委托给 CacheManager.Execute
 func (i *methodInterceptor) Intercept(target interface{}, methodName string, args []reflect.Value) []reflect.Value {
 	log.Printf("[DEBUG] Intercept: method=%s, target=%T", methodName, target)
 	annotation := i.getAnnotation(methodName)
@@ -55,62 +71,68 @@ func (i *methodInterceptor) Intercept(target interface{}, methodName string, arg
 		return i.invokeOriginal(target, methodName, args)
 	}
 
-	callInfo := i.getCallInfo(target, methodName, args)
-
 	switch annotation.Type {
-	case "cacheable":
-		return i.handleCacheable(target, callInfo, annotation, args)
-	case "cacheput":
-		return i.handleCachePut(target, callInfo, annotation, args)
-	case "cacheevict":
-		return i.handleCacheEvict(target, callInfo, annotation, args)
+	case "cacheable", "cacheput", "cacheevict":
 	default:
 		return i.invokeOriginal(target, methodName, args)
 	}
-}
-
-type callInfo struct {
-	methodName string
-	methodType reflect.Type
-	ctx        *spel.EvaluationContext
-}
-
-func (i *methodInterceptor) getCallInfo(target interface{}, methodName string, args []reflect.Value) *callInfo {
-	targetValue := reflect.ValueOf(target)
-	targetType := targetValue.Type()
-
-	ctx := spel.NewEvaluationContext()
-	ctx.Target = target
-	ctx.TargetType = targetType
-	ctx.Method = methodName
 
-	// 设置参数：支持多种 SpEL 语法
-	// #0, #1, ... (索引)
-	// #p0, #p1, ... (带前缀的索引)
-	// p0, p1, ... (不带#的参数)
-	for idx, arg := range args {
-		value := arg.Interface()
-		ctx.SetArgByIndex(idx, value)
-		ctx.SetArg(fmt.Sprintf("p%d", idx), value)
-		// 支持 #0, #1 语法（通过 BuildVariables 自动设置）
+	meta := annotation.MethodMeta()
+	meta.MethodName = methodName
+	meta.Target = target
+
+	// 并发未命中时只有一个协程执行原始方法；stale 重验证时在后台协程执行
+	var (
+		resultsMu sync.Mutex
+		results   []reflect.Value
+	)
+	invoke := func(ctx context.Context) (interface{}, error) {
+		r := i.invokeOriginal(target, methodName, args)
+		resultsMu.Lock()
+		results = r
+		resultsMu.Unlock()
+		if len(r) == 0 {
+			return nil, nil
+		}
+		// 方法最后一个返回值是非 nil 的 error 时作为回源错误（可被错误缓存）
+		if err := resultError(r); err != nil {
+			return nil, err
+		}
+		return r[0].Interface(), nil
 	}
-	
-	// 特殊处理：从注解中提取参数名并映射
-	// 对于常见模式如 #id, #user，尝试智能匹配
-	if len(args) >= 1 {
-		// 第一个参数映射为 #id（如果注解使用 #id）
-		ctx.SetArg("id", args[0].Interface())
+
+	value, err := i.manager.Execute(ContextFromArgs(args), meta, args, invoke)
+	resultsMu.Lock()
+	defer resultsMu.Unlock()
+	if results != nil {
+		// 本协程执行了原始方法，返回完整结果
+		return results
 	}
-	if len(args) >= 2 {
-		// 第二个参数映射为 #user（如果注解使用 #user）
-		ctx.SetArg("user", args[1].Interface())
+	if err != nil {
+		// 没有执行原始方法就失败（命中错误缓存、回源并发已满等）
+		var cachedErr *core.CachedError
+		if !errors.As(err, &cachedErr) {
+			log.Printf("[WARN] Intercept: %s failed without invoking original: %v", methodName, err)
+		}
+		if replayed := i.errorResults(target, methodName, err); replayed != nil {
+			return replayed
+		}
+		return i.invokeOriginal(target, methodName, args)
 	}
+	return i.valueResults(target, methodName, value)
+}
 
-	return &callInfo{
-		methodName: methodName,
-		methodType: targetType,
-		ctx:        ctx,
+// ContextFromArgs 返回参数中的第一个 context.Context，没有时返回 context.Background()
+func ContextFromArgs(args []reflect.Value) context.Context {
+	for _, arg := range args {
+		if !arg.IsValid() || !arg.CanInterface() {
+			continue
+		}
+		if ctx, ok := arg.Interface().(context.Context); ok && ctx != nil {
+			return ctx
+		}
 	}
+	return context.Background()
 }
 
 func (i *methodInterceptor) getAnnotation(methodName string) *CacheAnnotation {
@@ -125,185 +147,71 @@ func (i *methodInterceptor) RegisterAnnotation(methodName string, annotation *Ca
 	i.methodCache.Store(methodName, annotation)
 }
 
-func (i *methodInterceptor) handleCacheable(target interface{}, callInfo *callInfo, annotation *CacheAnnotation, args []reflect.Value) []reflect.Value {
-	log.Printf("[DEBUG] handleCacheable: method=%s, cache=%s, key=%s, args=%v", callInfo.methodName, annotation.CacheName, annotation.Key, args)
-	
-	cache, err := i.manager.GetCache(annotation.CacheName)
-	if err != nil {
-		log.Printf("[DEBUG] handleCacheable: Failed to get cache: %v", err)
-		return i.invokeOriginal(target, callInfo.methodName, args)
-	}
-	log.Printf("[DEBUG] handleCacheable: Got cache backend: %T", cache)
-
-	callInfo.ctx.CacheName = annotation.CacheName
-	cacheKey, err := i.evaluator.EvaluateToString(annotation.Key, callInfo.ctx)
-	log.Printf("[DEBUG] handleCacheable: cacheKey='%v', err=%v, ctx.args=%v", cacheKey, err, callInfo.ctx.Args)
-	if err != nil {
-		log.Printf("[DEBUG] handleCacheable: SpEL evaluation failed: %v", err)
-		return i.invokeOriginal(target, callInfo.methodName, args)
-	}
-
-	if annotation.Condition != "" {
-		conditionResult, err := i.evaluator.Evaluate(annotation.Condition, callInfo.ctx)
-		if err != nil || !i.isTruthy(conditionResult) {
-			return i.invokeOriginal(target, callInfo.methodName, args)
-		}
-	}
-
-	ctx := context.Background()
-	value, found, err := cache.Get(ctx, cacheKey)
-	if err == nil && found {
-		log.Printf("[INFO] Cache HIT: %s:%s", annotation.CacheName, cacheKey)
-		return []reflect.Value{reflect.ValueOf(value)}
-	}
-	log.Printf("[DEBUG] Cache MISS: %s:%s", annotation.CacheName, cacheKey)
-
-	results := i.invokeOriginal(target, callInfo.methodName, args)
-
-	if len(results) > 0 {
-		resultValue := results[0].Interface()
-		callInfo.ctx.SetResult(resultValue)
-
-		if annotation.Unless != "" {
-			unlessResult, err := i.evaluator.Evaluate(annotation.Unless, callInfo.ctx)
-			if err == nil && i.isTruthy(unlessResult) {
-				return results
-			}
-		}
-
-		ttl := i.parseTTL(annotation.TTL, callInfo.ctx)
-		_ = cache.Set(ctx, cacheKey, resultValue, ttl)
-	}
-
-	return results
-}
-
-func (i *methodInterceptor) handleCachePut(target interface{}, callInfo *callInfo, annotation *CacheAnnotation, args []reflect.Value) []reflect.Value {
-	results := i.invokeOriginal(target, callInfo.methodName, args)
-
-	cache, err := i.manager.GetCache(annotation.CacheName)
-	if err != nil {
-		return results
-	}
-
-	callInfo.ctx.CacheName = annotation.CacheName
-	if len(results) > 0 {
-		callInfo.ctx.SetResult(results[0].Interface())
-	}
-
-	cacheKey, err := i.evaluator.EvaluateToString(annotation.Key, callInfo.ctx)
-	if err != nil {
-		return results
-	}
+func (i *methodInterceptor) invokeOriginal(target interface{}, methodName string, args []reflect.Value) []reflect.Value {
+	targetValue := reflect.ValueOf(target)
+	method := targetValue.MethodByName(methodName)
 
-	if len(results) > 0 {
-		ctx := context.Background()
-		ttl := i.parseTTL(annotation.TTL, callInfo.ctx)
-		_ = cache.Set(ctx, cacheKey, results[0].Interface(), ttl)
+	if !method.IsValid() {
+		return []reflect.Value{}
 	}
 
-	return results
+	return method.Call(args)
 }
 
-func (i *methodInterceptor) handleCacheEvict(target interface{}, callInfo *callInfo, annotation *CacheAnnotation, args []reflect.Value) []reflect.Value {
-	cache, err := i.manager.GetCache(annotation.CacheName)
-	if err != nil {
-		return i.invokeOriginal(target, callInfo.methodName, args)
-	}
-
-	if annotation.Before {
-		callInfo.ctx.CacheName = annotation.CacheName
-		cacheKey, err := i.evaluator.EvaluateToString(annotation.Key, callInfo.ctx)
-		if err == nil {
-			ctx := context.Background()
-			_ = cache.Delete(ctx, cacheKey)
+// valueResults 按方法签名包装缓存中的值，其余返回值为零值；值的类型与签名不符时（如 Redis 反序列化的 map）原样返回
+func (i *methodInterceptor) valueResults(target interface{}, methodName string, value interface{}) []reflect.Value {
+	method := reflect.ValueOf(target).MethodByName(methodName)
+	if !method.IsValid() || method.Type().NumOut() == 0 {
+		if value == nil {
+			return []reflect.Value{reflect.ValueOf(&value).Elem()}
 		}
+		return []reflect.Value{reflect.ValueOf(value)}
 	}
-
-	results := i.invokeOriginal(target, callInfo.methodName, args)
-
-	if !annotation.Before {
-		callInfo.ctx.CacheName = annotation.CacheName
-		if len(results) > 0 {
-			callInfo.ctx.SetResult(results[0].Interface())
-		}
-		cacheKey, err := i.evaluator.EvaluateToString(annotation.Key, callInfo.ctx)
-		if err == nil {
-			ctx := context.Background()
-			_ = cache.Delete(ctx, cacheKey)
+	methodType := method.Type()
+	results := make([]reflect.Value, methodType.NumOut())
+	for idx := range results {
+		results[idx] = reflect.Zero(methodType.Out(idx))
+	}
+	if value != nil {
+		v := reflect.ValueOf(value)
+		if v.Type().AssignableTo(methodType.Out(0)) {
+			results[0] = reflect.New(methodType.Out(0)).Elem()
+			results[0].Set(v)
+		} else {
+			results[0] = v
 		}
 	}
-
 	return results
 }
 
-func (i *methodInterceptor) invokeOriginal(target interface{}, methodName string, args []reflect.Value) []reflect.Value {
-	targetValue := reflect.ValueOf(target)
-	method := targetValue.MethodByName(methodName)
-
+// errorResults 按方法签名构造零值返回值，最后一个 error 返回值设为 err；方法不返回 error 时返回 nil
+func (i *methodInterceptor) errorResults(target interface{}, methodName string, err error) []reflect.Value {
+	method := reflect.ValueOf(target).MethodByName(methodName)
 	if !method.IsValid() {
-		return []reflect.Value{}
+		return nil
 	}
-
-	return method.Call(args)
-}
-
-func (i *methodInterceptor) parseTTL(ttlExpr string, ctx *spel.EvaluationContext) time.Duration {
-	if ttlExpr == "" {
-		return 30 * time.Minute
+	methodType := method.Type()
+	numOut := methodType.NumOut()
+	if numOut == 0 || methodType.Out(numOut-1) != errorType {
+		return nil
 	}
-
-	// 尝试作为 SpEL 表达式求值
-	result, err := i.evaluator.EvaluateToInt(ttlExpr, ctx)
-	if err == nil {
-		return time.Duration(result) * time.Second
-	}
-
-	// 作为 duration 字符串解析
-	d, err := time.ParseDuration(ttlExpr)
-	if err == nil {
-		return d
+	results := make([]reflect.Value, numOut)
+	for idx := 0; idx < numOut-1; idx++ {
+		results[idx] = reflect.Zero(methodType.Out(idx))
 	}
-
-	return 30 * time.Minute
+	results[numOut-1] = reflect.ValueOf(&err).Elem()
+	return results
 }
 
-func (i *methodInterceptor) isTruthy(value interface{}) bool {
-	if value == nil {
-		return false
-	}
-	switch v := value.(type) {
-	case bool:
-		return v
-	case int:
-		return v != 0
-	case int8:
-		return v != 0
-	case int16:
-		return v != 0
-	case int32:
-		return v != 0
-	case int64:
-		return v != 0
-	case uint:
-		return v != 0
-	case uint8:
-		return v != 0
-	case uint16:
-		return v != 0
-	case uint32:
-		return v != 0
-	case uint64:
-		return v != 0
-	case float32:
-		return v != 0
-	case float64:
-		return v != 0
-	case string:
-		return v != "" && strings.ToLower(v) != "false"
-	default:
-		return true
+// resultError 返回方法最后一个 error 返回值（nil 表示没有错误）
+func resultError(results []reflect.Value) error {
+	last := results[len(results)-1]
+	if last.Type() != errorType || last.IsNil() {
+		return nil
 	}
+	return last.Interface().(error)
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 var _ MethodInterceptor = (*methodInterceptor)(nil)
@@ -2,21 +2,24 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/coderiser/go-cache/pkg/core"
-	"github.com/coderiser/go-cache/pkg/spel"
 )
 
 // TestService for proxy testing
 type TestService struct {
-	mu   sync.Mutex
-	data map[string]interface{}
+	mu      sync.Mutex
+	data    map[string]interface{}
+	lookups int
 }
 
+var errDataNotFound = errors.New("data not found")
+
 func NewTestService() *TestService {
 	return &TestService{data: make(map[string]interface{})}
 }
@@ -30,6 +33,20 @@ func (s *TestService) GetData(key string) interface{} {
 	return nil
 }
 
+func (s *TestService) FindData(key string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	if val, ok := s.data[key]; ok {
+		return val, nil
+	}
+	return nil, errDataNotFound
+}
+
+func (s *TestService) LoadData(ctx context.Context, key string) (interface{}, error) {
+	return s.FindData(key)
+}
+
 func (s *TestService) SetData(key string, value interface{}) interface{} {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -285,76 +302,6 @@ func TestMethodInterceptor(t *testing.T) {
 		}
 	})
 
-	t.Run("parseTTL", func(t *testing.T) {
-		manager := core.NewCacheManager()
-		defer manager.Close()
-
-		interceptor := newMethodInterceptor(manager)
-
-		// Empty TTL
-		ctx := spel.NewEvaluationContext()
-		ttl := interceptor.parseTTL("", ctx)
-		if ttl != 30*time.Minute {
-			t.Errorf("Expected 30m default TTL, got %v", ttl)
-		}
-
-		// Valid TTL expression
-		ctx.SetArg("ttl", int64(60))
-		ttl = interceptor.parseTTL("ttl", ctx)
-		if ttl != 60*time.Second {
-			t.Errorf("Expected 60s TTL, got %v", ttl)
-		}
-
-		// Invalid TTL expression (should use default)
-		ttl = interceptor.parseTTL("invalid_expr", ctx)
-		if ttl != 30*time.Minute {
-			t.Errorf("Expected 30m default TTL for invalid expr, got %v", ttl)
-		}
-	})
-
-	t.Run("isTruthy", func(t *testing.T) {
-		manager := core.NewCacheManager()
-		defer manager.Close()
-
-		interceptor := newMethodInterceptor(manager)
-
-		tests := []struct {
-			value  interface{}
-			expected bool
-		}{
-			{nil, false},
-			{false, false},
-			{true, true},
-			{int(0), false},
-			{int(1), true},
-			{int(-1), true},
-			{uint8(0), false},
-			{uint8(1), true},
-			{uint16(0), false},
-			{uint16(1), true},
-			{uint32(0), false},
-			{uint32(1), true},
-			{uint64(0), false},
-			{uint64(1), true},
-			{float32(0), false},
-			{float32(1.5), true},
-			{float64(0), false},
-			{float64(1.5), true},
-			{"", false},
-			{"false", false},
-			{"FALSE", false},
-			{"true", true},
-			{"anything", true},
-		}
-
-		for _, test := range tests {
-			result := interceptor.isTruthy(test.value)
-			if result != test.expected {
-				t.Errorf("isTruthy(%v) = %v, expected %v", test.value, result, test.expected)
-			}
-		}
-	})
-
 	t.Run("handleCacheable - cache miss then hit", func(t *testing.T) {
 		manager := core.NewCacheManager()
 		defer manager.Close()
@@ -388,6 +335,47 @@ func TestMethodInterceptor(t *testing.T) {
 		}
 	})
 
+	t.Run("handleCacheable - context from args", func(t *testing.T) {
+		manager := core.NewCacheManager()
+		defer manager.Close()
+		if err := manager.SetTenantConfig(core.DefaultTenantConfig()); err != nil {
+			t.Fatal(err)
+		}
+
+		interceptor := newMethodInterceptor(manager)
+		interceptor.RegisterAnnotation("LoadData", &CacheAnnotation{
+			Type:      "cacheable",
+			CacheName: "test-cache",
+			Key:       "'test-key'",
+		})
+
+		service := NewTestService()
+		service.SetData("test-key", "value")
+
+		// 租户来自方法的 ctx 参数，不同租户的缓存互不可见
+		for _, tenant := range []string{"acme", "acme", "globex"} {
+			ctx := core.WithTenant(context.Background(), tenant)
+			results := interceptor.Intercept(service, "LoadData", []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf("test-key")})
+			if len(results) != 2 || results[0].Interface() != "value" {
+				t.Fatalf("Unexpected results for %s: %v", tenant, results)
+			}
+		}
+		if service.lookups != 2 {
+			t.Errorf("Expected one lookup per tenant, got %d", service.lookups)
+		}
+	})
+
+	t.Run("ContextFromArgs", func(t *testing.T) {
+		ctx := core.WithTenant(context.Background(), "acme")
+		args := []reflect.Value{reflect.ValueOf("key"), reflect.ValueOf(ctx)}
+		if got := ContextFromArgs(args); core.TenantFromContext(got) != "acme" {
+			t.Errorf("Expected ctx from args, got %v", got)
+		}
+		if got := ContextFromArgs([]reflect.Value{reflect.ValueOf("key")}); got != context.Background() {
+			t.Errorf("Expected background ctx, got %v", got)
+		}
+	})
+
 	t.Run("handleCacheable - invalid cache name", func(t *testing.T) {
 		manager := core.NewCacheManager()
 		defer manager.Close()
@@ -494,6 +482,40 @@ func TestMethodInterceptor(t *testing.T) {
 		}
 	})
 
+	t.Run("handleCacheable - negative cache", func(t *testing.T) {
+		manager := core.NewCacheManager()
+		defer manager.Close()
+		manager.GetProtection().SetNegativeCache("test-cache", &core.NegativeCacheConfig{
+			Errors: []error{errDataNotFound},
+			TTL:    time.Minute,
+		})
+
+		interceptor := newMethodInterceptor(manager)
+		interceptor.RegisterAnnotation("FindData", &CacheAnnotation{
+			Type:      "cacheable",
+			CacheName: "test-cache",
+			Key:       "'missing-key'",
+		})
+
+		service := NewTestService()
+		for i := 0; i < 3; i++ {
+			results := interceptor.Intercept(service, "FindData", []reflect.Value{reflect.ValueOf("missing")})
+			if len(results) != 2 {
+				t.Fatalf("Call %d: expected 2 results, got %d", i, len(results))
+			}
+			err, _ := results[1].Interface().(error)
+			if !errors.Is(err, errDataNotFound) {
+				t.Errorf("Call %d: expected errDataNotFound, got %v", i, err)
+			}
+			if !results[0].IsNil() {
+				t.Errorf("Call %d: expected nil value, got %v", i, results[0].Interface())
+			}
+		}
+		if service.lookups != 1 {
+			t.Errorf("Expected original method to be called once, got %d", service.lookups)
+		}
+	})
+
 	t.Run("handleCacheEvict - before", func(t *testing.T) {
 		manager := core.NewCacheManager()
 		defer manager.Close()
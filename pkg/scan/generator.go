@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/coderiser/go-cache/pkg/logger"
 )
@@ -106,7 +105,7 @@ func (g *Generator) collectImports(fileInfo *FileInfo, matches map[string]*Match
 
 	// 基础导入
 	importSet["context"] = true
-	importSet["time"] = true
+	importSet["reflect"] = true
 	importSet["github.com/coderiser/go-cache/pkg/cache"] = true
 	importSet["github.com/coderiser/go-cache/pkg/core"] = true
 	importSet["github.com/coderiser/go-cache/pkg/spel"] = true
@@ -332,12 +331,8 @@ func (g *Generator) generateMethod(buf *bytes.Buffer, iface *InterfaceInfo, svc
 	buf.WriteString(" {\n")
 
 	switch methodInfo.Operation {
-	case "cacheable":
-		g.generateCacheableMethod(buf, methodSpec, methodInfo)
-	case "cacheput":
-		g.generateCachePutMethod(buf, methodSpec, methodInfo)
-	case "cacheevict":
-		g.generateCacheEvictMethod(buf, methodSpec, methodInfo)
+	case "cacheable", "cacheput", "cacheevict":
+		g.generateExecuteMethod(buf, methodSpec, methodInfo)
 	default:
 		buf.WriteString("\t// Unknown operation\n")
 	}
@@ -345,233 +340,95 @@ func (g *Generator) generateMethod(buf *bytes.Buffer, iface *InterfaceInfo, svc
 	buf.WriteString("}\n\n")
 }
 
-// generateCacheableMethod 生成 cacheable 方法
-func (g *Generator) generateCacheableMethod(buf *bytes.Buffer, methodSpec *MethodSpec, methodInfo *MethodInfo) {
-	buf.WriteString("\tif c.manager == nil {\n")
-	buf.WriteString("\t\tpanic(\"go-cache: CacheManager is nil. This should not happen.\")\n")
-	buf.WriteString("\t}\n")
-	buf.WriteString("\tif c.evaluator == nil {\n")
-	buf.WriteString("\t\tpanic(\"go-cache: SpELEvaluator is nil. This should not happen.\")\n")
-	buf.WriteString("\t}\n")
-	buf.WriteString("\n")
-	buf.WriteString("\tctx := context.Background()\n")
-	buf.WriteString(fmt.Sprintf("\tcache, _ := c.manager.GetCache(\"%s\")\n\n", methodInfo.Cache))
-
-	buf.WriteString("\t// 构建缓存 Key\n")
-	buf.WriteString("\tevalCtx := spel.NewEvaluationContext()\n")
-
-	for i, param := range methodSpec.Params {
-		buf.WriteString(fmt.Sprintf("\tevalCtx.SetArg(\"%s\", %s)\n", param.Name, param.Name))
-		buf.WriteString(fmt.Sprintf("\tevalCtx.SetArgByIndex(%d, %s)\n", i, param.Name))
-	}
-
-	buf.WriteString(fmt.Sprintf("\tkey, _ := c.evaluator.EvaluateToString(\"%s\", evalCtx)\n\n", methodInfo.Key))
-
-	buf.WriteString("\t// 查缓存\n")
-	buf.WriteString("\tif val, found, _ := cache.Get(ctx, key); found {\n")
-
-	if len(methodSpec.Returns) > 0 {
-		returnType := methodSpec.Returns[0].Type
-		if strings.HasPrefix(returnType, "*") || strings.HasPrefix(returnType, "[]") || strings.HasPrefix(returnType, "map[") {
-			buf.WriteString(fmt.Sprintf("\t\treturn val.(%s), nil\n", returnType))
-		} else {
-			buf.WriteString(fmt.Sprintf("\t\treturn val.(%s), nil\n", returnType))
-		}
-	} else {
-		buf.WriteString("\t\treturn\n")
-	}
-
-	buf.WriteString("\t}\n\n")
-
-	buf.WriteString("\t// 调用原始方法\n")
-	rawCall := g.generateRawCall(methodSpec)
-	buf.WriteString(fmt.Sprintf("\t%s\n\n", rawCall))
-
-	// 错误处理：根据返回值数量决定返回格式
-	if len(methodSpec.Returns) > 0 && strings.Contains(methodSpec.Returns[len(methodSpec.Returns)-1].Type, "error") {
-		buf.WriteString("\tif err != nil {\n")
-		if len(methodSpec.Returns) > 1 {
-			buf.WriteString("\t\treturn nil, err\n")
-		} else {
-			buf.WriteString("\t\treturn err\n")
-		}
-		buf.WriteString("\t}\n\n")
-	}
-
-	buf.WriteString("\t// 写缓存\n")
-	ttl := g.parseTTL(methodInfo.TTL)
-	buf.WriteString(fmt.Sprintf("\tcache.Set(ctx, key, result, %s)\n\n", ttl))
-
-	// 返回值：根据返回值数量决定返回格式
-	if len(methodSpec.Returns) > 0 {
-		if len(methodSpec.Returns) > 1 {
-			buf.WriteString("\treturn result, nil\n")
-		} else {
-			buf.WriteString("\treturn nil\n")
+// generateExecuteMethod 生成委托给 CacheManager.Execute 的方法体
+// 支持 (T, error)、(T)、(error) 和无返回值的方法，其他签名直接调用原始方法
+func (g *Generator) generateExecuteMethod(buf *bytes.Buffer, methodSpec *MethodSpec, methodInfo *MethodInfo) {
+	var args, invokeArgs, names []string
+	ctxExpr := "context.Background()"
+	invokeCtx := invokeContextName(methodSpec.Params)
+	for _, p := range methodSpec.Params {
+		args = append(args, p.Name)
+		names = append(names, fmt.Sprintf("%q", p.Name))
+		if p.Type == "context.Context" {
+			// 回源时传入 Execute 给闭包的 ctx，而不是调用方的 ctx
+			invokeArgs = append(invokeArgs, invokeCtx)
+			if ctxExpr == "context.Background()" {
+				ctxExpr = p.Name
+			}
+			continue
 		}
-	} else {
-		buf.WriteString("\treturn\n")
+		invokeArgs = append(invokeArgs, p.Name)
+	}
+	call := fmt.Sprintf("c.raw.%s(%s)", methodSpec.Name, strings.Join(args, ", "))
+	invokeCall := fmt.Sprintf("c.raw.%s(%s)", methodSpec.Name, strings.Join(invokeArgs, ", "))
+
+	returns := methodSpec.Returns
+	hasError := len(returns) > 0 && returns[len(returns)-1].Type == "error"
+	var lhs, invokeBody, returnCode string
+	switch {
+	case len(returns) == 2 && hasError:
+		// 后端解码出的值（如 map）不是返回类型时由 ConvertValue 重新解码，失败时返回错误
+		lhs, invokeBody = "result, err := ", "return "+invokeCall
+		returnCode = fmt.Sprintf("\tif err != nil {\n\t\tvar zero %s\n\t\treturn zero, err\n\t}\n\treturn core.ConvertValue[%s](result)\n", returns[0].Type, returns[0].Type)
+	case len(returns) == 1 && hasError:
+		lhs, invokeBody = "_, err := ", "return nil, "+invokeCall
+		returnCode = "\treturn err\n"
+	case len(returns) == 1:
+		// 无法返回错误，转换失败时直接调用原始方法
+		lhs, invokeBody = "result, _ := ", "return "+invokeCall+", nil"
+		returnCode = fmt.Sprintf("\tvalue, err := core.ConvertValue[%s](result)\n\tif err != nil {\n\t\treturn %s\n\t}\n\treturn value\n", returns[0].Type, call)
+	case len(returns) == 0:
+		invokeBody = invokeCall + "\n\t\treturn nil, nil"
+	default:
+		buf.WriteString(fmt.Sprintf("\treturn %s\n", call))
+		return
 	}
-}
 
-// generateCachePutMethod 生成 cacheput 方法
-func (g *Generator) generateCachePutMethod(buf *bytes.Buffer, methodSpec *MethodSpec, methodInfo *MethodInfo) {
 	buf.WriteString("\tif c.manager == nil {\n")
 	buf.WriteString("\t\tpanic(\"go-cache: CacheManager is nil. This should not happen.\")\n")
-	buf.WriteString("\t}\n")
-	buf.WriteString("\tif c.evaluator == nil {\n")
-	buf.WriteString("\t\tpanic(\"go-cache: SpELEvaluator is nil. This should not happen.\")\n")
-	buf.WriteString("\t}\n")
-	buf.WriteString("\n")
-	buf.WriteString("\tctx := context.Background()\n")
-	buf.WriteString(fmt.Sprintf("\tcache, _ := c.manager.GetCache(\"%s\")\n\n", methodInfo.Cache))
-
-	buf.WriteString("\t// 调用原始方法\n")
-	rawCall := g.generateRawCall(methodSpec)
-	buf.WriteString(fmt.Sprintf("\t%s\n\n", rawCall))
-
-	// 错误处理：根据返回值数量决定返回格式
-	if len(methodSpec.Returns) > 0 && strings.Contains(methodSpec.Returns[len(methodSpec.Returns)-1].Type, "error") {
-		buf.WriteString("\tif err != nil {\n")
-		if len(methodSpec.Returns) > 1 {
-			buf.WriteString("\t\treturn nil, err\n")
-		} else {
-			buf.WriteString("\t\treturn err\n")
-		}
-		buf.WriteString("\t}\n\n")
-	}
-
-	buf.WriteString("\t// 构建缓存 Key\n")
-	buf.WriteString("\tevalCtx := spel.NewEvaluationContext()\n")
-
-	if len(methodSpec.Returns) > 0 {
-		buf.WriteString("\tevalCtx.SetResult(result)\n")
-	}
-
-	buf.WriteString(fmt.Sprintf("\tkey, _ := c.evaluator.EvaluateToString(\"%s\", evalCtx)\n\n", methodInfo.Key))
-
-	buf.WriteString("\t// 写缓存\n")
-	ttl := g.parseTTL(methodInfo.TTL)
-	buf.WriteString(fmt.Sprintf("\tcache.Set(ctx, key, result, %s)\n\n", ttl))
+	buf.WriteString("\t}\n\n")
 
-	// 返回值：根据返回值数量决定返回格式
-	if len(methodSpec.Returns) > 0 {
-		if len(methodSpec.Returns) > 1 {
-			buf.WriteString("\treturn result, nil\n")
-		} else {
-			buf.WriteString("\treturn nil\n")
+	buf.WriteString("\tmeta := &core.MethodMeta{\n")
+	field := func(name, value string) {
+		if value != "" {
+			buf.WriteString(fmt.Sprintf("\t\t%s: %q,\n", name, value))
 		}
-	} else {
-		buf.WriteString("\treturn\n")
 	}
-}
-
-// generateCacheEvictMethod 生成 cacheevict 方法
-func (g *Generator) generateCacheEvictMethod(buf *bytes.Buffer, methodSpec *MethodSpec, methodInfo *MethodInfo) {
-	buf.WriteString("\tif c.manager == nil {\n")
-	buf.WriteString("\t\tpanic(\"go-cache: CacheManager is nil. This should not happen.\")\n")
-	buf.WriteString("\t}\n")
-	buf.WriteString("\tif c.evaluator == nil {\n")
-	buf.WriteString("\t\tpanic(\"go-cache: SpELEvaluator is nil. This should not happen.\")\n")
-	buf.WriteString("\t}\n")
-	buf.WriteString("\n")
-	buf.WriteString("\tctx := context.Background()\n")
-	buf.WriteString(fmt.Sprintf("\tcache, _ := c.manager.GetCache(\"%s\")\n\n", methodInfo.Cache))
-
+	field("CacheType", methodInfo.Operation)
+	field("CacheName", methodInfo.Cache)
+	field("KeyExpr", methodInfo.Key)
+	field("TTLExpr", methodInfo.TTL)
+	field("Condition", methodInfo.Condition)
+	field("Unless", methodInfo.Unless)
 	if methodInfo.Before {
-		buf.WriteString("\t// 先删除缓存\n")
-		g.generateEvictKey(buf, methodSpec, methodInfo)
-		buf.WriteString("\tcache.Delete(ctx, key)\n\n")
+		buf.WriteString("\t\tBefore: true,\n")
 	}
-
-	buf.WriteString("\t// 调用原始方法\n")
-	rawCall := g.generateRawCall(methodSpec)
-	buf.WriteString(fmt.Sprintf("\t%s\n\n", rawCall))
-
-	// 错误处理：根据返回值数量决定返回格式
-	if len(methodSpec.Returns) > 0 && strings.Contains(methodSpec.Returns[len(methodSpec.Returns)-1].Type, "error") {
-		buf.WriteString("\tif err != nil {\n")
-		// 如果有多个返回值（如 *T, error），返回 nil, err
-		// 如果只有一个 error 返回值，返回 err
-		if len(methodSpec.Returns) > 1 {
-			buf.WriteString("\t\treturn nil, err\n")
-		} else {
-			buf.WriteString("\t\treturn err\n")
-		}
-		buf.WriteString("\t}\n\n")
-	}
-
-	if !methodInfo.Before {
-		buf.WriteString("\t// 后删除缓存\n")
-		g.generateEvictKey(buf, methodSpec, methodInfo)
-		buf.WriteString("\tcache.Delete(ctx, key)\n\n")
-	}
-
-	// 返回值：根据返回值数量决定返回格式
-	if len(methodSpec.Returns) > 0 {
-		if len(methodSpec.Returns) > 1 {
-			buf.WriteString("\treturn result, nil\n")
-		} else {
-			// 只有 error 返回值的情况已经在上面处理了，这里不需要再返回
-			buf.WriteString("\treturn nil\n")
-		}
-	} else {
-		buf.WriteString("\treturn\n")
+	field("RefreshAfterExpr", methodInfo.RefreshAfter)
+	field("StaleExpr", methodInfo.Stale)
+	field("StaleIfErrorExpr", methodInfo.StaleIfError)
+	field("MethodName", methodSpec.Name)
+	if len(names) > 0 {
+		buf.WriteString(fmt.Sprintf("\t\tArgNames: []string{%s},\n", strings.Join(names, ", ")))
 	}
-}
-
-// generateEvictKey 生成删除缓存的 key 构建代码
-func (g *Generator) generateEvictKey(buf *bytes.Buffer, methodSpec *MethodSpec, methodInfo *MethodInfo) {
-	buf.WriteString("\tevalCtx := spel.NewEvaluationContext()\n")
+	buf.WriteString("\t}\n")
 
-	for i, param := range methodSpec.Params {
-		buf.WriteString(fmt.Sprintf("\tevalCtx.SetArg(\"%s\", %s)\n", param.Name, param.Name))
-		buf.WriteString(fmt.Sprintf("\tevalCtx.SetArgByIndex(%d, %s)\n", i, param.Name))
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprintf("reflect.ValueOf(%s)", arg)
 	}
-
-	buf.WriteString(fmt.Sprintf("\tkey, _ := c.evaluator.EvaluateToString(\"%s\", evalCtx)\n", methodInfo.Key))
+	buf.WriteString(fmt.Sprintf("\targs := []reflect.Value{%s}\n", strings.Join(values, ", ")))
+	buf.WriteString(fmt.Sprintf("\t%sc.manager.Execute(%s, meta, args, func(%s context.Context) (interface{}, error) {\n", lhs, ctxExpr, invokeCtx))
+	buf.WriteString(fmt.Sprintf("\t\t%s\n", invokeBody))
+	buf.WriteString("\t})\n")
+	buf.WriteString(returnCode)
 }
 
-// generateRawCall 生成调用原始方法的代码
-func (g *Generator) generateRawCall(methodSpec *MethodSpec) string {
-	var args []string
-	for _, p := range methodSpec.Params {
-		args = append(args, p.Name)
-	}
-
-	call := fmt.Sprintf("result, err := c.raw.%s(%s)", methodSpec.Name, strings.Join(args, ", "))
-
-	if len(methodSpec.Returns) == 0 {
-		call = fmt.Sprintf("c.raw.%s(%s)", methodSpec.Name, strings.Join(args, ", "))
-	} else if len(methodSpec.Returns) == 1 {
-		if strings.Contains(methodSpec.Returns[0].Type, "error") {
-			call = fmt.Sprintf("err := c.raw.%s(%s)", methodSpec.Name, strings.Join(args, ", "))
+// invokeContextName 回源闭包的 ctx 参数名，避免遮蔽名为 ctx 的非 context 参数
+func invokeContextName(params []*ParamSpec) string {
+	for _, p := range params {
+		if p.Name == "ctx" && p.Type != "context.Context" {
+			return "invokeCtx"
 		}
 	}
-
-	return call
-}
-
-// parseTTL 解析 TTL 字符串，生成可读的 Go 代码
-func (g *Generator) parseTTL(ttl string) string {
-	if ttl == "" {
-		return "30 * time.Minute"
-	}
-
-	d, err := time.ParseDuration(ttl)
-	if err != nil {
-		return "30 * time.Minute"
-	}
-
-	// 优化：使用合适的单位，提高代码可读性
-	if d >= time.Hour && d%time.Hour == 0 {
-		return fmt.Sprintf("%d * time.Hour", d/time.Hour)
-	}
-	if d >= time.Minute && d%time.Minute == 0 {
-		return fmt.Sprintf("%d * time.Minute", d/time.Minute)
-	}
-	if d >= time.Second && d%time.Second == 0 {
-		return fmt.Sprintf("%d * time.Second", d/time.Second)
-	}
-	return fmt.Sprintf("%d * time.Nanosecond", d.Nanoseconds())
+	return "ctx"
 }
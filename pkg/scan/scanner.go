@@ -330,6 +330,10 @@ func (s *Scanner) parseMethod(fn *ast.FuncDecl, fileInfo *FileInfo, commentMap m
 		Condition: annotation.Condition,
 		Unless:    annotation.Unless,
 		Before:    annotation.Before,
+
+		Stale:        annotation.Stale,
+		StaleIfError: annotation.StaleIfError,
+		RefreshAfter: annotation.RefreshAfter,
 	}
 
 	svcInfo.Methods[methodName] = methodInfo
@@ -478,6 +482,12 @@ func (s *Scanner) parseAnnotation(comment string) *AnnotationInfo {
 			annotation.Unless = value
 		case "before":
 			annotation.Before = value == "true"
+		case "stale":
+			annotation.Stale = value
+		case "stale_if_error":
+			annotation.StaleIfError = value
+		case "refresh_after":
+			annotation.RefreshAfter = value
 		}
 	}
 
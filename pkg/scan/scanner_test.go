@@ -1,8 +1,10 @@
 package scan
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -388,6 +390,47 @@ func (s *userService) GetUser(id int64) (*User, error) {
 	t.Logf("Generated file:\n%s", contentStr[:500])
 }
 
+// TestGeneratorExecuteMethod 测试生成的方法把闭包的 ctx 传给原始方法，并转换缓存值类型
+func TestGeneratorExecuteMethod(t *testing.T) {
+	g := NewGenerator(&Config{})
+	method := &MethodInfo{Operation: "cacheable", Cache: "users", Key: "#id"}
+
+	var buf bytes.Buffer
+	g.generateExecuteMethod(&buf, &MethodSpec{
+		Name:    "GetUser",
+		Params:  []*ParamSpec{{Name: "reqCtx", Type: "context.Context"}, {Name: "id", Type: "int64"}},
+		Returns: []*ParamSpec{{Type: "*User"}, {Type: "error"}},
+	}, method)
+	code := buf.String()
+	for _, s := range []string{
+		"c.manager.Execute(reqCtx, meta, args, func(ctx context.Context)",
+		"return c.raw.GetUser(ctx, id)",
+		"return core.ConvertValue[*User](result)",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("Generated method missing %q:\n%s", s, code)
+		}
+	}
+
+	buf.Reset()
+	g.generateExecuteMethod(&buf, &MethodSpec{
+		Name:    "GetName",
+		Params:  []*ParamSpec{{Name: "c2", Type: "context.Context"}, {Name: "ctx", Type: "string"}},
+		Returns: []*ParamSpec{{Type: "string"}},
+	}, method)
+	code = buf.String()
+	for _, s := range []string{
+		"func(invokeCtx context.Context)",
+		"return c.raw.GetName(invokeCtx, ctx), nil",
+		"value, err := core.ConvertValue[string](result)",
+		"return c.raw.GetName(c2, ctx)",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("Generated method missing %q:\n%s", s, code)
+		}
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || (len(s) > 0 && containsHelper(s, substr)))
 }
@@ -86,6 +86,10 @@ type MethodInfo struct {
 	Condition string // 条件表达式
 	Unless    string // unless 表达式
 	Before    bool   // cacheevict 的 before 标志
+
+	Stale        string // stale-while-revalidate 窗口
+	StaleIfError string // 回源失败时继续返回旧值的宽限期
+	RefreshAfter string // 写后刷新间隔
 }
 
 // MatchResult 匹配结果
@@ -115,4 +119,8 @@ type AnnotationInfo struct {
 	Condition string
 	Unless    string
 	Before    bool
+
+	Stale        string
+	StaleIfError string
+	RefreshAfter string
 }
@@ -15,6 +15,7 @@ type EvaluationContext struct {
 	Target interface{}
 	TargetType reflect.Type
 	Method, CacheName, Key string
+	Tenant string // 当前租户（#tenant），未启用多租户时为空
 	TTL time.Duration
 	Extra map[string]interface{}
 	Timestamp time.Time
@@ -90,6 +91,8 @@ func (c *EvaluationContext) BuildVariables() map[string]interface{} {
 	if c.Method != "" { vars["method"] = c.Method }
 	if c.CacheName != "" { vars["cacheName"] = c.CacheName }
 	if c.Key != "" { vars["key"] = c.Key }
+	// 同名参数优先
+	if _, ok := vars["tenant"]; !ok { vars["tenant"] = c.Tenant }
 	vars["timestamp"] = c.Timestamp.Unix()
 	vars["now"] = c.Timestamp
 	return vars
@@ -3,6 +3,7 @@ package spel
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/antonmedv/expr"
@@ -81,7 +82,7 @@ func (e *SpELEvaluator) compile(exprStr string) (*vm.Program, error) {
 	}
 	e.mu.RUnlock()
 
-	program, err := expr.Compile(exprStr, expr.AllowUndefinedVariables())
+	program, err := expr.Compile(translateVariables(exprStr), expr.AllowUndefinedVariables())
 	if err != nil {
 		return nil, &CompilationError{Expression: exprStr, Err: err}
 	}
@@ -93,6 +94,57 @@ func (e *SpELEvaluator) compile(exprStr string) (*vm.Program, error) {
 	return program, nil
 }
 
+// translateVariables 把 SpEL 风格的变量引用转换为 expr 语法：#id → id，#0 → p0
+// 引号内的内容、闭包中的 #（如 #.Name、#index）保持不变
+func translateVariables(exprStr string) string {
+	if !strings.Contains(exprStr, "#") {
+		return exprStr
+	}
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(exprStr); i++ {
+		c := exprStr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(exprStr) {
+				b.WriteByte(c)
+				i++
+				c = exprStr[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '#' && i+1 < len(exprStr):
+			name := leadingIdentifier(exprStr[i+1:])
+			switch {
+			case name == "" || name == "index" || name == "acc":
+			case name[0] >= '0' && name[0] <= '9':
+				b.WriteByte('p')
+				continue
+			default:
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// leadingIdentifier 返回字符串开头的标识符（字母、数字、下划线）
+func leadingIdentifier(s string) string {
+	end := 0
+	for end < len(s) {
+		c := s[end]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			end++
+			continue
+		}
+		break
+	}
+	return s[:end]
+}
+
 // ClearCache 清除缓存
 func (e *SpELEvaluator) ClearCache() {
 	e.mu.Lock()
@@ -49,6 +49,30 @@ func TestSpELEvaluator(t *testing.T) {
 		}
 	})
 
+	t.Run("SpEL style variable references", func(t *testing.T) {
+		ctx := NewEvaluationContext()
+		ctx.SetArg("prefix", "user")
+		ctx.SetArgByIndex(0, "42")
+		ctx.SetResult(map[string]interface{}{"ID": 7})
+
+		tests := map[string]string{
+			"#prefix + ':' + #p0":   "user:42",
+			"#prefix + ':' + #0":    "user:42",
+			"#result.ID":            "7",
+			"'#literal:' + #prefix": "#literal:user",
+		}
+		for exprStr, expected := range tests {
+			result, err := evaluator.EvaluateToString(exprStr, ctx)
+			if err != nil {
+				t.Errorf("EvaluateToString(%q) failed: %v", exprStr, err)
+				continue
+			}
+			if result != expected {
+				t.Errorf("EvaluateToString(%q) = %q, expected %q", exprStr, result, expected)
+			}
+		}
+	})
+
 	t.Run("EvaluateToInt", func(t *testing.T) {
 		ctx := NewEvaluationContext()
 		ctx.SetArg("count", 42)
@@ -188,6 +212,23 @@ func TestEvaluationContext(t *testing.T) {
 		}
 	})
 
+	t.Run("Tenant variable", func(t *testing.T) {
+		ctx := NewEvaluationContext()
+		ctx.Tenant = "acme"
+		ctx.SetArg("id", 7)
+
+		result, err := NewSpELEvaluator().EvaluateToString("#tenant + ':' + string(#id)", ctx)
+		if err != nil || result != "acme:7" {
+			t.Errorf("Expected 'acme:7', got %v (%v)", result, err)
+		}
+
+		// 同名参数优先
+		ctx.SetArg("tenant", "explicit")
+		if vars := ctx.BuildVariables(); vars["tenant"] != "explicit" {
+			t.Errorf("Expected tenant arg to win, got %v", vars["tenant"])
+		}
+	})
+
 	t.Run("SetExtra and GetExtra", func(t *testing.T) {
 		ctx := NewEvaluationContext()
 		ctx.SetExtra("custom", "data")